@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,26 +11,65 @@ import (
 
 	"github.com/dmitry/taskmanager/internal/config"
 	"github.com/dmitry/taskmanager/internal/database"
+	"github.com/dmitry/taskmanager/internal/domain"
 	"github.com/dmitry/taskmanager/internal/handler"
+	"github.com/dmitry/taskmanager/internal/metrics"
+	"github.com/dmitry/taskmanager/internal/middleware"
 	"github.com/dmitry/taskmanager/internal/repository"
 	"github.com/dmitry/taskmanager/internal/router"
 	"github.com/dmitry/taskmanager/internal/service"
+	"github.com/dmitry/taskmanager/internal/storage"
+	"github.com/dmitry/taskmanager/internal/tracing"
 	"github.com/dmitry/taskmanager/pkg/logger"
+	"github.com/dmitry/taskmanager/pkg/markdown"
 	"github.com/dmitry/taskmanager/pkg/validator"
 )
 
 func main() {
-	cfg := config.Load()
+	// Подкоманда migrate управляет схемой базы данных и не запускает сервер;
+	// флаги конфигурации принимает только сервер
+	args := os.Args[1:]
+	migrate := len(args) > 0 && args[0] == "migrate"
+	if migrate {
+		args = nil
+	}
+
+	cfg, err := config.Load(args)
+	if err != nil {
+		if config.IsHelp(err) {
+			os.Exit(0)
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if cfg.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	log := logger.New(cfg.LogLevel)
+	log := logger.NewWithOptions(logger.Options{
+		Level:        cfg.LogLevel,
+		Writer:       os.Stdout,
+		RedactFields: cfg.LogRedactFields,
+	})
+	logger.SetDefault(log)
 
-	// Проверка JWT secret
-	if cfg.JWTSecret == "" {
-		log.Fatal("Переменная окружения JWT_SECRET обязательна")
+	if migrate {
+		os.Exit(runMigrate(cfg, log, os.Args[2:]))
 	}
 
 	log.Info("Запуск Task Manager API", "environment", cfg.Environment)
 
+	// Трассировка настраивается до подключений, чтобы запросы к базе данных и Redis попадали в спаны
+	shutdownTracing, err := tracing.New(cfg, log)
+	if err != nil {
+		log.Fatal("Не удалось настроить трассировку", "error", err)
+	}
+
 	// Подключение к базе данных
 	db, err := database.NewPostgres(cfg, log)
 	if err != nil {
@@ -37,6 +77,18 @@ func main() {
 	}
 	defer db.Close()
 
+	if cfg.DBAutoMigrate {
+		migrator, err := database.NewMigrator(db.DB, log)
+		if err != nil {
+			log.Fatal("Не удалось загрузить миграции", "error", err)
+		}
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			log.Fatal("Не удалось применить миграции", "error", err)
+		}
+		log.Info("Схема базы данных актуальна", "version", migrator.Latest(), "applied", applied)
+	}
+
 	// Подключение к Redis
 	redis, err := database.NewRedis(cfg, log)
 	if err != nil {
@@ -44,6 +96,15 @@ func main() {
 	}
 	defer redis.Close()
 
+	metrics.RegisterDB(db.DB, "postgres")
+	metrics.RegisterRedis(redis.Client)
+
+	// Хранилище вложений
+	blobStorage, err := storage.New(cfg, log)
+	if err != nil {
+		log.Fatal("Не удалось инициализировать хранилище вложений", "error", err)
+	}
+
 	// Инициализация репозиториев
 	employeeRepo := repository.NewEmployeeRepository(db.DB)
 	taskRepo := repository.NewTaskRepository(db.DB)
@@ -51,6 +112,17 @@ func main() {
 	messageRepo := repository.NewMessageRepository(db.DB)
 	timeEntryRepo := repository.NewTimeEntryRepository(db.DB)
 	refreshTokenRepo := repository.NewRefreshTokenRepository(db.DB)
+	attachmentRepo := repository.NewAttachmentRepository(db.DB)
+	searchRepo := repository.NewSearchRepository(db.DB)
+	taskViewRepo := repository.NewTaskViewRepository(db.DB)
+	projectRepo := repository.NewProjectRepository(db.DB)
+	taskLinkRepo := repository.NewTaskLinkRepository(db.DB)
+	labelRepo := repository.NewLabelRepository(db.DB)
+	customFieldRepo := repository.NewCustomFieldRepository(db.DB)
+	sprintRepo := repository.NewSprintRepository(db.DB)
+	transitionRepo := repository.NewStatusTransitionRepository(db.DB)
+	analyticsRepo := repository.NewAnalyticsRepository(db.DB)
+	recurrenceRepo := repository.NewRecurrenceRepository(db.DB)
 
 	// JWT сервис
 	jwtService := service.NewJWTService(
@@ -60,26 +132,67 @@ func main() {
 	)
 
 	// Инициализация сервисов
-	employeeService := service.NewEmployeeService(employeeRepo, log)
-	authService := service.NewAuthService(employeeRepo, refreshTokenRepo, jwtService, log)
-	taskService := service.NewTaskService(taskRepo, participantRepo, messageRepo, employeeRepo, db.DB, log)
-	messageService := service.NewMessageService(messageRepo, log)
-	timeEntryService := service.NewTimeEntryService(timeEntryRepo, taskRepo, log)
+	employeeService := service.NewEmployeeService(employeeRepo)
+	authService := service.NewAuthService(employeeRepo, refreshTokenRepo, jwtService)
+	taskAccess := service.NewTaskAccess(taskRepo, projectRepo)
+	// Статусы и лимиты уже проверены при загрузке конфигурации
+	wipLimits := map[domain.TaskStatus]int{}
+	for status, limit := range cfg.BoardWIPLimits {
+		wipLimits[domain.TaskStatus(status)] = limit
+	}
 
-	_ = messageService
-	_ = timeEntryService
+	taskService := service.NewTaskService(taskRepo, participantRepo, messageRepo, employeeRepo, projectRepo, customFieldRepo, transitionRepo, taskAccess, service.TaskOptions{
+		MaxDepth:                    cfg.TaskMaxDepth,
+		CloseRequiresClosedSubtasks: cfg.TaskCloseRequiresClosedSubtasks,
+		BlockedTransition:           cfg.TaskBlockedTransition,
+		WIPLimits:                   wipLimits,
+	}, db.DB)
+	messageService := service.NewMessageService(messageRepo, taskAccess)
+	timeEntryService := service.NewTimeEntryService(timeEntryRepo, taskRepo, employeeRepo, cfg.ReportDepartments)
+	attachmentService := service.NewAttachmentService(attachmentRepo, taskAccess, messageRepo, blobStorage, service.AttachmentOptions{
+		MaxSizeBytes: int64(cfg.AttachmentMaxSizeMB) << 20,
+		AllowedTypes: cfg.AttachmentAllowedTypes,
+		LinkSecret:   cfg.JWTSecret,
+		LinkTTL:      time.Duration(cfg.AttachmentLinkTTLMin) * time.Minute,
+	}, db.DB)
+	searchService := service.NewSearchService(searchRepo, customFieldRepo)
+	taskViewService := service.NewTaskViewService(taskViewRepo, employeeRepo)
+	projectService := service.NewProjectService(projectRepo, taskRepo, employeeRepo)
+	taskLinkService := service.NewTaskLinkService(taskLinkRepo, taskAccess)
+	labelService := service.NewLabelService(labelRepo, taskAccess, messageRepo, db.DB)
+	customFieldService := service.NewCustomFieldService(customFieldRepo, projectRepo, employeeRepo, taskAccess, messageRepo, db.DB)
+	sprintService := service.NewSprintService(sprintRepo, projectRepo, taskAccess, messageRepo, db.DB)
+	recurrenceService := service.NewRecurrenceService(recurrenceRepo, taskRepo, participantRepo, projectRepo, transitionRepo, messageRepo, taskAccess, db.DB)
+	analyticsService := service.NewAnalyticsService(analyticsRepo, sprintRepo, projectRepo, customFieldRepo)
 
 	// Инициализация handlers
 	v := validator.New()
+	md := markdown.New()
 	isProduction := cfg.Environment == "production"
 	authHandler := handler.NewAuthHandler(authService, v, isProduction)
 	employeeHandler := handler.NewEmployeeHandler(employeeService, v)
-	taskHandler := handler.NewTaskHandler(taskService, v)
+	taskHandler := handler.NewTaskHandler(taskService, md, v)
+	messageHandler := handler.NewMessageHandler(messageService, md, v)
+	attachmentHandler := handler.NewAttachmentHandler(attachmentService)
+	searchHandler := handler.NewSearchHandler(searchService)
+	taskViewHandler := handler.NewTaskViewHandler(taskViewService, taskHandler, v)
+	projectHandler := handler.NewProjectHandler(projectService, taskHandler, v)
+	taskLinkHandler := handler.NewTaskLinkHandler(taskLinkService, v)
+	labelHandler := handler.NewLabelHandler(labelService, v)
+	customFieldHandler := handler.NewCustomFieldHandler(customFieldService, taskHandler, v)
+	sprintHandler := handler.NewSprintHandler(sprintService, taskHandler, v)
+	recurrenceHandler := handler.NewRecurrenceHandler(recurrenceService, v)
+	analyticsHandler := handler.NewAnalyticsHandler(analyticsService)
+	reportHandler := handler.NewReportHandler(timeEntryService)
+	healthHandler := handler.NewHealthHandler(map[string]handler.Pinger{
+		"postgres": db,
+		"redis":    redis,
+	}, cfg.HealthCheckTimeout)
 
-	// Настройка роутинга
-	r := router.NewRouter(authHandler, employeeHandler, taskHandler, jwtService, cfg.FrontendURL, log)
+	logSampling := middleware.NewLogSampling(cfg.LogSampleRate)
 
-	_ = redis // Redis будет использоваться для rate limiting позже
+	// Настройка роутинга
+	r := router.NewRouter(authHandler, employeeHandler, taskHandler, messageHandler, attachmentHandler, searchHandler, taskViewHandler, projectHandler, taskLinkHandler, labelHandler, customFieldHandler, sprintHandler, recurrenceHandler, analyticsHandler, reportHandler, healthHandler, jwtService, cfg.FrontendURL, logSampling, log)
 
 	server := &http.Server{
 		Addr:         cfg.ServerAddress,
@@ -92,6 +205,26 @@ func main() {
 	// Запуск горутины для очистки просроченных токенов
 	go cleanupExpiredTokens(refreshTokenRepo, log)
 
+	// Запуск горутины для создания повторяющихся задач
+	go runRecurrences(recurrenceService, cfg.RecurrenceCheckInterval, log)
+
+	// Метрики отдаются на отдельном адресе, чтобы не открывать их вместе с API
+	var metricsServer *http.Server
+	if cfg.MetricsAddress != "off" {
+		metricsServer = &http.Server{
+			Addr:         cfg.MetricsAddress,
+			Handler:      router.NewMetricsRouter(),
+			ReadTimeout:  15 * time.Second,
+			WriteTimeout: 15 * time.Second,
+		}
+		go func() {
+			log.Info("Сервер метрик запускается", "address", cfg.MetricsAddress)
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatal("Не удалось запустить сервер метрик", "error", err)
+			}
+		}()
+	}
+
 	go func() {
 		log.Info("Сервер запускается", "address", cfg.ServerAddress)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -99,22 +232,65 @@ func main() {
 		}
 	}()
 
+	// SIGHUP перечитывает конфигурацию и применяет параметры, не требующие перезапуска
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go reloadConfig(reload, cfg, args, log, logSampling)
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Info("Сервер завершает работу...")
 
+	// Сначала /readyz начинает отвечать 503, и только после паузы сервер перестаёт принимать запросы
+	healthHandler.SetShuttingDown()
+	time.Sleep(cfg.ShutdownDelay)
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatal("Сервер принудительно остановлен", "error", err)
 	}
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(ctx); err != nil {
+			log.Error("Сервер метрик принудительно остановлен", "error", err)
+		}
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error("Не удалось отправить оставшиеся спаны", "error", err)
+	}
 
 	log.Info("Сервер остановлен")
 }
 
+// reloadConfig загружает конфигурацию заново по каждому сигналу. Применяются уровень журнала
+// и доля журналируемых запросов; об остальных изменениях пишется предупреждение.
+// Конфигурация с ошибками не применяется целиком.
+func reloadConfig(signals <-chan os.Signal, cfg *config.Config, args []string, log *logger.Logger, logSampling *middleware.LogSampling) {
+	current := *cfg
+	for range signals {
+		next, err := config.Load(args)
+		if err != nil {
+			log.Error("Конфигурация не перезагружена", "error", err)
+			continue
+		}
+
+		applied, restart := current.Changes(next)
+		log.SetLevel(next.LogLevel)
+		logSampling.Set(next.LogSampleRate)
+		current.LogLevel = next.LogLevel
+		current.LogSampleRate = next.LogSampleRate
+
+		if len(restart) > 0 {
+			log.Warn("Параметры изменятся только после перезапуска", "settings", restart)
+		}
+		log.Info("Конфигурация перезагружена", "applied", applied)
+	}
+}
+
 // cleanupExpiredTokens выполняется ежедневно для удаления просроченных refresh-токенов
 func cleanupExpiredTokens(repo repository.RefreshTokenRepository, log *logger.Logger) {
 	ticker := time.NewTicker(24 * time.Hour)
@@ -130,3 +306,22 @@ func cleanupExpiredTokens(repo repository.RefreshTokenRepository, log *logger.Lo
 		cancel()
 	}
 }
+
+// runRecurrences периодически создаёт задачи по наступившим расписаниям повторения.
+// Горутина работает в каждой реплике API: повторение создаётся только одной из них.
+func runRecurrences(recurrenceService *service.RecurrenceService, interval time.Duration, log *logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log = log.With("job", "recurrences")
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(logger.NewContext(context.Background(), log), time.Minute)
+		created, err := recurrenceService.RunDue(ctx, time.Now())
+		if err != nil {
+			log.Error("Не удалось создать повторяющиеся задачи", "error", err)
+		} else if created > 0 {
+			log.Info("Созданы повторяющиеся задачи", "count", created)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/dmitry/taskmanager/internal/config"
+	"github.com/dmitry/taskmanager/internal/database"
+	"github.com/dmitry/taskmanager/pkg/logger"
+)
+
+const migrateUsage = `Использование: main migrate <команда>
+
+Команды:
+  up          применить все неприменённые миграции
+  down [N]    откатить N последних миграций (по умолчанию 1)
+  status      показать состояние миграций
+  to N        привести схему к версии N (0 - откатить все)
+  force N     отметить версию N текущей, не выполняя SQL (для базы, созданной вручную)`
+
+// runMigrate выполняет подкоманду migrate и возвращает код завершения процесса
+func runMigrate(cfg *config.Config, log *logger.Logger, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	command, number, err := parseMigrateArgs(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	db, err := database.NewPostgres(cfg, log)
+	if err != nil {
+		log.Error("Не удалось подключиться к базе данных", "error", err)
+		return 1
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db.DB, log)
+	if err != nil {
+		log.Error("Не удалось загрузить миграции", "error", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		var count int
+		if count, err = migrator.Up(ctx); err == nil {
+			fmt.Printf("Применено миграций: %d\n", count)
+		}
+	case "down":
+		var count int
+		if count, err = migrator.Down(ctx, number); err == nil {
+			fmt.Printf("Откачено миграций: %d\n", count)
+		}
+	case "to":
+		var count int
+		if count, err = migrator.To(ctx, number); err == nil {
+			fmt.Printf("Схема приведена к версии %d, выполнено миграций: %d\n", number, count)
+		}
+	case "force":
+		if err = migrator.Force(ctx, number); err == nil {
+			fmt.Printf("Версия схемы установлена: %d\n", number)
+		}
+	case "status":
+		err = printMigrationStatus(ctx, migrator)
+	}
+
+	if err != nil {
+		log.Error("Миграция не выполнена", "command", command, "error", err)
+		return 1
+	}
+	return 0
+}
+
+// parseMigrateArgs проверяет команду и её числовой аргумент
+func parseMigrateArgs(args []string) (string, int, error) {
+	command := args[0]
+	switch command {
+	case "up", "status":
+		if len(args) != 1 {
+			return "", 0, fmt.Errorf("команда %s не принимает аргументов", command)
+		}
+		return command, 0, nil
+	case "down":
+		if len(args) == 1 {
+			return command, 1, nil
+		}
+	case "to", "force":
+		if len(args) == 1 {
+			return "", 0, fmt.Errorf("команде %s нужен номер версии", command)
+		}
+	default:
+		return "", 0, fmt.Errorf("неизвестная команда %s", command)
+	}
+
+	if len(args) != 2 {
+		return "", 0, fmt.Errorf("команда %s принимает один аргумент", command)
+	}
+	number, err := strconv.Atoi(args[1])
+	if err != nil || number < 0 {
+		return "", 0, fmt.Errorf("неверное число: %s", args[1])
+	}
+	return command, number, nil
+}
+
+func printMigrationStatus(ctx context.Context, migrator *database.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ВЕРСИЯ\tМИГРАЦИЯ\tПРИМЕНЕНА")
+	for _, s := range statuses {
+		applied := "нет"
+		if s.AppliedAt != nil {
+			applied = s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\n", s.Version, s.Name, applied)
+	}
+	return w.Flush()
+}
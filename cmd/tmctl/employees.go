@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/dto"
+	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/google/uuid"
+)
+
+// listPageSize - размер страницы при выборке всех сотрудников
+const listPageSize = 100
+
+var employeeHeader = []string{"ID", "ИМЯ", "EMAIL", "ОТДЕЛ", "ДОЛЖНОСТЬ"}
+
+func employeeRow(e *domain.Employee) []string {
+	return []string{e.ID.String(), e.Name, e.Email, e.Department, e.Position}
+}
+
+func (a *app) listEmployees(ctx context.Context, args []string) error {
+	flags := newFlagSet("employee list")
+	department := flags.String("department", "", "только сотрудники отдела")
+	if _, err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	employees := []*domain.Employee{}
+	for page := 1; ; page++ {
+		batch, total, err := a.employees.GetAllEmployees(ctx, repository.EmployeeFilter{
+			Department: *department,
+			Page:       page,
+			PageSize:   listPageSize,
+		})
+		if err != nil {
+			return err
+		}
+		employees = append(employees, batch...)
+		if len(batch) < listPageSize || len(employees) >= total {
+			break
+		}
+	}
+
+	rows := make([][]string, len(employees))
+	responses := make([]dto.EmployeeResponse, len(employees))
+	for i, e := range employees {
+		rows[i] = employeeRow(e)
+		responses[i] = dto.ToEmployeeResponse(e)
+	}
+
+	return a.out.table(responses, employeeHeader, rows)
+}
+
+func (a *app) createEmployee(ctx context.Context, args []string) error {
+	flags := newFlagSet("employee create")
+	name := flags.String("name", "", "имя сотрудника")
+	email := flags.String("email", "", "email для входа")
+	department := flags.String("department", "", "отдел")
+	position := flags.String("position", "", "должность")
+	password := flags.String("password", "", "пароль; по умолчанию генерируется и выводится один раз")
+	if _, err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	generated := *password == ""
+	if generated {
+		var err error
+		if *password, err = generatePassword(); err != nil {
+			return err
+		}
+	}
+
+	req := dto.RegisterRequest{
+		Name:       *name,
+		Department: *department,
+		Position:   *position,
+		Email:      *email,
+		Password:   *password,
+	}
+	if err := a.validator.Validate(req); err != nil {
+		return err
+	}
+
+	employee, err := a.auth.Register(ctx, req.Name, req.Department, req.Position, req.Email, req.Password)
+	if err != nil {
+		return err
+	}
+
+	result := struct {
+		Employee dto.EmployeeResponse `json:"employee"`
+		Password string               `json:"password,omitempty"`
+	}{Employee: dto.ToEmployeeResponse(employee)}
+	text := fmt.Sprintf("Сотрудник создан: %s (%s)", employee.ID, employee.Email)
+	if generated {
+		result.Password = *password
+		text += "\nПароль: " + *password
+	}
+
+	return a.out.message(result, text)
+}
+
+// promoteEmployee меняет должность и, при необходимости, отдел сотрудника. Ролей доступа
+// в системе нет, поэтому повышение - это кадровое изменение должности.
+func (a *app) promoteEmployee(ctx context.Context, args []string) error {
+	flags := newFlagSet("employee promote <id|email>")
+	position := flags.String("position", "", "новая должность")
+	department := flags.String("department", "", "новый отдел; по умолчанию не меняется")
+	values, err := parseFlags(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	if *position == "" {
+		fmt.Fprintln(os.Stderr, "Параметр -position обязателен")
+		return errUsage
+	}
+
+	employee, err := a.findEmployee(ctx, values[0])
+	if err != nil {
+		return err
+	}
+
+	employee.Position = *position
+	if *department != "" {
+		employee.Department = *department
+	}
+	if err := a.employees.UpdateEmployee(ctx, employee); err != nil {
+		return err
+	}
+
+	return a.out.message(dto.ToEmployeeResponse(employee),
+		fmt.Sprintf("Сотрудник %s: должность %q, отдел %q", employee.Email, employee.Position, employee.Department))
+}
+
+// deactivateEmployee мягко удаляет сотрудника и отзывает его refresh-токены
+func (a *app) deactivateEmployee(ctx context.Context, args []string) error {
+	flags := newFlagSet("employee deactivate <id|email>")
+	values, err := parseFlags(flags, args, 1)
+	if err != nil {
+		return err
+	}
+
+	employee, err := a.findEmployee(ctx, values[0])
+	if err != nil {
+		return err
+	}
+
+	if err := a.auth.LogoutAll(ctx, employee.ID); err != nil {
+		return err
+	}
+	if err := a.employees.DeleteEmployee(ctx, employee.ID); err != nil {
+		return err
+	}
+
+	return a.out.message(map[string]string{"id": employee.ID.String(), "status": "deactivated"},
+		fmt.Sprintf("Сотрудник %s деактивирован, сессии отозваны", employee.Email))
+}
+
+func (a *app) resetPassword(ctx context.Context, args []string) error {
+	flags := newFlagSet("password reset <id|email>")
+	password := flags.String("password", "", "новый пароль; по умолчанию генерируется и выводится один раз")
+	values, err := parseFlags(flags, args, 1)
+	if err != nil {
+		return err
+	}
+
+	employee, err := a.findEmployee(ctx, values[0])
+	if err != nil {
+		return err
+	}
+
+	generated := *password == ""
+	if generated {
+		if *password, err = generatePassword(); err != nil {
+			return err
+		}
+	}
+
+	if err := a.auth.ResetPassword(ctx, employee.ID, *password); err != nil {
+		return err
+	}
+
+	result := map[string]string{"id": employee.ID.String()}
+	text := fmt.Sprintf("Пароль сотрудника %s сброшен, сессии отозваны", employee.Email)
+	if generated {
+		result["password"] = *password
+		text += "\nПароль: " + *password
+	}
+
+	return a.out.message(result, text)
+}
+
+func (a *app) revokeSessions(ctx context.Context, args []string) error {
+	flags := newFlagSet("sessions revoke <id|email>")
+	values, err := parseFlags(flags, args, 1)
+	if err != nil {
+		return err
+	}
+
+	employee, err := a.findEmployee(ctx, values[0])
+	if err != nil {
+		return err
+	}
+
+	if err := a.auth.LogoutAll(ctx, employee.ID); err != nil {
+		return err
+	}
+
+	return a.out.message(map[string]string{"id": employee.ID.String(), "status": "sessions_revoked"},
+		fmt.Sprintf("Сессии сотрудника %s отозваны", employee.Email))
+}
+
+// findEmployee ищет сотрудника по UUID или email
+func (a *app) findEmployee(ctx context.Context, ref string) (*domain.Employee, error) {
+	if id, err := uuid.Parse(ref); err == nil {
+		return a.employees.GetEmployee(ctx, id)
+	}
+	return a.employeeRepo.GetByEmail(ctx, ref)
+}
+
+// generatePassword создаёт случайный пароль из 22 символов
+func generatePassword() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("не удалось сгенерировать пароль: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Использование: tmctl %s [параметры]\n", name)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseFlags разбирает параметры команды и возвращает позиционные аргументы, проверив их число.
+// Позиционные аргументы могут стоять и до параметров: tmctl password reset user@example.com -password ...
+func parseFlags(flags *flag.FlagSet, args []string, positional int) ([]string, error) {
+	var values []string
+	for len(args) > 0 && len(values) < positional && !strings.HasPrefix(args[0], "-") {
+		values = append(values, args[0])
+		args = args[1:]
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	values = append(values, flags.Args()...)
+
+	if len(values) != positional {
+		flags.Usage()
+		return nil, errUsage
+	}
+	return values, nil
+}
@@ -0,0 +1,199 @@
+// Команда tmctl - утилита администратора Task Manager: управление сотрудниками, паролями
+// и сессиями, очистка удалённых данных, выгрузка и загрузка рабочего пространства, импорт задач
+// из других трекеров. Использует ту же конфигурацию, что и API.
+package main
+
+import (
+	"context"
+	goerrors "errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dmitry/taskmanager/internal/config"
+	"github.com/dmitry/taskmanager/internal/database"
+	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/internal/service"
+	"github.com/dmitry/taskmanager/internal/storage"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/logger"
+	"github.com/dmitry/taskmanager/pkg/validator"
+)
+
+const usage = `Использование: tmctl [-o table|json] <команда> [параметры]
+
+Команды:
+  employee list [-department D]                       список сотрудников
+  employee create -name N -email E -department D -position P [-password P]
+                                                      создать сотрудника; без -password пароль генерируется
+  employee promote <id|email> -position P [-department D]
+                                                      перевести сотрудника на новую должность
+  employee deactivate <id|email>                      деактивировать сотрудника и отозвать его сессии
+  password reset <id|email> [-password P]             сбросить пароль и отозвать сессии
+  sessions revoke <id|email>                          отозвать все сессии сотрудника
+  purge -older-than-days N [-dry-run]                 безвозвратно удалить данные, удалённые более N дней назад
+  export [-with-password-hashes] <файл|->             выгрузить рабочее пространство в архив (.gz - сжатый)
+  import [-remap] [-dry-run] [-batch-size N] <файл|->
+                                                      загрузить архив рабочего пространства
+  tasks import -source jira|trello|csv [-mapping M] [-project ID] [-dry-run] <файл|->
+                                                      создать задачи по выгрузке Jira, Trello или CSV
+
+Параметры каждой команды: tmctl <команда> -h`
+
+// errUsage - неверный вызов команды; описание уже выведено
+var errUsage = goerrors.New("неверные параметры")
+
+var commands = map[string]func(a *app, ctx context.Context, args []string) error{
+	"employee list":       (*app).listEmployees,
+	"employee create":     (*app).createEmployee,
+	"employee promote":    (*app).promoteEmployee,
+	"employee deactivate": (*app).deactivateEmployee,
+	"password reset":      (*app).resetPassword,
+	"sessions revoke":     (*app).revokeSessions,
+	"tasks import":        (*app).importTasks,
+	"purge":               (*app).purge,
+	"export":              (*app).exportWorkspace,
+	"import":              (*app).importWorkspace,
+}
+
+// singleWordCommands - команды из одного слова, без подкоманды
+var singleWordCommands = map[string]bool{
+	"purge":  true,
+	"export": true,
+	"import": true,
+}
+
+// app - зависимости команд
+type app struct {
+	employeeRepo repository.EmployeeRepository
+	employees    *service.EmployeeService
+	auth         *service.AuthService
+	maintenance  *service.MaintenanceService
+	workspace    *service.WorkspaceService
+	taskImport   *service.TaskImportService
+	taskMaxDepth int
+	validator    *validator.Validator
+	out          *printer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	flags := flag.NewFlagSet("tmctl", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	output := flags.String("o", "table", "формат вывода: table или json")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *output != "table" && *output != "json" {
+		fmt.Fprintln(os.Stderr, "Неверный формат вывода:", *output)
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	command := flags.Arg(0)
+	args = flags.Args()[1:]
+	if !singleWordCommands[command] {
+		if len(args) == 0 {
+			flags.Usage()
+			return 2
+		}
+		command += " " + args[0]
+		args = args[1:]
+	}
+
+	handler, ok := commands[command]
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Неизвестная команда:", command)
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка:", err)
+		return 1
+	}
+	log := logger.NewWithOptions(logger.Options{
+		Level:        cfg.LogLevel,
+		Writer:       os.Stderr,
+		RedactFields: cfg.LogRedactFields,
+	})
+	logger.SetDefault(log)
+
+	db, err := database.NewPostgres(cfg, log)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка:", err)
+		return 1
+	}
+	defer db.Close()
+
+	blobStorage, err := storage.New(cfg, log)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Не удалось инициализировать хранилище вложений:", err)
+		return 1
+	}
+
+	employeeRepo := repository.NewEmployeeRepository(db.DB)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db.DB)
+	attachmentRepo := repository.NewAttachmentRepository(db.DB)
+	maintenanceRepo := repository.NewMaintenanceRepository(db.DB)
+	workspaceRepo := repository.NewWorkspaceRepository(db.DB)
+	taskRepo := repository.NewTaskRepository(db.DB)
+	participantRepo := repository.NewTaskParticipantRepository(db.DB)
+	messageRepo := repository.NewMessageRepository(db.DB)
+	timeEntryRepo := repository.NewTimeEntryRepository(db.DB)
+	projectRepo := repository.NewProjectRepository(db.DB)
+	transitionRepo := repository.NewStatusTransitionRepository(db.DB)
+
+	jwtService := service.NewJWTService(cfg.JWTSecret, cfg.JWTAccessExpiryMin, cfg.JWTRefreshExpiryDays)
+
+	a := &app{
+		employeeRepo: employeeRepo,
+		employees:    service.NewEmployeeService(employeeRepo),
+		auth:         service.NewAuthService(employeeRepo, refreshTokenRepo, jwtService),
+		maintenance:  service.NewMaintenanceService(maintenanceRepo, attachmentRepo, blobStorage, db.DB),
+		workspace:    service.NewWorkspaceService(workspaceRepo, transitionRepo, db.DB),
+		taskImport: service.NewTaskImportService(taskRepo, participantRepo, messageRepo, timeEntryRepo,
+			employeeRepo, projectRepo, transitionRepo, db.DB),
+		taskMaxDepth: cfg.TaskMaxDepth,
+		validator:    validator.New(),
+		out:          &printer{format: *output},
+	}
+
+	if err := handler(a, context.Background(), args); err != nil {
+		if err == errUsage || err == flag.ErrHelp {
+			return 2
+		}
+		fmt.Fprintln(os.Stderr, "Ошибка:", describeError(err))
+		return 1
+	}
+	return 0
+}
+
+// describeError возвращает сообщение ошибки вместе с ошибками валидации полей
+func describeError(err error) string {
+	var appErr *errors.AppError
+	if !goerrors.As(err, &appErr) {
+		return err.Error()
+	}
+
+	message := appErr.Message
+	details := make([]string, len(appErr.Details))
+	for i, d := range appErr.Details {
+		details[i] = d.Field + ": " + d.Message
+	}
+	if len(details) > 0 {
+		message += " (" + strings.Join(details, "; ") + ")"
+	}
+	if appErr.Err != nil {
+		message += ": " + appErr.Err.Error()
+	}
+	return message
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// printer выводит результат команды таблицей или в JSON
+type printer struct {
+	format string
+}
+
+// table выводит value в JSON либо строки rows с заголовком header
+func (p *printer) table(value interface{}, header []string, rows [][]string) error {
+	if p.format == "json" {
+		return p.json(value)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// message выводит value в JSON либо текстовое сообщение
+func (p *printer) message(value interface{}, text string) error {
+	if p.format == "json" {
+		return p.json(value)
+	}
+
+	_, err := fmt.Println(text)
+	return err
+}
+
+func (p *printer) json(value interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
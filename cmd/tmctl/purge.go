@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+)
+
+func (a *app) purge(ctx context.Context, args []string) error {
+	flags := newFlagSet("purge")
+	days := flags.Int("older-than-days", 0, "удалить данные, мягко удалённые более N дней назад")
+	dryRun := flags.Bool("dry-run", false, "только посчитать строки, ничего не удаляя")
+	if _, err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+	if *days <= 0 {
+		fmt.Fprintln(os.Stderr, "Параметр -older-than-days должен быть больше 0")
+		return errUsage
+	}
+
+	before := time.Now().AddDate(0, 0, -*days)
+	counts, files, err := a.maintenance.PurgeDeleted(ctx, before, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	if a.out.format == "json" {
+		return a.out.json(struct {
+			Before time.Time           `json:"before"`
+			DryRun bool                `json:"dry_run"`
+			Tables []domain.PurgeCount `json:"tables"`
+			Files  int                 `json:"files"`
+		}{before, *dryRun, counts, files})
+	}
+
+	if *dryRun {
+		fmt.Println("Пробный запуск: изменения не сохранены")
+	}
+	rows := make([][]string, 0, len(counts)+1)
+	for _, c := range counts {
+		rows = append(rows, []string{c.Table, strconv.FormatInt(c.Rows, 10)})
+	}
+	if !*dryRun {
+		rows = append(rows, []string{"файлы вложений", strconv.Itoa(files)})
+	}
+	return a.out.table(nil, []string{"ТАБЛИЦА", "УДАЛЕНО"}, rows)
+}
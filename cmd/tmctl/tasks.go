@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/dmitry/taskmanager/internal/importer"
+	"github.com/dmitry/taskmanager/internal/service"
+	"github.com/google/uuid"
+)
+
+// importTasks создаёт задачи по выгрузке Jira, Trello или CSV
+func (a *app) importTasks(ctx context.Context, args []string) error {
+	flags := newFlagSet("tasks import <файл|->")
+	source := flags.String("source", "", "формат выгрузки: jira, trello или csv")
+	mappingPath := flags.String("mapping", "", "файл сопоставления статусов, приоритетов и пользователей (YAML)")
+	projectRef := flags.String("project", "", "UUID проекта, в который попадут задачи")
+	dryRun := flags.Bool("dry-run", false, "выполнить импорт и откатить его, показав отчёт")
+	values, err := parseFlags(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	if !importer.Source(*source).IsValid() {
+		fmt.Fprintln(os.Stderr, "Параметр -source должен быть jira, trello или csv")
+		return errUsage
+	}
+
+	opts := service.TaskImportOptions{
+		Source:   importer.Source(*source),
+		Mapping:  importer.DefaultMapping(),
+		MaxDepth: a.taskMaxDepth,
+		DryRun:   *dryRun,
+	}
+	if *projectRef != "" {
+		projectID, err := uuid.Parse(*projectRef)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Параметр -project должен быть UUID проекта")
+			return errUsage
+		}
+		opts.ProjectID = &projectID
+	}
+	if *mappingPath != "" {
+		f, err := os.Open(*mappingPath)
+		if err != nil {
+			return err
+		}
+		opts.Mapping, err = importer.LoadMapping(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	var r io.Reader = os.Stdin
+	if values[0] != "-" {
+		f, err := os.Open(values[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	issues, err := importer.Parse(opts.Source, r)
+	if err != nil {
+		return err
+	}
+
+	report, err := a.taskImport.Import(ctx, issues, opts)
+	if err != nil {
+		return err
+	}
+	return a.printTaskImportReport(report)
+}
+
+func (a *app) printTaskImportReport(report *service.TaskImportReport) error {
+	if a.out.format == "json" {
+		return a.out.json(report)
+	}
+
+	if report.DryRun {
+		fmt.Println("Пробный запуск: изменения не сохранены")
+	}
+	err := a.out.table(nil, []string{"ЗАПИСИ", "ЧИСЛО"}, [][]string{
+		{"задачи", strconv.Itoa(report.Tasks)},
+		{"пропущено задач", strconv.Itoa(report.SkippedTasks)},
+		{"исполнители", strconv.Itoa(report.Participants)},
+		{"сообщения", strconv.Itoa(report.Messages)},
+		{"записи времени", strconv.Itoa(report.TimeEntries)},
+	})
+	if err != nil {
+		return err
+	}
+
+	unmapped := []struct {
+		title  string
+		values map[string]int
+	}{
+		{"НЕСОПОСТАВЛЕННЫЙ СТАТУС", report.UnmappedStatuses},
+		{"НЕСОПОСТАВЛЕННЫЙ ПРИОРИТЕТ", report.UnmappedPriorities},
+		{"НЕСОПОСТАВЛЕННЫЙ ПОЛЬЗОВАТЕЛЬ", report.UnmappedUsers},
+	}
+	for _, u := range unmapped {
+		if len(u.values) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(u.values))
+		for name := range u.values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		rows := make([][]string, len(names))
+		for i, name := range names {
+			rows[i] = []string{name, strconv.Itoa(u.values[name])}
+		}
+		fmt.Println()
+		if err := a.out.table(nil, []string{u.title, "УПОМИНАНИЙ"}, rows); err != nil {
+			return err
+		}
+	}
+
+	if len(report.Issues) == 0 {
+		return nil
+	}
+	rows := make([][]string, len(report.Issues))
+	for i, issue := range report.Issues {
+		rows[i] = []string{issue.Key, issue.Message}
+	}
+	fmt.Println()
+	if err := a.out.table(nil, []string{"ЗАДАЧА", "ПРОБЛЕМА"}, rows); err != nil {
+		return err
+	}
+	if report.IssuesTotal > len(report.Issues) {
+		fmt.Printf("... и ещё %d\n", report.IssuesTotal-len(report.Issues))
+	}
+	return nil
+}
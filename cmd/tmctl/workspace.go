@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/service"
+)
+
+// exportWorkspace пишет архив в файл или, если указан "-", в стандартный вывод.
+// Файл с расширением .gz сжимается.
+func (a *app) exportWorkspace(ctx context.Context, args []string) error {
+	flags := newFlagSet("export <файл|->")
+	withHashes := flags.Bool("with-password-hashes", false, "выгрузить хеши паролей сотрудников")
+	values, err := parseFlags(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	path := values[0]
+
+	var w io.Writer = os.Stdout
+	var file *os.File
+	if path != "-" {
+		if file, err = os.Create(path); err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	var gz *gzip.Writer
+	if strings.HasSuffix(path, ".gz") {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+
+	counts, err := a.workspace.Export(ctx, w, service.ExportOptions{WithPasswordHashes: *withHashes})
+	if err != nil {
+		return err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return err
+		}
+	}
+	if file != nil {
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+
+	// Архив в стандартном выводе не смешивается с отчётом
+	if path == "-" {
+		return nil
+	}
+	rows := make([][]string, 0, len(domain.ArchiveRecordOrder))
+	for _, recordType := range domain.ArchiveRecordOrder {
+		rows = append(rows, []string{string(recordType), strconv.Itoa(counts[recordType])})
+	}
+	return a.out.table(counts, []string{"ТИП", "ВЫГРУЖЕНО"}, rows)
+}
+
+// importWorkspace загружает архив из файла или стандартного ввода; сжатый архив
+// распознаётся по содержимому
+func (a *app) importWorkspace(ctx context.Context, args []string) error {
+	flags := newFlagSet("import <файл|->")
+	remap := flags.Bool("remap", false, "выдать записям новые идентификаторы вместо сохранения исходных")
+	dryRun := flags.Bool("dry-run", false, "проверить архив и показать конфликты, ничего не сохраняя")
+	batchSize := flags.Int("batch-size", 500, "число записей в одной транзакции")
+	values, err := parseFlags(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	if *batchSize <= 0 {
+		fmt.Fprintln(os.Stderr, "Параметр -batch-size должен быть больше 0")
+		return errUsage
+	}
+
+	var r io.Reader = os.Stdin
+	if values[0] != "-" {
+		f, err := os.Open(values[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	buffered := bufio.NewReader(r)
+	r = buffered
+	if magic, _ := buffered.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	report, importErr := a.workspace.Import(ctx, r, service.ImportOptions{
+		Remap:     *remap,
+		DryRun:    *dryRun,
+		BatchSize: *batchSize,
+	})
+	if report == nil {
+		return importErr
+	}
+	if err := a.printImportReport(report); err != nil {
+		return err
+	}
+	return importErr
+}
+
+func (a *app) printImportReport(report *service.ImportReport) error {
+	if a.out.format == "json" {
+		return a.out.json(report)
+	}
+
+	if report.DryRun {
+		fmt.Println("Пробный запуск: изменения не сохранены")
+	}
+	rows := make([][]string, 0, len(domain.ArchiveRecordOrder))
+	for _, recordType := range domain.ArchiveRecordOrder {
+		rows = append(rows, []string{
+			string(recordType),
+			strconv.Itoa(report.Imported[recordType]),
+			strconv.Itoa(report.Matched[recordType]),
+			strconv.Itoa(report.Skipped[recordType]),
+		})
+	}
+	if err := a.out.table(nil, []string{"ТИП", "ЗАГРУЖЕНО", "СОПОСТАВЛЕНО", "ПРОПУЩЕНО"}, rows); err != nil {
+		return err
+	}
+
+	if len(report.Issues) == 0 {
+		return nil
+	}
+	fmt.Println()
+	rows = make([][]string, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		rows = append(rows, []string{issue.Kind, string(issue.Type), issue.ID.String(), issue.Message})
+	}
+	if err := a.out.table(nil, []string{"ПРОБЛЕМА", "ТИП", "ID", "ОПИСАНИЕ"}, rows); err != nil {
+		return err
+	}
+	if report.IssuesTotal > len(report.Issues) {
+		fmt.Printf("... и ещё %d\n", report.IssuesTotal-len(report.Issues))
+	}
+	return nil
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"os"
-	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -15,47 +19,202 @@ type Config struct {
 	LogLevel      string
 	Environment   string
 
+	// MetricsAddress - адрес отдельного сервера метрик Prometheus; "off" - метрики не отдаются
+	MetricsAddress string
+
+	// LogSampleRate - доля запросов, для которых пишутся информационные записи журнала;
+	// предупреждения и ошибки пишутся всегда
+	LogSampleRate float64
+	// LogRedactFields - поля, значения которых скрываются в журнале
+	LogRedactFields []string
+
+	// HealthCheckTimeout - время на проверку каждой зависимости в /readyz
+	HealthCheckTimeout time.Duration
+	// ShutdownDelay - пауза между отказом /readyz и остановкой сервера при завершении,
+	// за которую балансировщик успевает исключить экземпляр
+	ShutdownDelay time.Duration
+
 	DBMaxOpenConns int
 	DBMaxIdleConns int
-	DBMaxIdleTime  string
+	DBMaxIdleTime  time.Duration
+	// DBAutoMigrate - применять миграции схемы при запуске
+	DBAutoMigrate bool
 
 	// Конфигурация JWT
 	JWTSecret            string
 	JWTAccessExpiryMin   int
 	JWTRefreshExpiryDays int
+
+	// Хранилище вложений
+	StorageDriver    string
+	StorageLocalPath string
+	S3Endpoint       string
+	S3AccessKey      string
+	S3SecretKey      string
+	S3Bucket         string
+	S3Region         string
+	S3UseSSL         bool
+
+	// Ограничения вложений
+	AttachmentMaxSizeMB    int
+	AttachmentAllowedTypes []string
+	AttachmentLinkTTLMin   int
+
+	// Правила работы с задачами
+	TaskMaxDepth                    int
+	TaskCloseRequiresClosedSubtasks bool
+	TaskBlockedTransition           string
+
+	// RecurrenceCheckInterval - период проверки наступивших повторений задач
+	RecurrenceCheckInterval time.Duration
+
+	// WIP-лимиты колонок доски: статус -> максимальное число задач
+	BoardWIPLimits map[string]int
+
+	// Отделы, которым доступны табели всех сотрудников
+	ReportDepartments []string
+
+	// Трассировка OpenTelemetry: экспортёр none, stdout или otlp
+	TracingExporter string
+	// TracingEndpoint - адрес коллектора OTLP/HTTP (host:port)
+	TracingEndpoint    string
+	TracingInsecure    bool
+	TracingSampleRatio float64
+
+	// PrintConfig - задан флаг --print-config: вывести итоговую конфигурацию и завершиться
+	PrintConfig bool
 }
 
-func Load() *Config {
-	godotenv.Load()
+// settings описывает все параметры конфигурации. Имя параметра - переменная окружения;
+// в файле конфигурации используется то же имя в нижнем регистре (db_max_open_conns),
+// во флагах - через дефис (--db-max-open-conns).
+func (c *Config) settings() []setting {
+	return []setting{
+		{name: "SERVER_ADDRESS", value: stringValue(&c.ServerAddress), def: ":8080"},
+		{name: "METRICS_ADDRESS", value: stringValue(&c.MetricsAddress), def: ":9090"},
+		{name: "DATABASE_URL", value: stringValue(&c.DatabaseURL), secret: true},
+		{name: "REDIS_URL", value: stringValue(&c.RedisURL), def: "redis://localhost:6379/0", secret: true},
+		{name: "FRONTEND_URL", value: stringValue(&c.FrontendURL), def: "http://localhost:8081"},
+		{name: "LOG_LEVEL", value: stringValue(&c.LogLevel), def: "info", reloadable: true},
+		{name: "LOG_SAMPLE_RATE", value: floatValue(&c.LogSampleRate), def: "1", reloadable: true},
+		{name: "LOG_REDACT_FIELDS", value: listValue(&c.LogRedactFields)},
+		{name: "ENVIRONMENT", value: stringValue(&c.Environment), def: "development"},
+		{name: "HEALTH_CHECK_TIMEOUT", value: durationValue(&c.HealthCheckTimeout), def: "2s"},
+		{name: "SHUTDOWN_DELAY", value: durationValue(&c.ShutdownDelay), def: "5s"},
+
+		{name: "DB_MAX_OPEN_CONNS", value: intValue(&c.DBMaxOpenConns), def: "25"},
+		{name: "DB_MAX_IDLE_CONNS", value: intValue(&c.DBMaxIdleConns), def: "5"},
+		{name: "DB_MAX_IDLE_TIME", value: durationValue(&c.DBMaxIdleTime), def: "15m"},
+		{name: "DB_AUTO_MIGRATE", value: boolValue(&c.DBAutoMigrate), def: "false"},
+
+		{name: "JWT_SECRET", value: stringValue(&c.JWTSecret), secret: true},
+		{name: "JWT_ACCESS_EXPIRY_MIN", value: intValue(&c.JWTAccessExpiryMin), def: "15"},
+		{name: "JWT_REFRESH_EXPIRY_DAYS", value: intValue(&c.JWTRefreshExpiryDays), def: "7"},
+
+		{name: "STORAGE_DRIVER", value: stringValue(&c.StorageDriver), def: "local"},
+		{name: "STORAGE_LOCAL_PATH", value: stringValue(&c.StorageLocalPath), def: "./data/attachments"},
+		{name: "S3_ENDPOINT", value: stringValue(&c.S3Endpoint), def: "localhost:9000"},
+		{name: "S3_ACCESS_KEY", value: stringValue(&c.S3AccessKey), secret: true},
+		{name: "S3_SECRET_KEY", value: stringValue(&c.S3SecretKey), secret: true},
+		{name: "S3_BUCKET", value: stringValue(&c.S3Bucket), def: "taskmanager-attachments"},
+		{name: "S3_REGION", value: stringValue(&c.S3Region)},
+		{name: "S3_USE_SSL", value: boolValue(&c.S3UseSSL), def: "false"},
+
+		{name: "ATTACHMENT_MAX_SIZE_MB", value: intValue(&c.AttachmentMaxSizeMB), def: "20"},
+		{name: "ATTACHMENT_ALLOWED_TYPES", value: listValue(&c.AttachmentAllowedTypes),
+			def: "image/png,image/jpeg,image/gif,image/webp,text/plain,application/pdf,application/zip,application/gzip,application/json"},
+		{name: "ATTACHMENT_LINK_TTL_MIN", value: intValue(&c.AttachmentLinkTTLMin), def: "15"},
 
-	return &Config{
-		ServerAddress:        getEnv("SERVER_ADDRESS", ":8080"),
-		DatabaseURL:          getEnv("DATABASE_URL", ""),
-		RedisURL:             getEnv("REDIS_URL", "redis://localhost:6379/0"),
-		FrontendURL:          getEnv("FRONTEND_URL", "http://localhost:8081"),
-		LogLevel:             getEnv("LOG_LEVEL", "info"),
-		Environment:          getEnv("ENVIRONMENT", "development"),
-		DBMaxOpenConns:       getEnvInt("DB_MAX_OPEN_CONNS", 25),
-		DBMaxIdleConns:       getEnvInt("DB_MAX_IDLE_CONNS", 5),
-		DBMaxIdleTime:        getEnv("DB_MAX_IDLE_TIME", "15m"),
-		JWTSecret:            getEnv("JWT_SECRET", ""),
-		JWTAccessExpiryMin:   getEnvInt("JWT_ACCESS_EXPIRY_MIN", 15),
-		JWTRefreshExpiryDays: getEnvInt("JWT_REFRESH_EXPIRY_DAYS", 7),
+		{name: "TASK_MAX_DEPTH", value: intValue(&c.TaskMaxDepth), def: "5"},
+		{name: "TASK_CLOSE_REQUIRES_CLOSED_SUBTASKS", value: boolValue(&c.TaskCloseRequiresClosedSubtasks), def: "false"},
+		{name: "TASK_BLOCKED_TRANSITION", value: stringValue(&c.TaskBlockedTransition), def: "warn"},
+		{name: "RECURRENCE_CHECK_INTERVAL", value: durationValue(&c.RecurrenceCheckInterval), def: "1m"},
+
+		{name: "BOARD_WIP_LIMITS", value: intMapValue(&c.BoardWIPLimits)},
+
+		{name: "REPORT_DEPARTMENTS", value: listValue(&c.ReportDepartments)},
+
+		{name: "TRACING_EXPORTER", value: stringValue(&c.TracingExporter), def: "none"},
+		{name: "OTLP_ENDPOINT", value: stringValue(&c.TracingEndpoint), def: "localhost:4318"},
+		{name: "OTLP_INSECURE", value: boolValue(&c.TracingInsecure), def: "true"},
+		{name: "TRACING_SAMPLE_RATIO", value: floatValue(&c.TracingSampleRatio), def: "1"},
 	}
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// Load собирает конфигурацию по слоям: значения по умолчанию, файл конфигурации
+// (--config или CONFIG_FILE, YAML или TOML), переменные окружения (и .env), флаги args.
+// Для секретов вместо значения можно указать файл: JWT_SECRET_FILE=/run/secrets/jwt.
+// Ошибки разбора и проверки не заменяются значениями по умолчанию: возвращается
+// *ValidationError со списком всех проблем.
+func Load(args []string) (*Config, error) {
+	godotenv.Load()
+
+	c := &Config{}
+	settings := c.settings()
+	problems := []string{}
+
+	for _, s := range settings {
+		if err := s.value.set(s.def); err != nil {
+			panic(fmt.Sprintf("config: неверное значение по умолчанию %s: %v", s.name, err))
+		}
+	}
+
+	fs, overrides := newFlagSet(c, settings)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	configFile := fs.Lookup("config").Value.String()
+	if configFile == "" {
+		configFile = os.Getenv("CONFIG_FILE")
+	}
+	if configFile != "" {
+		problems = append(problems, applyFile(configFile, settings)...)
+	}
+
+	problems = append(problems, applyEnv(settings)...)
+
+	for _, override := range *overrides {
+		if err := override.setting.value.set(override.value); err != nil {
+			problems = append(problems, fmt.Sprintf("флаг --%s: %v", flagName(override.setting.name), err))
+		}
 	}
-	return defaultValue
+
+	problems = append(problems, c.validate()...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	return c, nil
+}
+
+// ValidationError перечисляет все найденные проблемы конфигурации
+type ValidationError struct {
+	Problems []string
 }
 
-func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intVal, err := strconv.Atoi(value); err == nil {
-			return intVal
+func (e *ValidationError) Error() string {
+	return "некорректная конфигурация:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// IsHelp сообщает, что вместо запуска запрошена справка по флагам (-h)
+func IsHelp(err error) bool {
+	return errors.Is(err, flag.ErrHelp)
+}
+
+// Changes сравнивает конфигурацию с next и возвращает изменившиеся параметры, которые
+// применяются без перезапуска (reloadable), и те, что требуют перезапуска (restart)
+func (c *Config) Changes(next *Config) (reloadable, restart []string) {
+	current, updated := c.settings(), next.settings()
+	for i, s := range current {
+		if s.value.get() == updated[i].value.get() {
+			continue
+		}
+		if s.reloadable {
+			reloadable = append(reloadable, s.name)
+		} else {
+			restart = append(restart, s.name)
 		}
 	}
-	return defaultValue
+	return reloadable, restart
 }
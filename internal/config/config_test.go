@@ -0,0 +1,404 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// isolateEnv сбрасывает переменные окружения всех параметров, чтобы окружение, в котором
+// запущены тесты, не влияло на результат (пустая переменная считается незаданной), и
+// задаёт обязательные параметры
+func isolateEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("CONFIG_FILE", "")
+	for _, s := range (&Config{}).settings() {
+		t.Setenv(s.name, "")
+		t.Setenv(s.name+"_FILE", "")
+	}
+	t.Setenv("DATABASE_URL", "postgres://localhost/test")
+	t.Setenv("JWT_SECRET", "test-secret")
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func problems(t *testing.T, err error) []string {
+	t.Helper()
+	var validation *ValidationError
+	if !errors.As(err, &validation) {
+		t.Fatalf("ожидалась *ValidationError, получено %v", err)
+	}
+	return validation.Problems
+}
+
+func TestLoadDefaults(t *testing.T) {
+	isolateEnv(t)
+
+	c, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.ServerAddress != ":8080" || c.MetricsAddress != ":9090" || c.LogLevel != "info" {
+		t.Errorf("адреса и уровень журнала: %q, %q, %q", c.ServerAddress, c.MetricsAddress, c.LogLevel)
+	}
+	if c.DBMaxOpenConns != 25 || c.DBMaxIdleTime != 15*time.Minute || c.RecurrenceCheckInterval != time.Minute {
+		t.Errorf("числовые параметры: %d, %s, %s", c.DBMaxOpenConns, c.DBMaxIdleTime, c.RecurrenceCheckInterval)
+	}
+	if c.LogSampleRate != 1 || c.TracingInsecure != true || c.DBAutoMigrate != false {
+		t.Errorf("LOG_SAMPLE_RATE = %v, OTLP_INSECURE = %v, DB_AUTO_MIGRATE = %v", c.LogSampleRate, c.TracingInsecure, c.DBAutoMigrate)
+	}
+	if len(c.AttachmentAllowedTypes) != 9 || c.AttachmentAllowedTypes[0] != "image/png" {
+		t.Errorf("ATTACHMENT_ALLOWED_TYPES = %v", c.AttachmentAllowedTypes)
+	}
+	if len(c.BoardWIPLimits) != 0 || len(c.ReportDepartments) != 0 {
+		t.Errorf("пустые по умолчанию списки: %v, %v", c.BoardWIPLimits, c.ReportDepartments)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	yamlFile := "server_address: \":7000\"\nlog_level: warn\n"
+	tomlFile := "server_address = \":7000\"\nlog_level = \"warn\"\n"
+
+	tests := []struct {
+		name     string
+		file     string
+		fileName string
+		env      map[string]string
+		args     []string
+		address  string
+		logLevel string
+	}{
+		{
+			name:     "значения по умолчанию",
+			address:  ":8080",
+			logLevel: "info",
+		},
+		{
+			name:     "файл YAML",
+			file:     yamlFile,
+			fileName: "config.yaml",
+			address:  ":7000",
+			logLevel: "warn",
+		},
+		{
+			name:     "файл TOML",
+			file:     tomlFile,
+			fileName: "config.toml",
+			address:  ":7000",
+			logLevel: "warn",
+		},
+		{
+			name:     "окружение важнее файла",
+			file:     yamlFile,
+			fileName: "config.yml",
+			env:      map[string]string{"SERVER_ADDRESS": ":7100"},
+			address:  ":7100",
+			logLevel: "warn",
+		},
+		{
+			name:     "флаг важнее окружения и файла",
+			file:     yamlFile,
+			fileName: "config.yaml",
+			env:      map[string]string{"SERVER_ADDRESS": ":7100", "LOG_LEVEL": "error"},
+			args:     []string{"--server-address", ":7200"},
+			address:  ":7200",
+			logLevel: "error",
+		},
+		{
+			name:     "флаг без файла",
+			args:     []string{"--log-level=debug"},
+			address:  ":8080",
+			logLevel: "debug",
+		},
+		{
+			name:     "пустая переменная окружения не заменяет файл",
+			file:     yamlFile,
+			fileName: "config.yaml",
+			env:      map[string]string{"LOG_LEVEL": ""},
+			address:  ":7000",
+			logLevel: "warn",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"--config", writeFile(t, tt.fileName, tt.file)}, args...)
+			}
+
+			c, err := Load(args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.ServerAddress != tt.address || c.LogLevel != tt.logLevel {
+				t.Errorf("SERVER_ADDRESS = %q, LOG_LEVEL = %q; ожидалось %q, %q", c.ServerAddress, c.LogLevel, tt.address, tt.logLevel)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileFromEnv(t *testing.T) {
+	isolateEnv(t)
+	t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", "log_level: debug\n"))
+
+	c, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.LogLevel != "debug" {
+		t.Errorf("LOG_LEVEL = %q, файл из CONFIG_FILE не применён", c.LogLevel)
+	}
+}
+
+func TestLoadFileValueTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		content  string
+	}{
+		{
+			"YAML",
+			"config.yaml",
+			`db_max_open_conns: 40
+db_auto_migrate: true
+log_sample_rate: 0.25
+recurrence_check_interval: 30s
+attachment_allowed_types: [image/png, application/pdf]
+board_wip_limits:
+  in_progress: 5
+  code_review: 3
+`,
+		},
+		{
+			"TOML",
+			"config.toml",
+			`db_max_open_conns = 40
+db_auto_migrate = true
+log_sample_rate = 0.25
+recurrence_check_interval = "30s"
+attachment_allowed_types = ["image/png", "application/pdf"]
+
+[board_wip_limits]
+in_progress = 5
+code_review = 3
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateEnv(t)
+
+			c, err := Load([]string{"--config", writeFile(t, tt.fileName, tt.content)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.DBMaxOpenConns != 40 || !c.DBAutoMigrate || c.LogSampleRate != 0.25 || c.RecurrenceCheckInterval != 30*time.Second {
+				t.Errorf("скалярные значения: %d, %v, %v, %s", c.DBMaxOpenConns, c.DBAutoMigrate, c.LogSampleRate, c.RecurrenceCheckInterval)
+			}
+			if want := []string{"image/png", "application/pdf"}; !reflect.DeepEqual(c.AttachmentAllowedTypes, want) {
+				t.Errorf("ATTACHMENT_ALLOWED_TYPES = %v", c.AttachmentAllowedTypes)
+			}
+			if want := map[string]int{"in_progress": 5, "code_review": 3}; !reflect.DeepEqual(c.BoardWIPLimits, want) {
+				t.Errorf("BOARD_WIP_LIMITS = %v", c.BoardWIPLimits)
+			}
+		})
+	}
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	secret := writeFile(t, "jwt", "secret-from-file\n")
+
+	t.Run("переменная _FILE", func(t *testing.T) {
+		isolateEnv(t)
+		t.Setenv("JWT_SECRET", "")
+		t.Setenv("JWT_SECRET_FILE", secret)
+
+		c, err := Load(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.JWTSecret != "secret-from-file" {
+			t.Errorf("JWT_SECRET = %q, перевод строки должен отбрасываться", c.JWTSecret)
+		}
+	})
+
+	t.Run("ключ _file в файле конфигурации", func(t *testing.T) {
+		isolateEnv(t)
+		t.Setenv("JWT_SECRET", "")
+
+		c, err := Load([]string{"--config", writeFile(t, "config.yaml", "jwt_secret_file: "+secret+"\n")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.JWTSecret != "secret-from-file" {
+			t.Errorf("JWT_SECRET = %q", c.JWTSecret)
+		}
+	})
+
+	t.Run("_FILE только для секретов", func(t *testing.T) {
+		isolateEnv(t)
+		t.Setenv("LOG_LEVEL_FILE", writeFile(t, "level", "debug"))
+
+		c, err := Load(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.LogLevel != "info" {
+			t.Errorf("LOG_LEVEL = %q, LOG_LEVEL_FILE должен игнорироваться", c.LogLevel)
+		}
+	})
+
+	t.Run("файл секрета не найден", func(t *testing.T) {
+		isolateEnv(t)
+		t.Setenv("JWT_SECRET_FILE", filepath.Join(t.TempDir(), "missing"))
+
+		_, err := Load(nil)
+		got := problems(t, err)
+		if len(got) != 1 || !strings.HasPrefix(got[0], "JWT_SECRET_FILE: не удалось прочитать секрет") {
+			t.Errorf("проблемы: %q", got)
+		}
+	})
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		content  string
+		want     []string
+	}{
+		{
+			"неизвестные ключи",
+			"config.yaml",
+			"db_max_conns: 5\nlog_level_file: /tmp/level\nlog_level: info\n",
+			[]string{"неизвестный параметр db_max_conns", "неизвестный параметр log_level_file"},
+		},
+		{
+			"неверное значение",
+			"config.toml",
+			"db_max_open_conns = \"много\"\n",
+			[]string{`db_max_open_conns: ожидается целое число, получено "много"`},
+		},
+		{
+			"неподдерживаемый формат",
+			"config.json",
+			"{}",
+			[]string{`неподдерживаемый формат ".json"`},
+		},
+		{
+			"синтаксическая ошибка",
+			"config.yaml",
+			"log_level: [",
+			[]string{"config.yaml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateEnv(t)
+
+			_, err := Load([]string{"--config", writeFile(t, tt.fileName, tt.content)})
+			got := problems(t, err)
+			if len(got) != len(tt.want) {
+				t.Fatalf("проблемы: %q, ожидалось %d", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("проблема %d: %q, ожидалось содержание %q", i, got[i], want)
+				}
+			}
+		})
+	}
+
+	t.Run("файл не найден", func(t *testing.T) {
+		isolateEnv(t)
+		_, err := Load([]string{"--config", filepath.Join(t.TempDir(), "missing.yaml")})
+		if got := problems(t, err); len(got) != 1 || !strings.Contains(got[0], "не удалось прочитать файл конфигурации") {
+			t.Errorf("проблемы: %q", got)
+		}
+	})
+}
+
+func TestLoadListsAllProblems(t *testing.T) {
+	isolateEnv(t)
+	t.Setenv("DATABASE_URL", "")
+	t.Setenv("DB_MAX_OPEN_CONNS", "abc")
+	t.Setenv("LOG_LEVEL", "loud")
+	t.Setenv("BOARD_WIP_LIMITS", "archived=2,in_progress=0")
+	t.Setenv("METRICS_ADDRESS", ":8080")
+
+	_, err := Load([]string{"--tracing-sample-ratio", "2", "--shutdown-delay", "скоро"})
+	want := []string{
+		// Ошибки разбора: окружение, затем флаги
+		`DB_MAX_OPEN_CONNS: ожидается целое число, получено "abc"`,
+		`флаг --shutdown-delay: ожидается длительность вида 30s, 15m, получено "скоро"`,
+		// Проверка итоговых значений; DB_MAX_OPEN_CONNS остаётся значением по умолчанию
+		"METRICS_ADDRESS: метрики нельзя отдавать на адресе API :8080",
+		"DATABASE_URL: значение обязательно",
+		`LOG_LEVEL: ожидается debug, info, warn или error, получено "loud"`,
+		`BOARD_WIP_LIMITS: неизвестный статус "archived"`,
+		"BOARD_WIP_LIMITS: лимит колонки in_progress должен быть больше нуля",
+		"TRACING_SAMPLE_RATIO: ожидается число от 0 до 1, получено 2",
+	}
+	if got := problems(t, err); !reflect.DeepEqual(got, want) {
+		t.Errorf("проблемы:\n%s\nожидалось:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if !strings.HasPrefix(err.Error(), "некорректная конфигурация:\n  - DB_MAX_OPEN_CONNS") {
+		t.Errorf("текст ошибки: %q", err.Error())
+	}
+}
+
+func TestLoadHelp(t *testing.T) {
+	isolateEnv(t)
+	_, err := Load([]string{"-h"})
+	if !IsHelp(err) {
+		t.Errorf("IsHelp(%v) = false", err)
+	}
+}
+
+func TestChanges(t *testing.T) {
+	isolateEnv(t)
+	current, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("LOG_SAMPLE_RATE", "0.5")
+	t.Setenv("SERVER_ADDRESS", ":8081")
+	t.Setenv("BOARD_WIP_LIMITS", "in_progress=4")
+	next, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloadable, restart := current.Changes(next)
+	if want := []string{"LOG_LEVEL", "LOG_SAMPLE_RATE"}; !reflect.DeepEqual(reloadable, want) {
+		t.Errorf("reloadable = %v, ожидалось %v", reloadable, want)
+	}
+	if want := []string{"SERVER_ADDRESS", "BOARD_WIP_LIMITS"}; !reflect.DeepEqual(restart, want) {
+		t.Errorf("restart = %v, ожидалось %v", restart, want)
+	}
+
+	if reloadable, restart := current.Changes(current); reloadable != nil || restart != nil {
+		t.Errorf("без изменений: %v, %v", reloadable, restart)
+	}
+}
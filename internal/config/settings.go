@@ -0,0 +1,309 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// setting - параметр конфигурации, привязанный к полю Config
+type setting struct {
+	name  string
+	value value
+	def   string
+	// secret - значение скрывается в --print-config и может читаться из файла <NAME>_FILE
+	secret bool
+	// reloadable - параметр применяется без перезапуска по SIGHUP
+	reloadable bool
+}
+
+// value разбирает строковое значение параметра в поле Config и возвращает его обратно строкой
+type value struct {
+	set func(raw string) error
+	get func() string
+}
+
+func stringValue(p *string) value {
+	return value{
+		set: func(raw string) error { *p = raw; return nil },
+		get: func() string { return *p },
+	}
+}
+
+func intValue(p *int) value {
+	return value{
+		set: func(raw string) error {
+			n, err := strconv.Atoi(strings.TrimSpace(raw))
+			if err != nil {
+				return fmt.Errorf("ожидается целое число, получено %q", raw)
+			}
+			*p = n
+			return nil
+		},
+		get: func() string { return strconv.Itoa(*p) },
+	}
+}
+
+func floatValue(p *float64) value {
+	return value{
+		set: func(raw string) error {
+			f, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+			if err != nil {
+				return fmt.Errorf("ожидается число, получено %q", raw)
+			}
+			*p = f
+			return nil
+		},
+		get: func() string { return strconv.FormatFloat(*p, 'f', -1, 64) },
+	}
+}
+
+func boolValue(p *bool) value {
+	return value{
+		set: func(raw string) error {
+			b, err := strconv.ParseBool(strings.TrimSpace(raw))
+			if err != nil {
+				return fmt.Errorf("ожидается true или false, получено %q", raw)
+			}
+			*p = b
+			return nil
+		},
+		get: func() string { return strconv.FormatBool(*p) },
+	}
+}
+
+func durationValue(p *time.Duration) value {
+	return value{
+		set: func(raw string) error {
+			d, err := time.ParseDuration(strings.TrimSpace(raw))
+			if err != nil {
+				return fmt.Errorf("ожидается длительность вида 30s, 15m, получено %q", raw)
+			}
+			*p = d
+			return nil
+		},
+		get: func() string { return p.String() },
+	}
+}
+
+// listValue - список через запятую; пустые элементы пропускаются
+func listValue(p *[]string) value {
+	return value{
+		set: func(raw string) error {
+			items := []string{}
+			for _, item := range strings.Split(raw, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			*p = items
+			return nil
+		},
+		get: func() string { return strings.Join(*p, ",") },
+	}
+}
+
+// intMapValue - список вида "ключ=число,ключ=число"
+func intMapValue(p *map[string]int) value {
+	return value{
+		set: func(raw string) error {
+			items := map[string]int{}
+			for _, item := range strings.Split(raw, ",") {
+				if item = strings.TrimSpace(item); item == "" {
+					continue
+				}
+				name, number, ok := strings.Cut(item, "=")
+				n, err := strconv.Atoi(strings.TrimSpace(number))
+				if !ok || err != nil {
+					return fmt.Errorf("ожидается ключ=число, получено %q", item)
+				}
+				items[strings.TrimSpace(name)] = n
+			}
+			*p = items
+			return nil
+		},
+		get: func() string {
+			keys := make([]string, 0, len(*p))
+			for key := range *p {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			items := make([]string, 0, len(keys))
+			for _, key := range keys {
+				items = append(items, fmt.Sprintf("%s=%d", key, (*p)[key]))
+			}
+			return strings.Join(items, ",")
+		},
+	}
+}
+
+// flagOverride - значение флага; флаги применяются после файла и окружения
+type flagOverride struct {
+	setting setting
+	value   string
+}
+
+func newFlagSet(c *Config, settings []setting) (*flag.FlagSet, *[]flagOverride) {
+	fs := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	fs.String("config", "", "файл конфигурации YAML или TOML (по умолчанию CONFIG_FILE)")
+	fs.BoolVar(&c.PrintConfig, "print-config", false, "вывести итоговую конфигурацию со скрытыми секретами и завершиться")
+
+	overrides := &[]flagOverride{}
+	for _, s := range settings {
+		s := s
+		fs.Func(flagName(s.name), "переопределяет "+s.name, func(raw string) error {
+			*overrides = append(*overrides, flagOverride{setting: s, value: raw})
+			return nil
+		})
+	}
+	return fs, overrides
+}
+
+func flagName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+func fileKey(name string) string {
+	return strings.ToLower(name)
+}
+
+// applyFile применяет значения из файла конфигурации. Ключи - имена параметров в нижнем
+// регистре; списки задаются массивами, BOARD_WIP_LIMITS - таблицей статус: лимит.
+func applyFile(path string, settings []setting) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []string{fmt.Sprintf("не удалось прочитать файл конфигурации: %v", err)}
+	}
+
+	values := map[string]interface{}{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return []string{fmt.Sprintf("файл конфигурации %s: неподдерживаемый формат %q, ожидается .yaml, .yml или .toml", path, ext)}
+	}
+	if err != nil {
+		return []string{fmt.Sprintf("файл конфигурации %s: %v", path, err)}
+	}
+
+	problems := []string{}
+	known := map[string]bool{}
+	for _, s := range settings {
+		key := fileKey(s.name)
+		known[key] = true
+		if s.secret {
+			known[key+"_file"] = true
+		}
+
+		if raw, ok := values[key]; ok {
+			if err := s.value.set(fileValue(raw)); err != nil {
+				problems = append(problems, fmt.Sprintf("файл конфигурации %s: %s: %v", path, key, err))
+			}
+		}
+		if raw, ok := values[key+"_file"]; ok && s.secret {
+			if err := setFromFile(s, fileValue(raw)); err != nil {
+				problems = append(problems, fmt.Sprintf("файл конфигурации %s: %s_file: %v", path, key, err))
+			}
+		}
+	}
+
+	unknown := []string{}
+	for key := range values {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		problems = append(problems, fmt.Sprintf("файл конфигурации %s: неизвестный параметр %s", path, key))
+	}
+
+	return problems
+}
+
+// fileValue приводит значение из YAML или TOML к строковой записи, как в переменной окружения
+func fileValue(raw interface{}) string {
+	switch v := raw.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fileValue(item))
+		}
+		return strings.Join(items, ",")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := make([]string, 0, len(keys))
+		for _, key := range keys {
+			items = append(items, key+"="+fileValue(v[key]))
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// applyEnv применяет переменные окружения; пустая переменная считается незаданной
+func applyEnv(settings []setting) []string {
+	problems := []string{}
+	for _, s := range settings {
+		if raw := os.Getenv(s.name); raw != "" {
+			if err := s.value.set(raw); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", s.name, err))
+			}
+		}
+		if path := os.Getenv(s.name + "_FILE"); path != "" && s.secret {
+			if err := setFromFile(s, path); err != nil {
+				problems = append(problems, fmt.Sprintf("%s_FILE: %v", s.name, err))
+			}
+		}
+	}
+	return problems
+}
+
+// setFromFile читает секрет из файла; завершающий перевод строки отбрасывается
+func setFromFile(s setting, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("не удалось прочитать секрет: %w", err)
+	}
+	return s.value.set(strings.TrimRight(string(data), "\r\n"))
+}
+
+// Print выводит итоговую конфигурацию в формате файла YAML; значения секретов скрыты
+func (c *Config) Print(w io.Writer) error {
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	for _, s := range c.settings() {
+		value := s.value.get()
+		if s.secret && value != "" {
+			value = "[скрыто]"
+		}
+		doc.Content = append(doc.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: fileKey(s.name)},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: value, Style: yaml.DoubleQuotedStyle},
+		)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
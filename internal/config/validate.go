@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+)
+
+// minProductionSecretLength - минимальная длина JWT_SECRET в production
+const minProductionSecretLength = 32
+
+// validate проверяет значения параметров и возвращает все найденные проблемы
+func (c *Config) validate() []string {
+	problems := []string{}
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.ServerAddress != "", "SERVER_ADDRESS: значение обязательно")
+	check(c.MetricsAddress != "", "METRICS_ADDRESS: значение обязательно (off - не отдавать метрики)")
+	check(c.MetricsAddress != c.ServerAddress, "METRICS_ADDRESS: метрики нельзя отдавать на адресе API %s", c.ServerAddress)
+	check(c.DatabaseURL != "", "DATABASE_URL: значение обязательно")
+	check(c.RedisURL != "", "REDIS_URL: значение обязательно")
+	check(oneOf(c.LogLevel, "debug", "info", "warn", "error"), "LOG_LEVEL: ожидается debug, info, warn или error, получено %q", c.LogLevel)
+	check(c.LogSampleRate >= 0 && c.LogSampleRate <= 1, "LOG_SAMPLE_RATE: ожидается число от 0 до 1, получено %v", c.LogSampleRate)
+	check(c.HealthCheckTimeout > 0, "HEALTH_CHECK_TIMEOUT: должно быть больше нуля")
+	check(c.ShutdownDelay >= 0, "SHUTDOWN_DELAY: не может быть отрицательным")
+
+	check(c.DBMaxOpenConns > 0, "DB_MAX_OPEN_CONNS: должно быть больше нуля")
+	check(c.DBMaxIdleConns >= 0, "DB_MAX_IDLE_CONNS: не может быть отрицательным")
+	check(c.DBMaxIdleConns <= c.DBMaxOpenConns, "DB_MAX_IDLE_CONNS: не может превышать DB_MAX_OPEN_CONNS (%d)", c.DBMaxOpenConns)
+	check(c.DBMaxIdleTime > 0, "DB_MAX_IDLE_TIME: должно быть больше нуля")
+
+	check(c.JWTSecret != "", "JWT_SECRET: значение обязательно (или укажите JWT_SECRET_FILE)")
+	if c.Environment == "production" && c.JWTSecret != "" {
+		check(len(c.JWTSecret) >= minProductionSecretLength, "JWT_SECRET: в production нужно не меньше %d символов", minProductionSecretLength)
+	}
+	check(c.JWTAccessExpiryMin > 0, "JWT_ACCESS_EXPIRY_MIN: должно быть больше нуля")
+	check(c.JWTRefreshExpiryDays > 0, "JWT_REFRESH_EXPIRY_DAYS: должно быть больше нуля")
+
+	check(oneOf(c.StorageDriver, "local", "s3"), "STORAGE_DRIVER: ожидается local или s3, получено %q", c.StorageDriver)
+	if c.StorageDriver == "s3" {
+		check(c.S3Endpoint != "", "S3_ENDPOINT: значение обязательно для STORAGE_DRIVER=s3")
+		check(c.S3Bucket != "", "S3_BUCKET: значение обязательно для STORAGE_DRIVER=s3")
+	}
+	check(c.AttachmentMaxSizeMB > 0, "ATTACHMENT_MAX_SIZE_MB: должно быть больше нуля")
+	check(len(c.AttachmentAllowedTypes) > 0, "ATTACHMENT_ALLOWED_TYPES: нужен хотя бы один MIME-тип")
+	check(c.AttachmentLinkTTLMin > 0, "ATTACHMENT_LINK_TTL_MIN: должно быть больше нуля")
+
+	check(c.TaskMaxDepth > 0, "TASK_MAX_DEPTH: должно быть больше нуля")
+	check(oneOf(c.TaskBlockedTransition, "warn", "fail"), "TASK_BLOCKED_TRANSITION: ожидается warn или fail, получено %q", c.TaskBlockedTransition)
+	check(c.RecurrenceCheckInterval > 0, "RECURRENCE_CHECK_INTERVAL: должно быть больше нуля")
+	statuses := make([]string, 0, len(c.BoardWIPLimits))
+	for status := range c.BoardWIPLimits {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		check(domain.TaskStatus(status).IsValid(), "BOARD_WIP_LIMITS: неизвестный статус %q", status)
+		check(c.BoardWIPLimits[status] > 0, "BOARD_WIP_LIMITS: лимит колонки %s должен быть больше нуля", status)
+	}
+
+	check(oneOf(c.TracingExporter, "none", "stdout", "otlp"), "TRACING_EXPORTER: ожидается none, stdout или otlp, получено %q", c.TracingExporter)
+	check(c.TracingSampleRatio >= 0 && c.TracingSampleRatio <= 1, "TRACING_SAMPLE_RATIO: ожидается число от 0 до 1, получено %v", c.TracingSampleRatio)
+
+	return problems
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,327 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/database/migrations"
+	"github.com/dmitry/taskmanager/pkg/logger"
+)
+
+// migrationLockID - ключ advisory-блокировки: одновременно миграции применяет одна реплика
+const migrationLockID = 720_415_393_113
+
+// Migration - версия схемы из пары файлов NNN_name.up.sql / NNN_name.down.sql
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus - миграция и время её применения; AppliedAt == nil - не применена
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrator применяет встроенные миграции и ведёт их учёт в таблице schema_migrations.
+// Каждая миграция выполняется в отдельной транзакции вместе с записью о версии.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	logger     *logger.Logger
+}
+
+func NewMigrator(db *sql.DB, log *logger.Logger) (*Migrator, error) {
+	list, err := loadMigrations(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: list,
+		logger:     log,
+	}, nil
+}
+
+// Latest возвращает номер последней известной миграции
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up применяет все неприменённые миграции и возвращает их число
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	return m.To(ctx, m.Latest())
+}
+
+// Down откатывает steps последних применённых миграций и возвращает их число
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	if steps <= 0 {
+		return 0, fmt.Errorf("число откатываемых миграций должно быть больше 0")
+	}
+
+	count := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration, false); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// To приводит схему к версии version: применяет миграции до неё включительно и откатывает
+// более поздние. Версия 0 откатывает все миграции. Возвращает число выполненных шагов.
+func (m *Migrator) To(ctx context.Context, version int) (int, error) {
+	if version < 0 || version > m.Latest() {
+		return 0, fmt.Errorf("неизвестная версия схемы %d, последняя - %d", version, m.Latest())
+	}
+
+	count := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; ok && migration.Version > version {
+				if err := m.apply(ctx, conn, migration, false); err != nil {
+					return err
+				}
+				count++
+			}
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; !ok && migration.Version <= version {
+				if err := m.apply(ctx, conn, migration, true); err != nil {
+					return err
+				}
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Force отмечает миграции до version включительно применёнными, а более поздние - нет,
+// не выполняя SQL. Нужен для базы, схема которой создана вручную до появления учёта версий.
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	if version < 0 || version > m.Latest() {
+		return fmt.Errorf("неизвестная версия схемы %d, последняя - %d", version, m.Latest())
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("не удалось начать транзакцию: %w", err)
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version > $1`, version); err != nil {
+			return fmt.Errorf("не удалось обновить версии схемы: %w", err)
+		}
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO schema_migrations (version, name) VALUES ($1, $2)
+				ON CONFLICT (version) DO NOTHING
+			`, migration.Version, migration.Name)
+			if err != nil {
+				return fmt.Errorf("не удалось обновить версии схемы: %w", err)
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("не удалось зафиксировать транзакцию: %w", err)
+		}
+
+		m.logger.Info("Версия схемы установлена без выполнения миграций", "version", version)
+		return nil
+	})
+}
+
+// Status возвращает все известные миграции с отметкой о применении
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	statuses := make([]MigrationStatus, len(m.migrations))
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i, migration := range m.migrations {
+			statuses[i] = MigrationStatus{Migration: migration}
+			if appliedAt, ok := applied[migration.Version]; ok {
+				statuses[i].AppliedAt = &appliedAt
+			}
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// withLock выполняет fn на одном соединении под advisory-блокировкой, предварительно
+// создав таблицу версий
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось получить соединение с базой данных: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("не удалось получить блокировку миграций: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу версий схемы: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// apply выполняет миграцию вверх (up) или вниз в одной транзакции с записью о версии
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration, up bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	script, direction := migration.Down, "down"
+	if up {
+		script, direction = migration.Up, "up"
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("миграция %03d_%s (%s) завершилась ошибкой: %w", migration.Version, migration.Name, direction, err)
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, migration.Version, migration.Name)
+	} else {
+		_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("не удалось обновить версию схемы: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("не удалось зафиксировать миграцию %03d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	m.logger.Info("Миграция выполнена", "version", migration.Version, "name", migration.Name, "direction", direction)
+	return nil
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить версии схемы: %w", err)
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("не удалось получить версии схемы: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// loadMigrations читает пары файлов миграций и упорядочивает их по версии
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, file := range names {
+		base, up := strings.CutSuffix(file, ".up.sql")
+		if !up {
+			var down bool
+			if base, down = strings.CutSuffix(file, ".down.sql"); !down {
+				return nil, fmt.Errorf("файл миграции %s должен оканчиваться на .up.sql или .down.sql", file)
+			}
+		}
+
+		number, name, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(number)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("имя файла миграции %s должно начинаться с номера версии", file)
+		}
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		migration := byVersion[version]
+		if migration == nil {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		} else if migration.Name != name {
+			return nil, fmt.Errorf("у версии %d несколько миграций: %s и %s", version, migration.Name, name)
+		}
+
+		if up {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("у миграции %03d_%s нет файла up или down", migration.Version, migration.Name)
+		}
+		list = append(list, *migration)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+
+	return list, nil
+}
@@ -0,0 +1,21 @@
+-- Attachments table for files attached to tasks and task messages
+CREATE TABLE attachments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    message_id UUID REFERENCES task_messages(id) ON DELETE CASCADE,
+    uploaded_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes >= 0),
+    checksum CHAR(64) NOT NULL,
+    storage_key VARCHAR(500) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_attachments_task ON attachments(task_id);
+CREATE INDEX idx_attachments_message ON attachments(message_id);
+CREATE INDEX idx_attachments_deleted ON attachments(deleted_at);
+
+-- Blobs are deduplicated by content checksum, several attachments may share one object
+CREATE INDEX idx_attachments_checksum ON attachments(checksum) WHERE deleted_at IS NULL;
@@ -0,0 +1,6 @@
+-- Remove full-text search vectors
+DROP INDEX IF EXISTS idx_task_messages_search_vector;
+ALTER TABLE task_messages DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS idx_tasks_search_vector;
+ALTER TABLE tasks DROP COLUMN IF EXISTS search_vector;
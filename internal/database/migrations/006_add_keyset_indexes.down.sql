@@ -0,0 +1,3 @@
+-- Drop keyset pagination indexes
+DROP INDEX IF EXISTS idx_employees_keyset_created;
+DROP INDEX IF EXISTS idx_tasks_keyset_created;
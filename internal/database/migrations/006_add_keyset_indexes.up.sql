@@ -0,0 +1,3 @@
+-- Indexes supporting keyset (cursor) pagination in the default "newest first" order
+CREATE INDEX idx_tasks_keyset_created ON tasks(created_at DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX idx_employees_keyset_created ON employees(created_at DESC, id DESC) WHERE deleted_at IS NULL;
@@ -0,0 +1,4 @@
+-- Drop saved task views
+DROP TABLE IF EXISTS task_view_defaults;
+DROP TRIGGER IF EXISTS update_task_views_updated_at ON task_views;
+DROP TABLE IF EXISTS task_views;
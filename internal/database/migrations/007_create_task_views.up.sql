@@ -0,0 +1,22 @@
+-- Saved task views: named task list filters (query string of GET /tasks parameters)
+CREATE TABLE task_views (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    query TEXT NOT NULL DEFAULT '',
+    shared_department VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_task_views_owner ON task_views(owner_id);
+CREATE INDEX idx_task_views_shared_department ON task_views(shared_department) WHERE shared_department IS NOT NULL;
+
+CREATE TRIGGER update_task_views_updated_at BEFORE UPDATE ON task_views
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Default (pinned) view per employee; may point to an own or a shared view
+CREATE TABLE task_view_defaults (
+    employee_id UUID PRIMARY KEY REFERENCES employees(id) ON DELETE CASCADE,
+    view_id UUID NOT NULL REFERENCES task_views(id) ON DELETE CASCADE
+);
@@ -0,0 +1,9 @@
+-- Drop projects
+DROP INDEX IF EXISTS idx_tasks_project;
+DROP INDEX IF EXISTS idx_tasks_key;
+ALTER TABLE tasks DROP COLUMN IF EXISTS key;
+ALTER TABLE tasks DROP COLUMN IF EXISTS project_id;
+
+DROP TABLE IF EXISTS project_members;
+DROP TRIGGER IF EXISTS update_projects_updated_at ON projects;
+DROP TABLE IF EXISTS projects;
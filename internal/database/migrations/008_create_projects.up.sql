@@ -0,0 +1,35 @@
+-- Projects group tasks and define who may see and edit them
+CREATE TABLE projects (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    key VARCHAR(10) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    owner_id UUID NOT NULL REFERENCES employees(id),
+    task_counter INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Keys stay reserved after soft delete: issued task keys must never repeat
+CREATE UNIQUE INDEX idx_projects_key ON projects(key);
+CREATE INDEX idx_projects_owner ON projects(owner_id);
+
+CREATE TRIGGER update_projects_updated_at BEFORE UPDATE ON projects
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE project_members (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, employee_id)
+);
+
+CREATE INDEX idx_project_members_employee ON project_members(employee_id);
+
+-- Tasks optionally belong to a project and then get a human-readable key like CORE-123
+ALTER TABLE tasks ADD COLUMN project_id UUID REFERENCES projects(id);
+ALTER TABLE tasks ADD COLUMN key VARCHAR(32);
+
+CREATE UNIQUE INDEX idx_tasks_key ON tasks(key) WHERE key IS NOT NULL;
+CREATE INDEX idx_tasks_project ON tasks(project_id) WHERE deleted_at IS NULL;
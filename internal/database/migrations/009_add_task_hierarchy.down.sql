@@ -0,0 +1,3 @@
+-- Drop task hierarchy
+DROP INDEX IF EXISTS idx_tasks_parent;
+ALTER TABLE tasks DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,4 @@
+-- Parent/child relation between tasks (subtasks)
+ALTER TABLE tasks ADD COLUMN parent_id UUID REFERENCES tasks(id);
+
+CREATE INDEX idx_tasks_parent ON tasks(parent_id) WHERE deleted_at IS NULL;
@@ -0,0 +1,3 @@
+-- Drop task links
+DROP TABLE IF EXISTS task_links;
+DROP TYPE IF EXISTS task_link_type;
@@ -0,0 +1,20 @@
+-- Typed links between tasks. For "blocks" the source task blocks the target task;
+-- "duplicates" means the source task duplicates the target task.
+CREATE TYPE task_link_type AS ENUM (
+    'blocks',
+    'relates_to',
+    'duplicates'
+);
+
+CREATE TABLE task_links (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source_task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    target_task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    type task_link_type NOT NULL,
+    created_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (source_task_id, target_task_id, type),
+    CHECK (source_task_id <> target_task_id)
+);
+
+CREATE INDEX idx_task_links_target ON task_links(target_task_id, type);
@@ -0,0 +1,4 @@
+-- Drop labels
+DROP TABLE IF EXISTS task_labels;
+DROP TRIGGER IF EXISTS update_labels_updated_at ON labels;
+DROP TABLE IF EXISTS labels;
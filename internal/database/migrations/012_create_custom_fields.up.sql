@@ -0,0 +1,41 @@
+-- Custom field definitions. A field without project_id applies to all tasks,
+-- otherwise only to tasks of that project. Keys are unique across all fields
+-- so that list filters (cf.<key>=...) are unambiguous.
+CREATE TYPE custom_field_type AS ENUM (
+    'text',
+    'number',
+    'date',
+    'single_select',
+    'multi_select',
+    'employee'
+);
+
+CREATE TABLE custom_fields (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+    key VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    type custom_field_type NOT NULL,
+    options JSONB NOT NULL DEFAULT '[]',
+    created_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_custom_fields_key ON custom_fields(key);
+CREATE INDEX idx_custom_fields_project ON custom_fields(project_id);
+
+CREATE TRIGGER update_custom_fields_updated_at BEFORE UPDATE ON custom_fields
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Field values are stored as JSON: a string for text, date (YYYY-MM-DD), single_select
+-- and employee (UUID), a number for number and an array of strings for multi_select
+CREATE TABLE task_custom_values (
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    field_id UUID NOT NULL REFERENCES custom_fields(id) ON DELETE CASCADE,
+    value JSONB NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_id, field_id)
+);
+
+CREATE INDEX idx_task_custom_values_field ON task_custom_values(field_id);
@@ -0,0 +1,3 @@
+-- Drop board ranks
+DROP INDEX IF EXISTS idx_tasks_board;
+ALTER TABLE tasks DROP COLUMN IF EXISTS board_rank;
@@ -0,0 +1,15 @@
+-- Manual position of a task within its board column (status). Ranks are base-36
+-- strings compared byte-wise, so a task can be placed between two others without
+-- renumbering the column.
+ALTER TABLE tasks ADD COLUMN board_rank TEXT COLLATE "C";
+
+-- Existing tasks keep their creation order within each column
+UPDATE tasks t
+SET board_rank = r.rank
+FROM (
+    SELECT id, lpad(to_hex(row_number() OVER (PARTITION BY status ORDER BY created_at, id)), 8, '0') AS rank
+    FROM tasks
+) r
+WHERE t.id = r.id;
+
+CREATE INDEX idx_tasks_board ON tasks(status, board_rank) WHERE deleted_at IS NULL;
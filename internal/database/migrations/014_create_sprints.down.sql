@@ -0,0 +1,7 @@
+-- Drop sprints
+DROP TABLE IF EXISTS sprint_tasks;
+DROP INDEX IF EXISTS idx_tasks_sprint;
+ALTER TABLE tasks DROP COLUMN IF EXISTS sprint_id;
+DROP TRIGGER IF EXISTS update_sprints_updated_at ON sprints;
+DROP TABLE IF EXISTS sprints;
+DROP TYPE IF EXISTS sprint_state;
@@ -0,0 +1,43 @@
+-- Sprints are time-boxed iterations inside a project
+CREATE TYPE sprint_state AS ENUM ('planned', 'active', 'closed');
+
+CREATE TABLE sprints (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    goal TEXT,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    state sprint_state NOT NULL DEFAULT 'planned',
+    started_at TIMESTAMP WITH TIME ZONE,
+    closed_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX idx_sprints_project ON sprints(project_id, start_date);
+-- A project runs at most one sprint at a time
+CREATE UNIQUE INDEX idx_sprints_active ON sprints(project_id) WHERE state = 'active';
+
+CREATE TRIGGER update_sprints_updated_at BEFORE UPDATE ON sprints
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Current sprint of a task; NULL means the project backlog
+ALTER TABLE tasks ADD COLUMN sprint_id UUID REFERENCES sprints(id) ON DELETE SET NULL;
+CREATE INDEX idx_tasks_sprint ON tasks(sprint_id) WHERE deleted_at IS NULL;
+
+-- Every task that has been part of a sprint, for the sprint report.
+-- committed marks tasks that were in the sprint when it started;
+-- removed_at is set when a task leaves the sprint (including carry-over on close)
+CREATE TABLE sprint_tasks (
+    sprint_id UUID NOT NULL REFERENCES sprints(id) ON DELETE CASCADE,
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    committed BOOLEAN NOT NULL DEFAULT false,
+    added_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    removed_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (sprint_id, task_id)
+);
+
+CREATE INDEX idx_sprint_tasks_task ON sprint_tasks(task_id);
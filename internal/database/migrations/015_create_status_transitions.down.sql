@@ -0,0 +1,2 @@
+-- Drop status transitions
+DROP TABLE IF EXISTS task_status_transitions;
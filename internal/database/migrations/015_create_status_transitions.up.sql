@@ -0,0 +1,42 @@
+-- Status transitions of tasks, the source for burndown, cumulative flow and cycle time
+CREATE TABLE task_status_transitions (
+    id BIGSERIAL PRIMARY KEY,
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    from_status task_status,
+    to_status task_status NOT NULL,
+    changed_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_task_status_transitions_task ON task_status_transitions(task_id, changed_at);
+CREATE INDEX idx_task_status_transitions_to ON task_status_transitions(to_status, changed_at);
+
+-- Backfill: creation of every task...
+INSERT INTO task_status_transitions (task_id, from_status, to_status, changed_by, changed_at)
+SELECT id, NULL, 'new', created_by, created_at FROM tasks;
+
+-- ...status changes previously kept only as system messages...
+INSERT INTO task_status_transitions (task_id, from_status, to_status, changed_at)
+SELECT task_id, parts[1]::task_status, parts[2]::task_status, created_at
+FROM (
+    SELECT task_id, created_at,
+        regexp_match(content, '^Статус задачи изменён с ''([a-z_]+)'' на ''([a-z_]+)''$') AS parts
+    FROM task_messages
+    WHERE is_system_message = true
+) m
+WHERE parts IS NOT NULL
+    AND parts[1] IN ('new', 'in_progress', 'code_review', 'testing', 'returned_with_errors', 'closed')
+    AND parts[2] IN ('new', 'in_progress', 'code_review', 'testing', 'returned_with_errors', 'closed');
+
+-- ...and the current status where the recovered history does not end with it
+INSERT INTO task_status_transitions (task_id, from_status, to_status, changed_at)
+SELECT t.id, last.to_status, t.status, GREATEST(t.updated_at, last.changed_at)
+FROM tasks t
+CROSS JOIN LATERAL (
+    SELECT tr.to_status, tr.changed_at
+    FROM task_status_transitions tr
+    WHERE tr.task_id = t.id
+    ORDER BY tr.changed_at DESC, tr.id DESC
+    LIMIT 1
+) last
+WHERE last.to_status <> t.status;
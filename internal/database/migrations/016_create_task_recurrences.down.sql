@@ -0,0 +1,4 @@
+-- Drop task recurrences
+DROP TABLE IF EXISTS task_recurrence_occurrences;
+DROP TRIGGER IF EXISTS update_task_recurrences_updated_at ON task_recurrences;
+DROP TABLE IF EXISTS task_recurrences;
@@ -0,0 +1,32 @@
+-- Recurrence schedules: a template task is copied on every occurrence of its rule
+CREATE TABLE task_recurrences (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    template_task_id UUID NOT NULL UNIQUE REFERENCES tasks(id) ON DELETE CASCADE,
+    rule TEXT NOT NULL,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    -- NULL once the rule has no more occurrences
+    next_run_at TIMESTAMP WITH TIME ZONE,
+    occurrences INTEGER NOT NULL DEFAULT 0,
+    -- Consecutive failed runs; the schedule is skipped until retry_at
+    failures INTEGER NOT NULL DEFAULT 0,
+    retry_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_task_recurrences_next_run ON task_recurrences(next_run_at) WHERE next_run_at IS NOT NULL;
+
+CREATE TRIGGER update_task_recurrences_updated_at BEFORE UPDATE ON task_recurrences
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Materialized occurrences. The primary key makes creation idempotent:
+-- an occurrence is copied at most once even if several replicas run the scheduler
+CREATE TABLE task_recurrence_occurrences (
+    recurrence_id UUID NOT NULL REFERENCES task_recurrences(id) ON DELETE CASCADE,
+    occurrence_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    task_id UUID REFERENCES tasks(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (recurrence_id, occurrence_at)
+);
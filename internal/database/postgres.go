@@ -1,11 +1,13 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"time"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 
 	"github.com/dmitry/taskmanager/internal/config"
 	"github.com/dmitry/taskmanager/pkg/logger"
@@ -17,7 +19,11 @@ type Database struct {
 }
 
 func NewPostgres(cfg *config.Config, log *logger.Logger) (*Database, error) {
-	db, err := sql.Open("postgres", cfg.DatabaseURL)
+	// Каждый запрос к базе данных становится дочерним спаном спана запроса из контекста
+	db, err := otelsql.Open("postgres", cfg.DatabaseURL,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true, OmitRows: true}),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("не удалось открыть базу данных: %w", err)
 	}
@@ -25,11 +31,7 @@ func NewPostgres(cfg *config.Config, log *logger.Logger) (*Database, error) {
 	db.SetMaxOpenConns(cfg.DBMaxOpenConns)
 	db.SetMaxIdleConns(cfg.DBMaxIdleConns)
 
-	maxIdleTime, err := time.ParseDuration(cfg.DBMaxIdleTime)
-	if err != nil {
-		maxIdleTime = 15 * time.Minute
-	}
-	db.SetConnMaxIdleTime(maxIdleTime)
+	db.SetConnMaxIdleTime(cfg.DBMaxIdleTime)
 
 	if err := db.Ping(); err != nil {
 		return nil, fmt.Errorf("не удалось подключиться к базе данных: %w", err)
@@ -48,6 +50,7 @@ func (d *Database) Close() error {
 	return d.DB.Close()
 }
 
-func (d *Database) HealthCheck() error {
-	return d.DB.Ping()
+// HealthCheck проверяет доступность базы данных
+func (d *Database) HealthCheck(ctx context.Context) error {
+	return d.DB.PingContext(ctx)
 }
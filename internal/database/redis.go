@@ -7,6 +7,7 @@ import (
 
 	"github.com/dmitry/taskmanager/internal/config"
 	"github.com/dmitry/taskmanager/pkg/logger"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -22,6 +23,9 @@ func NewRedis(cfg *config.Config, log *logger.Logger) (*RedisClient, error) {
 	}
 
 	client := redis.NewClient(opt)
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		return nil, fmt.Errorf("не удалось подключить трассировку Redis: %w", err)
+	}
 
 	// Проверка подключения
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -46,6 +50,11 @@ func (r *RedisClient) Close() error {
 	return nil
 }
 
+// HealthCheck проверяет доступность Redis
+func (r *RedisClient) HealthCheck(ctx context.Context) error {
+	return r.Client.Ping(ctx).Err()
+}
+
 // Set сохраняет значение в Redis с TTL
 func (r *RedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
 	return r.Client.Set(ctx, key, value, expiration).Err()
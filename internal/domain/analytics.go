@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatusTransition - смена статуса задачи. FromStatus == nil у записи о создании задачи.
+type StatusTransition struct {
+	ID         int64       `json:"id"`
+	TaskID     uuid.UUID   `json:"task_id"`
+	FromStatus *TaskStatus `json:"from_status,omitempty"`
+	ToStatus   TaskStatus  `json:"to_status"`
+	ChangedBy  *uuid.UUID  `json:"changed_by,omitempty"`
+	ChangedAt  time.Time   `json:"changed_at"`
+}
+
+func NewStatusTransition(taskID uuid.UUID, from *TaskStatus, to TaskStatus, changedBy uuid.UUID) *StatusTransition {
+	return &StatusTransition{
+		TaskID:     taskID,
+		FromStatus: from,
+		ToStatus:   to,
+		ChangedBy:  &changedBy,
+		ChangedAt:  time.Now(),
+	}
+}
+
+// BurndownPoint - состояние объёма работ на конец дня: Scope - задачи в работе периода
+// (для спринта - его состав на этот день), Completed - из них закрытые, Ideal - идеальный
+// остаток при равномерном закрытии задач до конца периода
+type BurndownPoint struct {
+	Date      time.Time `json:"date"`
+	Scope     int       `json:"scope"`
+	Completed int       `json:"completed"`
+	Remaining int       `json:"remaining"`
+	Ideal     float64   `json:"ideal"`
+}
+
+// FlowPoint - число задач в каждом статусе на конец дня (точка диаграммы накопленного потока)
+type FlowPoint struct {
+	Date   time.Time          `json:"date"`
+	Counts map[TaskStatus]int `json:"counts"`
+}
+
+// Percentiles - перцентили длительности в часах
+type Percentiles struct {
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P90 float64 `json:"p90"`
+	P95 float64 `json:"p95"`
+}
+
+// FlowTimeStats - время выполнения задач команды (отдела исполнителя). LeadTime - от создания
+// до последнего закрытия, CycleTime - от первого перехода в работу до последнего закрытия.
+// CycleTime == nil, если ни одна задача не проходила через in_progress.
+type FlowTimeStats struct {
+	Team      string       `json:"team"`
+	Tasks     int          `json:"tasks"`
+	LeadTime  Percentiles  `json:"lead_time"`
+	CycleTime *Percentiles `json:"cycle_time,omitempty"`
+}
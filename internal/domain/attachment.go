@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Attachment struct {
+	ID          uuid.UUID  `json:"id"`
+	TaskID      uuid.UUID  `json:"task_id"`
+	MessageID   *uuid.UUID `json:"message_id,omitempty"`
+	UploadedBy  *uuid.UUID `json:"uploaded_by,omitempty"`
+	FileName    string     `json:"file_name"`
+	ContentType string     `json:"content_type"`
+	SizeBytes   int64      `json:"size_bytes"`
+	Checksum    string     `json:"checksum"`
+	StorageKey  string     `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+func NewAttachment(taskID uuid.UUID, messageID, uploadedBy *uuid.UUID, fileName, contentType string, sizeBytes int64, checksum string) *Attachment {
+	return &Attachment{
+		ID:          uuid.New(),
+		TaskID:      taskID,
+		MessageID:   messageID,
+		UploadedBy:  uploadedBy,
+		FileName:    fileName,
+		ContentType: contentType,
+		SizeBytes:   sizeBytes,
+		Checksum:    checksum,
+		StorageKey:  AttachmentStorageKey(checksum),
+		CreatedAt:   time.Now(),
+	}
+}
+
+// AttachmentStorageKey строит ключ объекта по контрольной сумме содержимого,
+// поэтому одинаковые файлы хранятся в единственном экземпляре
+func AttachmentStorageKey(checksum string) string {
+	return "blobs/" + checksum[:2] + "/" + checksum[2:4] + "/" + checksum
+}
@@ -0,0 +1,87 @@
+package domain
+
+import "strings"
+
+// BoardStatuses - колонки доски в порядке отображения
+var BoardStatuses = []TaskStatus{
+	TaskStatusNew,
+	TaskStatusInProgress,
+	TaskStatusCodeReview,
+	TaskStatusTesting,
+	TaskStatusReturnedWithErrors,
+	TaskStatusClosed,
+}
+
+// BoardColumn - колонка доски: задачи одного статуса в порядке ранга
+type BoardColumn struct {
+	Status TaskStatus
+	// WIPLimit - ограничение числа задач в колонке, 0 - без ограничения
+	WIPLimit int
+	// Total - число задач в колонке с учётом фильтра (Tasks может быть усечён)
+	Total int
+	Tasks []*Task
+}
+
+// rankAlphabet - цифры ранга в порядке возрастания (побайтовое сравнение строк)
+const rankAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// initialRank - ранг первой задачи пустой колонки; запас разрядов позволяет
+// долго добавлять задачи в конец, не удлиняя ранг
+const initialRank = "i0000001"
+
+func rankDigit(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'z':
+		return int(c-'a') + 10
+	}
+	return 0
+}
+
+// RankBetween возвращает ранг строго между prev и next; пустой prev означает начало
+// колонки, пустой next - её конец. Ранг никогда не заканчивается на "0", поэтому
+// между любыми двумя рангами всегда найдётся место.
+func RankBetween(prev, next string) string {
+	if next == "" {
+		if prev == "" {
+			return initialRank
+		}
+		return rankAfter(prev)
+	}
+
+	rank := make([]byte, 0, len(prev)+1)
+	for i := 0; ; i++ {
+		lo := 0
+		if i < len(prev) {
+			lo = rankDigit(prev[i])
+		}
+		hi := len(rankAlphabet)
+		if next != "" && i < len(next) {
+			hi = rankDigit(next[i])
+		}
+
+		if lo == hi {
+			rank = append(rank, rankAlphabet[lo])
+			continue
+		}
+		if hi-lo > 1 {
+			return string(append(rank, rankAlphabet[(lo+hi)/2]))
+		}
+
+		// Соседние цифры: берём цифру prev, дальше ограничение сверху снимается
+		rank = append(rank, rankAlphabet[lo])
+		next = ""
+	}
+}
+
+// rankAfter увеличивает ранг на единицу младшего разряда (при переносе младшие разряды
+// становятся "1", а не "0"), так что при добавлении задач в конец колонки длина ранга не растёт
+func rankAfter(prev string) string {
+	for i := len(prev) - 1; i >= 0; i-- {
+		if d := rankDigit(prev[i]); d < len(rankAlphabet)-1 {
+			return prev[:i] + string(rankAlphabet[d+1]) + strings.Repeat("1", len(prev)-i-1)
+		}
+	}
+	return prev + string(rankAlphabet[len(rankAlphabet)/2])
+}
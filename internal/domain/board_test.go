@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		name       string
+		prev, next string
+		want       string
+	}{
+		{"пустая колонка", "", "", initialRank},
+		{"в конец", "i0000001", "", "i0000002"},
+		{"в конец с переносом разряда", "i000000z", "", "i0000011"},
+		{"в конец после максимального ранга", "zzzzzzzz", "", "zzzzzzzzi"},
+		{"в начало", "", "i0000001", "9"},
+		{"в середину", "i0000001", "i0000003", "i0000002"},
+		{"соседи отличаются последним символом", "i0000001", "i0000002", "i0000001i"},
+		{"короткие соседи", "a1", "a2", "a1i"},
+		{"prev - префикс next", "a", "a1", "a0i"},
+		{"prev - префикс next с нулями", "a", "a01", "a00i"},
+		{"перед первым рангом миграции", "", "00000001", "00000000i"},
+		{"ранги миграции подряд", "00000001", "00000002", "00000001i"},
+		{"ранги миграции через разряд", "0000000f", "00000010", "0000000p"},
+		{"ранги миграции через два разряда", "000000ff", "00000100", "000000p"},
+		{"перед рангом миграции на 0", "", "00000010", "0000000i"},
+		{"после ранга миграции на 0", "00000100", "", "00000101"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RankBetween(tt.prev, tt.next)
+			if got != tt.want {
+				t.Errorf("RankBetween(%q, %q) = %q, ожидалось %q", tt.prev, tt.next, got, tt.want)
+			}
+			if got <= tt.prev || (tt.next != "" && got >= tt.next) {
+				t.Errorf("ранг %q не между %q и %q", got, tt.prev, tt.next)
+			}
+			if strings.HasSuffix(got, "0") {
+				t.Errorf("ранг %q заканчивается на 0", got)
+			}
+		})
+	}
+}
+
+// Многократная вставка между одной и той же парой: сначала каждый раз перед последней
+// вставленной задачей, затем каждый раз после неё
+func TestRankBetweenRepeated(t *testing.T) {
+	pairs := [][2]string{
+		{"i0000001", "i0000002"},
+		{"0000000f", "00000010"},
+		{"", "00000001"},
+		{"00000001", ""},
+	}
+
+	for _, pair := range pairs {
+		t.Run(pair[0]+"-"+pair[1], func(t *testing.T) {
+			prev, next := pair[0], pair[1]
+			for i := 0; i < 200; i++ {
+				rank := RankBetween(prev, next)
+				if rank <= prev || (next != "" && rank >= next) {
+					t.Fatalf("вставка %d: RankBetween(%q, %q) = %q", i, prev, next, rank)
+				}
+				next = rank
+			}
+
+			prev, next = pair[0], pair[1]
+			for i := 0; i < 200; i++ {
+				rank := RankBetween(prev, next)
+				if rank <= prev || (next != "" && rank >= next) {
+					t.Fatalf("вставка %d: RankBetween(%q, %q) = %q", i, prev, next, rank)
+				}
+				prev = rank
+			}
+		})
+	}
+}
+
+// Колонка с рангами миграции 013 (lpad(to_hex(n), 8, '0')): вставки в начало, конец
+// и между каждой парой соседей сохраняют строгий порядок
+func TestRankBetweenBackfilledColumn(t *testing.T) {
+	column := []string{""}
+	for n := 1; n <= 300; n++ {
+		column = append(column, fmt.Sprintf("%08x", n))
+	}
+	column = append(column, "")
+
+	for i := 0; i+1 < len(column); i++ {
+		prev, next := column[i], column[i+1]
+		rank := RankBetween(prev, next)
+		if rank <= prev || (next != "" && rank >= next) {
+			t.Errorf("RankBetween(%q, %q) = %q", prev, next, rank)
+		}
+	}
+}
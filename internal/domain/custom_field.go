@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+type CustomFieldType string
+
+const (
+	CustomFieldText         CustomFieldType = "text"
+	CustomFieldNumber       CustomFieldType = "number"
+	CustomFieldDate         CustomFieldType = "date"
+	CustomFieldSingleSelect CustomFieldType = "single_select"
+	CustomFieldMultiSelect  CustomFieldType = "multi_select"
+	CustomFieldEmployee     CustomFieldType = "employee"
+)
+
+// MaxCustomTextLength - максимальная длина значения текстового поля в символах
+const MaxCustomTextLength = 1000
+
+func (t CustomFieldType) IsValid() bool {
+	switch t {
+	case CustomFieldText, CustomFieldNumber, CustomFieldDate,
+		CustomFieldSingleSelect, CustomFieldMultiSelect, CustomFieldEmployee:
+		return true
+	}
+	return false
+}
+
+// HasOptions сообщает, выбирается ли значение поля из списка вариантов
+func (t CustomFieldType) HasOptions() bool {
+	return t == CustomFieldSingleSelect || t == CustomFieldMultiSelect
+}
+
+// IsOrdered сообщает, можно ли фильтровать поле по диапазону
+func (t CustomFieldType) IsOrdered() bool {
+	return t == CustomFieldNumber || t == CustomFieldDate
+}
+
+// IsSortable сообщает, можно ли сортировать задачи по полю
+func (t CustomFieldType) IsSortable() bool {
+	return t != CustomFieldMultiSelect
+}
+
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// IsValidCustomFieldKey проверяет ключ поля: строчная латинская буква,
+// затем до 49 строчных букв, цифр или подчёркиваний (например, story_points)
+func IsValidCustomFieldKey(key string) bool {
+	return customFieldKeyPattern.MatchString(key)
+}
+
+type CustomField struct {
+	ID uuid.UUID `json:"id"`
+	// ProjectID - проект, к задачам которого относится поле; nil - поле для всех задач
+	ProjectID *uuid.UUID      `json:"project_id,omitempty"`
+	Key       string          `json:"key"`
+	Name      string          `json:"name"`
+	Type      CustomFieldType `json:"type"`
+	Options   []string        `json:"options"`
+	CreatedBy *uuid.UUID      `json:"created_by,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+func NewCustomField(projectID *uuid.UUID, key, name string, fieldType CustomFieldType, options []string, createdBy uuid.UUID) *CustomField {
+	now := time.Now()
+	return &CustomField{
+		ID:        uuid.New(),
+		ProjectID: projectID,
+		Key:       key,
+		Name:      name,
+		Type:      fieldType,
+		Options:   options,
+		CreatedBy: &createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// AppliesTo сообщает, можно ли задать поле задаче
+func (f *CustomField) AppliesTo(task *Task) bool {
+	if f.ProjectID == nil {
+		return true
+	}
+	return task.ProjectID != nil && *task.ProjectID == *f.ProjectID
+}
+
+func (f *CustomField) hasOption(value string) bool {
+	for _, option := range f.Options {
+		if option == value {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeValue проверяет значение, пришедшее в JSON, и приводит его к виду,
+// в котором оно хранится: строка, число или массив строк (для multi_select)
+func (f *CustomField) NormalizeValue(raw interface{}) (interface{}, error) {
+	switch f.Type {
+	case CustomFieldNumber:
+		number, ok := raw.(float64)
+		if !ok {
+			return nil, fmt.Errorf("Поле %s должно быть числом", f.Key)
+		}
+		return number, nil
+
+	case CustomFieldMultiSelect:
+		items, ok := raw.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Поле %s должно быть массивом вариантов", f.Key)
+		}
+		values := []string{}
+		seen := map[string]bool{}
+		for _, item := range items {
+			value, ok := item.(string)
+			if !ok || !f.hasOption(value) {
+				return nil, fmt.Errorf("Недопустимый вариант поля %s: %v", f.Key, item)
+			}
+			if !seen[value] {
+				seen[value] = true
+				values = append(values, value)
+			}
+		}
+		return values, nil
+	}
+
+	value, ok := raw.(string)
+	if !ok {
+		return nil, fmt.Errorf("Поле %s должно быть строкой", f.Key)
+	}
+	return f.ParseValue(value)
+}
+
+// ParseValue разбирает строковое значение поля (из JSON или параметра запроса).
+// Для multi_select разбирается один вариант.
+func (f *CustomField) ParseValue(value string) (interface{}, error) {
+	switch f.Type {
+	case CustomFieldText:
+		if utf8.RuneCountInString(value) > MaxCustomTextLength {
+			return nil, fmt.Errorf("Значение поля %s длиннее %d символов", f.Key, MaxCustomTextLength)
+		}
+		return value, nil
+
+	case CustomFieldNumber:
+		number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, fmt.Errorf("Поле %s должно быть числом", f.Key)
+		}
+		return number, nil
+
+	case CustomFieldDate:
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, fmt.Errorf("Поле %s должно быть датой в формате ГГГГ-ММ-ДД", f.Key)
+		}
+		return date.Format("2006-01-02"), nil
+
+	case CustomFieldSingleSelect, CustomFieldMultiSelect:
+		if !f.hasOption(value) {
+			return nil, fmt.Errorf("Недопустимый вариант поля %s: %s", f.Key, value)
+		}
+		return value, nil
+
+	case CustomFieldEmployee:
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("Поле %s должно содержать UUID сотрудника", f.Key)
+		}
+		return id.String(), nil
+	}
+
+	return nil, fmt.Errorf("Неизвестный тип поля %s", f.Key)
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// NormalizeLabelColor приводит цвет к виду #rrggbb; второй результат false, если формат неверный
+func NormalizeLabelColor(color string) (string, bool) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	return color, labelColorPattern.MatchString(color)
+}
+
+type Label struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// UsageCount - число задач с меткой; заполняется только в списке меток
+	UsageCount int `json:"usage_count"`
+}
+
+func NewLabel(name, color string) *Label {
+	now := time.Now()
+	return &Label{
+		ID:        uuid.New(),
+		Name:      name,
+		Color:     color,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
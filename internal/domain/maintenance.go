@@ -0,0 +1,7 @@
+package domain
+
+// PurgeCount - число строк таблицы, удалённых при очистке
+type PurgeCount struct {
+	Table string `json:"table"`
+	Rows  int64  `json:"rows"`
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var projectKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,9}$`)
+
+// IsValidProjectKey проверяет ключ проекта: латинская заглавная буква,
+// затем от 1 до 9 заглавных букв или цифр (например, CORE)
+func IsValidProjectKey(key string) bool {
+	return projectKeyPattern.MatchString(key)
+}
+
+type Project struct {
+	ID          uuid.UUID  `json:"id"`
+	Key         string     `json:"key"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	OwnerID     uuid.UUID  `json:"owner_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+func NewProject(key, name, description string, ownerID uuid.UUID) *Project {
+	now := time.Now()
+	return &Project{
+		ID:          uuid.New(),
+		Key:         key,
+		Name:        name,
+		Description: description,
+		OwnerID:     ownerID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// TaskKey возвращает ключ задачи проекта по её порядковому номеру, например CORE-123
+func (p *Project) TaskKey(number int) string {
+	return fmt.Sprintf("%s-%d", p.Key, number)
+}
+
+type ProjectMember struct {
+	ProjectID  uuid.UUID `json:"project_id"`
+	EmployeeID uuid.UUID `json:"employee_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func NewProjectMember(projectID, employeeID uuid.UUID) *ProjectMember {
+	return &ProjectMember{
+		ProjectID:  projectID,
+		EmployeeID: employeeID,
+		CreatedAt:  time.Now(),
+	}
+}
@@ -0,0 +1,445 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	// База часовых поясов встроена, чтобы TZ-имена работали и в образе без tzdata
+	_ "time/tzdata"
+
+	"github.com/google/uuid"
+)
+
+// RecurrenceFrequency - частота повторения (FREQ в RRULE)
+type RecurrenceFrequency string
+
+const (
+	RecurrenceDaily   RecurrenceFrequency = "DAILY"
+	RecurrenceWeekly  RecurrenceFrequency = "WEEKLY"
+	RecurrenceMonthly RecurrenceFrequency = "MONTHLY"
+)
+
+// RecurrenceWeekday - день недели из BYDAY. N - порядковый номер дня в месяце
+// (1 - первый, -1 - последний) для MONTHLY; 0 - каждый такой день.
+type RecurrenceWeekday struct {
+	Weekday time.Weekday
+	N       int
+}
+
+// RecurrenceRule - подмножество RRULE (RFC 5545): FREQ=DAILY|WEEKLY|MONTHLY, INTERVAL,
+// BYDAY, UNTIL и COUNT. Неделя начинается с понедельника.
+type RecurrenceRule struct {
+	Frequency RecurrenceFrequency
+	Interval  int
+	ByDay     []RecurrenceWeekday
+	Until     *time.Time
+	Count     int
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// maxRecurrencePeriods ограничивает поиск следующего повторения: правило вроде
+// FREQ=DAILY;INTERVAL=7;BYDAY=TU при старте в понедельник не даёт ни одного повторения
+const maxRecurrencePeriods = 1000
+
+// ParseRecurrenceRule разбирает правило вида FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=10.
+// Префикс "RRULE:" допускается.
+func ParseRecurrenceRule(value string) (*RecurrenceRule, error) {
+	rule := &RecurrenceRule{Interval: 1}
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return nil, fmt.Errorf("Правило повторения не задано")
+	}
+
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ";") {
+		name, val, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		val = strings.ToUpper(strings.TrimSpace(val))
+		if !ok || val == "" {
+			return nil, fmt.Errorf("Неверная часть правила повторения: %q", part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("Часть %s правила повторения указана дважды", name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			rule.Frequency = RecurrenceFrequency(val)
+			if rule.Frequency != RecurrenceDaily && rule.Frequency != RecurrenceWeekly && rule.Frequency != RecurrenceMonthly {
+				return nil, fmt.Errorf("Поддерживаются только FREQ=DAILY, WEEKLY и MONTHLY")
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("INTERVAL должен быть целым числом больше нуля")
+			}
+			rule.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("COUNT должен быть целым числом больше нуля")
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, err := parseRRuleTime(val)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = &until
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				weekday, err := parseRRuleWeekday(day)
+				if err != nil {
+					return nil, err
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "WKST":
+			if val != "MO" {
+				return nil, fmt.Errorf("Поддерживается только WKST=MO")
+			}
+		default:
+			return nil, fmt.Errorf("Часть %s правила повторения не поддерживается", name)
+		}
+	}
+
+	if rule.Frequency == "" {
+		return nil, fmt.Errorf("В правиле повторения нет FREQ")
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, fmt.Errorf("COUNT и UNTIL нельзя указывать вместе")
+	}
+	if rule.Frequency != RecurrenceMonthly {
+		for _, day := range rule.ByDay {
+			if day.N != 0 {
+				return nil, fmt.Errorf("Порядковый номер дня в BYDAY допускается только для FREQ=MONTHLY")
+			}
+		}
+	}
+
+	return rule, nil
+}
+
+// parseRRuleTime разбирает UNTIL: дату ГГГГММДД или время ГГГГММДДTЧЧММССZ
+func parseRRuleTime(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// Дата без времени включает весь день
+				t = t.Add(24*time.Hour - time.Nanosecond)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("UNTIL должен быть в формате ГГГГММДД или ГГГГММДДTЧЧММССZ")
+}
+
+func parseRRuleWeekday(value string) (RecurrenceWeekday, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 {
+		return RecurrenceWeekday{}, fmt.Errorf("Неверный день недели в BYDAY: %q", value)
+	}
+
+	weekday, ok := rruleWeekdays[value[len(value)-2:]]
+	if !ok {
+		return RecurrenceWeekday{}, fmt.Errorf("Неверный день недели в BYDAY: %q", value)
+	}
+	day := RecurrenceWeekday{Weekday: weekday}
+	if prefix := value[:len(value)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return RecurrenceWeekday{}, fmt.Errorf("Неверный номер дня в BYDAY: %q", value)
+		}
+		day.N = n
+	}
+	return day, nil
+}
+
+// String возвращает правило в каноническом виде
+func (r *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + string(r.Frequency)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = strings.ToUpper(day.Weekday.String()[:2])
+			if day.N != 0 {
+				days[i] = strconv.Itoa(day.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next возвращает первое повторение позже after для расписания, начинающегося в start.
+// Повторения приходятся на время суток start в его часовом поясе. COUNT здесь
+// не учитывается (см. TaskRecurrence.Advance); ok=false - повторений больше нет.
+func (r *RecurrenceRule) Next(start, after time.Time) (time.Time, bool) {
+	from := after.In(start.Location())
+	if from.Before(start) {
+		from = start
+	}
+
+	period := r.periodOf(start, from) / r.Interval * r.Interval
+	for i := 0; i < maxRecurrencePeriods; i, period = i+1, period+r.Interval {
+		candidates := r.candidates(start, period)
+		for _, candidate := range candidates {
+			if candidate.Before(start) || !candidate.After(after) {
+				continue
+			}
+			if r.Until != nil && candidate.After(*r.Until) {
+				return time.Time{}, false
+			}
+			return candidate, true
+		}
+		if r.Until != nil && len(candidates) > 0 && candidates[len(candidates)-1].After(*r.Until) {
+			return time.Time{}, false
+		}
+	}
+	return time.Time{}, false
+}
+
+// periodOf возвращает номер дня, недели или месяца t от начала расписания
+func (r *RecurrenceRule) periodOf(start, t time.Time) int {
+	switch r.Frequency {
+	case RecurrenceWeekly:
+		return daysBetween(weekStart(start), weekStart(t)) / 7
+	case RecurrenceMonthly:
+		return (t.Year()-start.Year())*12 + int(t.Month()-start.Month())
+	}
+	return daysBetween(start, t)
+}
+
+// candidates возвращает повторения периода по возрастанию
+func (r *RecurrenceRule) candidates(start time.Time, period int) []time.Time {
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+	}
+
+	var result []time.Time
+	switch r.Frequency {
+	case RecurrenceDaily:
+		day := at(start.Year(), start.Month(), start.Day()+period)
+		if len(r.ByDay) == 0 || r.hasWeekday(day.Weekday()) {
+			result = append(result, day)
+		}
+
+	case RecurrenceWeekly:
+		monday := weekStart(start)
+		if len(r.ByDay) == 0 {
+			offset := (int(start.Weekday()) + 6) % 7
+			result = append(result, at(monday.Year(), monday.Month(), monday.Day()+period*7+offset))
+		}
+		for _, day := range r.ByDay {
+			offset := (int(day.Weekday) + 6) % 7
+			result = append(result, at(monday.Year(), monday.Month(), monday.Day()+period*7+offset))
+		}
+
+	case RecurrenceMonthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(period), 1, 0, 0, 0, 0, time.UTC)
+		daysInMonth := first.AddDate(0, 1, -1).Day()
+		if len(r.ByDay) == 0 {
+			// Как в RFC 5545: месяц без нужного числа (31-е) пропускается
+			if start.Day() <= daysInMonth {
+				result = append(result, at(first.Year(), first.Month(), start.Day()))
+			}
+		}
+		for _, day := range r.ByDay {
+			for _, d := range monthWeekdays(first, daysInMonth, day) {
+				result = append(result, at(first.Year(), first.Month(), d))
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+	return result
+}
+
+func (r *RecurrenceRule) hasWeekday(weekday time.Weekday) bool {
+	for _, day := range r.ByDay {
+		if day.Weekday == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// monthWeekdays возвращает числа месяца, на которые приходится день BYDAY
+func monthWeekdays(first time.Time, daysInMonth int, day RecurrenceWeekday) []int {
+	var days []int
+	for d := 1 + (int(day.Weekday)-int(first.Weekday())+7)%7; d <= daysInMonth; d += 7 {
+		days = append(days, d)
+	}
+
+	switch {
+	case day.N > 0 && day.N <= len(days):
+		return days[day.N-1 : day.N]
+	case day.N < 0 && -day.N <= len(days):
+		return days[len(days)+day.N : len(days)+day.N+1]
+	case day.N != 0:
+		return nil
+	}
+	return days
+}
+
+// daysBetween - число календарных дней от a до b без учёта перехода на летнее время
+func daysBetween(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
+
+// weekStart возвращает понедельник недели t
+func weekStart(t time.Time) time.Time {
+	return t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+}
+
+// TaskRecurrence - расписание повторения задачи-шаблона. По расписанию создаются копии
+// шаблона с его участниками; создателем копий считается создатель шаблона.
+type TaskRecurrence struct {
+	ID             uuid.UUID `json:"id"`
+	TemplateTaskID uuid.UUID `json:"template_task_id"`
+	Rule           string    `json:"rule"`
+	StartsAt       time.Time `json:"starts_at"`
+	Timezone       string    `json:"timezone"`
+	// NextRunAt - следующее повторение; nil - повторения закончились
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+	// Occurrences - число наступивших повторений, включая пропущенные
+	Occurrences int `json:"occurrences"`
+	// Failures - число неудачных попыток подряд; до RetryAt расписание не обрабатывается
+	Failures  int        `json:"failures"`
+	RetryAt   *time.Time `json:"retry_at,omitempty"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// NewTaskRecurrence проверяет правило и часовой пояс и вычисляет первое повторение
+// не раньше startsAt
+func NewTaskRecurrence(templateTaskID, createdBy uuid.UUID, rule string, startsAt time.Time, timezone string) (*TaskRecurrence, error) {
+	parsed, err := ParseRecurrenceRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("Неизвестный часовой пояс: %s", timezone)
+	}
+
+	now := time.Now()
+	r := &TaskRecurrence{
+		ID:             uuid.New(),
+		TemplateTaskID: templateTaskID,
+		Rule:           parsed.String(),
+		StartsAt:       startsAt.In(location),
+		Timezone:       location.String(),
+		CreatedBy:      &createdBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	first, ok := parsed.Next(r.StartsAt, r.StartsAt.Add(-time.Nanosecond))
+	if !ok {
+		return nil, fmt.Errorf("Правило повторения не даёт ни одного повторения")
+	}
+	r.NextRunAt = &first
+	return r, nil
+}
+
+// schedule возвращает разобранное правило и начало расписания в его часовом поясе
+func (r *TaskRecurrence) schedule() (*RecurrenceRule, time.Time, error) {
+	rule, err := ParseRecurrenceRule(r.Rule)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	location, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("Неизвестный часовой пояс: %s", r.Timezone)
+	}
+	return rule, r.StartsAt.In(location), nil
+}
+
+// Advance отмечает повторение NextRunAt наступившим и переходит к следующему;
+// после последнего повторения (COUNT, UNTIL) NextRunAt становится nil
+func (r *TaskRecurrence) Advance() error {
+	if r.NextRunAt == nil {
+		return nil
+	}
+	rule, start, err := r.schedule()
+	if err != nil {
+		return err
+	}
+
+	r.Occurrences++
+	next, ok := rule.Next(start, *r.NextRunAt)
+	if !ok || (rule.Count > 0 && r.Occurrences >= rule.Count) {
+		r.NextRunAt = nil
+		return nil
+	}
+	r.NextRunAt = &next
+	return nil
+}
+
+// CatchUp засчитывает все повторения, наступившие к now (NextRunAt должен быть задан),
+// и возвращает последнее из них и число пропущенных до него. Создавать нужно только
+// последнее: пропущенные учитываются в COUNT, но копий не порождают.
+func (r *TaskRecurrence) CatchUp(now time.Time) (occurrence time.Time, skipped int, err error) {
+	occurrence = *r.NextRunAt
+	for {
+		if err := r.Advance(); err != nil {
+			return occurrence, skipped, err
+		}
+		if r.NextRunAt == nil || r.NextRunAt.After(now) {
+			return occurrence, skipped, nil
+		}
+		occurrence = *r.NextRunAt
+		skipped++
+	}
+}
+
+// Повторные попытки после ошибки: 1, 2, 4... минуты, но не реже раза в час
+const (
+	recurrenceRetryDelay    = time.Minute
+	recurrenceMaxRetryDelay = time.Hour
+)
+
+// Fail отмечает неудачную попытку создать повторение и откладывает следующую;
+// NextRunAt не меняется, поэтому повторение не теряется
+func (r *TaskRecurrence) Fail(now time.Time) {
+	delay := recurrenceMaxRetryDelay
+	if r.Failures < 6 {
+		delay = recurrenceRetryDelay << r.Failures
+	}
+	r.Failures++
+	retryAt := now.Add(delay)
+	r.RetryAt = &retryAt
+}
+
+// Upcoming возвращает до n ближайших повторений, начиная с NextRunAt
+func (r *TaskRecurrence) Upcoming(n int) []time.Time {
+	upcoming := []time.Time{}
+	preview := *r
+	for len(upcoming) < n && preview.NextRunAt != nil {
+		upcoming = append(upcoming, *preview.NextRunAt)
+		if err := preview.Advance(); err != nil {
+			break
+		}
+	}
+	return upcoming
+}
@@ -0,0 +1,261 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func utc(value string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func newRecurrence(t *testing.T, rule string, start time.Time, timezone string) *TaskRecurrence {
+	t.Helper()
+	r, err := NewTaskRecurrence(uuid.New(), uuid.New(), rule, start, timezone)
+	if err != nil {
+		t.Fatalf("NewTaskRecurrence(%q): %v", rule, err)
+	}
+	return r
+}
+
+func TestParseRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"RRULE:freq=weekly;interval=2;byday=mo,th", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH"},
+		{"FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3"},
+		{"FREQ=DAILY;INTERVAL=1;WKST=MO", "FREQ=DAILY"},
+		{"FREQ=WEEKLY;UNTIL=20250131", "FREQ=WEEKLY;UNTIL=20250131T235959Z"},
+		{"FREQ=DAILY;UNTIL=20250131T170000Z", "FREQ=DAILY;UNTIL=20250131T170000Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("String() = %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRecurrenceRuleErrors(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{"", "не задано"},
+		{"INTERVAL=2", "нет FREQ"},
+		{"FREQ=YEARLY", "Поддерживаются только"},
+		{"FREQ=DAILY;FREQ=WEEKLY", "указана дважды"},
+		{"FREQ=DAILY;INTERVAL=0", "INTERVAL"},
+		{"FREQ=DAILY;COUNT=-1", "COUNT"},
+		{"FREQ=DAILY;COUNT=2;UNTIL=20250101", "нельзя указывать вместе"},
+		{"FREQ=DAILY;UNTIL=завтра", "UNTIL"},
+		{"FREQ=WEEKLY;BYDAY=XX", "день недели"},
+		{"FREQ=MONTHLY;BYDAY=6MO", "номер дня"},
+		{"FREQ=WEEKLY;BYDAY=1MO", "только для FREQ=MONTHLY"},
+		{"FREQ=DAILY;BYMONTH=1", "не поддерживается"},
+		{"FREQ=DAILY;WKST=SU", "WKST"},
+		{"FREQ", "Неверная часть"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			_, err := ParseRecurrenceRule(tt.rule)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ошибка %v, ожидалось %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceUpcoming(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     string
+		start    time.Time
+		timezone string
+		want     []string
+		// finite - после want повторений больше нет
+		finite bool
+	}{
+		{
+			name:  "каждая вторая неделя по понедельникам и четвергам",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			start: utc("2025-01-01 10:00"), // среда
+			want: []string{
+				"2025-01-02 10:00", "2025-01-13 10:00", "2025-01-16 10:00",
+				"2025-01-27 10:00", "2025-01-30 10:00", "2025-02-10 10:00",
+			},
+		},
+		{
+			name:  "каждую неделю в день старта",
+			rule:  "FREQ=WEEKLY",
+			start: utc("2025-01-01 10:00"),
+			want:  []string{"2025-01-01 10:00", "2025-01-08 10:00", "2025-01-15 10:00"},
+		},
+		{
+			name:  "31-е число: месяцы без него пропускаются",
+			rule:  "FREQ=MONTHLY",
+			start: utc("2025-01-31 09:00"),
+			want:  []string{"2025-01-31 09:00", "2025-03-31 09:00", "2025-05-31 09:00", "2025-07-31 09:00"},
+		},
+		{
+			name:  "последняя пятница месяца",
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR",
+			start: utc("2025-01-01 18:00"),
+			want:  []string{"2025-01-31 18:00", "2025-02-28 18:00", "2025-03-28 18:00", "2025-04-25 18:00"},
+		},
+		{
+			name:  "первый понедельник раз в квартал",
+			rule:  "FREQ=MONTHLY;INTERVAL=3;BYDAY=1MO",
+			start: utc("2025-01-01 09:00"),
+			want:  []string{"2025-01-06 09:00", "2025-04-07 09:00", "2025-07-07 09:00"},
+		},
+		{
+			name:  "по будням",
+			rule:  "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR",
+			start: utc("2025-01-03 09:00"), // пятница
+			want:  []string{"2025-01-03 09:00", "2025-01-06 09:00", "2025-01-07 09:00"},
+		},
+		{
+			name:   "COUNT ограничивает число повторений",
+			rule:   "FREQ=DAILY;INTERVAL=3;COUNT=3",
+			start:  utc("2025-01-01 08:00"),
+			want:   []string{"2025-01-01 08:00", "2025-01-04 08:00", "2025-01-07 08:00"},
+			finite: true,
+		},
+		{
+			name:   "UNTIL включает последний день",
+			rule:   "FREQ=DAILY;UNTIL=20250103",
+			start:  utc("2025-01-01 23:00"),
+			want:   []string{"2025-01-01 23:00", "2025-01-02 23:00", "2025-01-03 23:00"},
+			finite: true,
+		},
+		{
+			name:     "время суток сохраняется при переходе на летнее время",
+			rule:     "FREQ=DAILY",
+			start:    utc("2025-03-29 08:00"), // 09:00 в Берлине, CET
+			timezone: "Europe/Berlin",
+			// С 30 марта CEST: 09:00 по Берлину - это 07:00 UTC
+			want: []string{"2025-03-29 08:00", "2025-03-30 07:00", "2025-03-31 07:00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timezone := tt.timezone
+			if timezone == "" {
+				timezone = "UTC"
+			}
+			r := newRecurrence(t, tt.rule, tt.start, timezone)
+
+			n := len(tt.want)
+			if tt.finite {
+				n++
+			}
+			got := r.Upcoming(n)
+			if len(got) != len(tt.want) {
+				t.Fatalf("получено %d повторений: %v, ожидалось %v", len(got), got, tt.want)
+			}
+			for i, want := range tt.want {
+				if !got[i].Equal(utc(want)) {
+					t.Errorf("повторение %d: %s, ожидалось %s UTC", i, got[i].UTC().Format("2006-01-02 15:04"), want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewTaskRecurrenceErrors(t *testing.T) {
+	if _, err := NewTaskRecurrence(uuid.New(), uuid.New(), "FREQ=DAILY", time.Now(), "Марс/Олимп"); err == nil {
+		t.Error("ожидалась ошибка неизвестного часового пояса")
+	}
+	// Правило в прошлом не даёт ни одного повторения
+	if _, err := NewTaskRecurrence(uuid.New(), uuid.New(), "FREQ=DAILY;UNTIL=20200101", utc("2025-01-01 00:00"), "UTC"); err == nil {
+		t.Error("ожидалась ошибка правила без повторений")
+	}
+}
+
+func catchUp(t *testing.T, r *TaskRecurrence, now time.Time) (time.Time, int) {
+	t.Helper()
+	occurrence, skipped, err := r.CatchUp(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return occurrence, skipped
+}
+
+func TestRecurrenceCatchUpCountsSkipped(t *testing.T) {
+	r := newRecurrence(t, "FREQ=DAILY;COUNT=5", utc("2025-01-01 09:00"), "UTC")
+
+	// Планировщик не работал два дня: создаётся повторение 3 января, два пропущены
+	occurrence, skipped := catchUp(t, r, utc("2025-01-03 12:00"))
+	if !occurrence.Equal(utc("2025-01-03 09:00")) || skipped != 2 {
+		t.Errorf("повторение %s, пропущено %d", occurrence, skipped)
+	}
+	if r.Occurrences != 3 || r.NextRunAt == nil || !r.NextRunAt.Equal(utc("2025-01-04 09:00")) {
+		t.Errorf("Occurrences = %d, NextRunAt = %v", r.Occurrences, r.NextRunAt)
+	}
+
+	// Пропущенные засчитаны в COUNT: осталось два повторения, создаётся последнее
+	occurrence, skipped = catchUp(t, r, utc("2025-01-10 12:00"))
+	if !occurrence.Equal(utc("2025-01-05 09:00")) || skipped != 1 {
+		t.Errorf("повторение %s, пропущено %d", occurrence, skipped)
+	}
+	if r.Occurrences != 5 || r.NextRunAt != nil {
+		t.Errorf("после COUNT: Occurrences = %d, NextRunAt = %v", r.Occurrences, r.NextRunAt)
+	}
+
+	if err := r.Advance(); err != nil || r.Occurrences != 5 {
+		t.Errorf("Advance после окончания: %v, Occurrences = %d", err, r.Occurrences)
+	}
+}
+
+// Реплики, прочитавшие одно и то же состояние расписания, должны получить одно и то же
+// повторение: на этом основана отметка в task_recurrence_occurrences
+func TestRecurrenceCatchUpIsDeterministic(t *testing.T) {
+	stored := newRecurrence(t, "FREQ=WEEKLY;BYDAY=MO,WE,FR", utc("2025-01-01 09:00"), "Europe/Moscow")
+	now := utc("2025-01-20 12:00")
+
+	first, second := *stored, *stored
+	a, _ := catchUp(t, &first, now)
+	b, _ := catchUp(t, &second, now)
+	if !a.Equal(b) || first.Occurrences != second.Occurrences || !first.NextRunAt.Equal(*second.NextRunAt) {
+		t.Errorf("разные результаты: %s/%d/%s и %s/%d/%s",
+			a, first.Occurrences, first.NextRunAt, b, second.Occurrences, second.NextRunAt)
+	}
+	if stored.Occurrences != 0 {
+		t.Error("обработка копии изменила исходное расписание")
+	}
+}
+
+func TestRecurrenceFail(t *testing.T) {
+	r := newRecurrence(t, "FREQ=DAILY", utc("2025-01-01 09:00"), "UTC")
+	next := *r.NextRunAt
+	now := utc("2025-01-01 09:00")
+
+	want := []time.Duration{1, 2, 4, 8, 16, 32, 60, 60}
+	for i, minutes := range want {
+		r.Fail(now)
+		if r.Failures != i+1 || r.RetryAt == nil || r.RetryAt.Sub(now) != minutes*time.Minute {
+			t.Errorf("попытка %d: Failures = %d, RetryAt = %v, ожидалось через %d мин", i+1, r.Failures, r.RetryAt, minutes)
+		}
+	}
+	if !r.NextRunAt.Equal(next) {
+		t.Errorf("Fail изменил NextRunAt: %s", r.NextRunAt)
+	}
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "github.com/google/uuid"
+
+// SearchResult - задача, найденная полнотекстовым поиском.
+// Если лучшее совпадение найдено в сообщении, MessageID указывает на него.
+type SearchResult struct {
+	Task      *Task      `json:"task"`
+	MessageID *uuid.UUID `json:"message_id,omitempty"`
+	Rank      float64    `json:"rank"`
+	Title     string     `json:"title"`
+	Snippet   string     `json:"snippet"`
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SprintState string
+
+const (
+	SprintStatePlanned SprintState = "planned"
+	SprintStateActive  SprintState = "active"
+	SprintStateClosed  SprintState = "closed"
+)
+
+func (s SprintState) IsValid() bool {
+	switch s {
+	case SprintStatePlanned, SprintStateActive, SprintStateClosed:
+		return true
+	}
+	return false
+}
+
+type Sprint struct {
+	ID        uuid.UUID   `json:"id"`
+	ProjectID uuid.UUID   `json:"project_id"`
+	Name      string      `json:"name"`
+	Goal      string      `json:"goal"`
+	StartDate time.Time   `json:"start_date"`
+	EndDate   time.Time   `json:"end_date"`
+	State     SprintState `json:"state"`
+	StartedAt *time.Time  `json:"started_at,omitempty"`
+	ClosedAt  *time.Time  `json:"closed_at,omitempty"`
+	CreatedBy *uuid.UUID  `json:"created_by,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+func NewSprint(projectID uuid.UUID, name, goal string, startDate, endDate time.Time, createdBy uuid.UUID) *Sprint {
+	now := time.Now()
+	return &Sprint{
+		ID:        uuid.New(),
+		ProjectID: projectID,
+		Name:      name,
+		Goal:      goal,
+		StartDate: startDate,
+		EndDate:   endDate,
+		State:     SprintStatePlanned,
+		CreatedBy: &createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// SprintReport - итоги спринта. Committed - задачи, бывшие в спринте на момент старта
+// (для запланированного спринта - его текущие задачи), Added - добавленные после старта,
+// Removed - убранные из идущего спринта, CarriedOver - перенесённые при закрытии,
+// Completed - задачи, оставшиеся в спринте и закрытые на момент его закрытия. Часы берутся из записей времени
+// по задачам спринта с даты начала до даты окончания (или закрытия, если спринт закрыт).
+type SprintReport struct {
+	SprintID           uuid.UUID `json:"sprint_id"`
+	CommittedCount     int       `json:"committed_count"`
+	AddedCount         int       `json:"added_count"`
+	RemovedCount       int       `json:"removed_count"`
+	CarriedOverCount   int       `json:"carried_over_count"`
+	CompletedCount     int       `json:"completed_count"`
+	CompletedCommitted int       `json:"completed_committed"`
+	CommittedHours     float64   `json:"committed_hours"`
+	CompletedHours     float64   `json:"completed_hours"`
+	TotalHours         float64   `json:"total_hours"`
+}
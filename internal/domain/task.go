@@ -32,6 +32,10 @@ func (s TaskStatus) String() string {
 
 type Task struct {
 	ID          uuid.UUID  `json:"id"`
+	ProjectID   *uuid.UUID `json:"project_id,omitempty"`
+	Key         *string    `json:"key,omitempty"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
+	SprintID    *uuid.UUID `json:"sprint_id,omitempty"`
 	Title       string     `json:"title"`
 	Description string     `json:"description"`
 	Status      TaskStatus `json:"status"`
@@ -42,6 +46,13 @@ type Task struct {
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	// BoardRank - позиция задачи в колонке доски (см. RankBetween)
+	BoardRank *string `json:"board_rank,omitempty"`
+	// Blocked вычисляется при чтении: есть незакрытая задача, блокирующая эту
+	Blocked bool    `json:"blocked"`
+	Labels  []Label `json:"labels"`
+	// CustomFields - значения пользовательских полей по ключу поля
+	CustomFields map[string]interface{} `json:"custom_fields"`
 }
 
 func NewTask(title, description string, priority int, createdBy uuid.UUID, dueDate *time.Time) *Task {
@@ -59,3 +70,25 @@ func NewTask(title, description string, priority int, createdBy uuid.UUID, dueDa
 		UpdatedAt:   now,
 	}
 }
+
+// TaskRollup - сводка по поддереву задачи: прогресс подзадач всех уровней
+// и время, списанное на саму задачу и все её подзадачи
+type TaskRollup struct {
+	TaskID         uuid.UUID `json:"task_id"`
+	TotalSubtasks  int       `json:"total_subtasks"`
+	ClosedSubtasks int       `json:"closed_subtasks"`
+	TotalHours     float64   `json:"total_hours"`
+}
+
+// Progress возвращает долю закрытых подзадач от 0 до 1; без подзадач прогресс равен 0
+func (r *TaskRollup) Progress() float64 {
+	if r.TotalSubtasks == 0 {
+		return 0
+	}
+	return float64(r.ClosedSubtasks) / float64(r.TotalSubtasks)
+}
+
+// OpenSubtasks возвращает число незакрытых подзадач всех уровней
+func (r *TaskRollup) OpenSubtasks() int {
+	return r.TotalSubtasks - r.ClosedSubtasks
+}
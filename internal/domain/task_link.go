@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TaskLinkType string
+
+const (
+	// TaskLinkBlocks - исходная задача блокирует целевую
+	TaskLinkBlocks TaskLinkType = "blocks"
+	// TaskLinkRelatesTo - задачи связаны без ограничений
+	TaskLinkRelatesTo TaskLinkType = "relates_to"
+	// TaskLinkDuplicates - исходная задача дублирует целевую
+	TaskLinkDuplicates TaskLinkType = "duplicates"
+)
+
+func (t TaskLinkType) IsValid() bool {
+	switch t {
+	case TaskLinkBlocks, TaskLinkRelatesTo, TaskLinkDuplicates:
+		return true
+	}
+	return false
+}
+
+type TaskLink struct {
+	ID           uuid.UUID    `json:"id"`
+	SourceTaskID uuid.UUID    `json:"source_task_id"`
+	TargetTaskID uuid.UUID    `json:"target_task_id"`
+	Type         TaskLinkType `json:"type"`
+	CreatedBy    *uuid.UUID   `json:"created_by,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+func NewTaskLink(sourceTaskID, targetTaskID uuid.UUID, linkType TaskLinkType, createdBy uuid.UUID) *TaskLink {
+	return &TaskLink{
+		ID:           uuid.New(),
+		SourceTaskID: sourceTaskID,
+		TargetTaskID: targetTaskID,
+		Type:         linkType,
+		CreatedBy:    &createdBy,
+		CreatedAt:    time.Now(),
+	}
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskView - сохранённый фильтр списка задач. Query хранит параметры GET /tasks
+// в виде query-строки; значение "me" подставляется текущим сотрудником при выполнении.
+type TaskView struct {
+	ID               uuid.UUID `json:"id"`
+	OwnerID          uuid.UUID `json:"owner_id"`
+	Name             string    `json:"name"`
+	Query            string    `json:"query"`
+	SharedDepartment *string   `json:"shared_department,omitempty"`
+	// IsDefault относится к сотруднику, запросившему представление
+	IsDefault bool      `json:"is_default"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewTaskView(ownerID uuid.UUID, name, query string, sharedDepartment *string) *TaskView {
+	now := time.Now()
+	return &TaskView{
+		ID:               uuid.New(),
+		OwnerID:          ownerID,
+		Name:             name,
+		Query:            query,
+		SharedDepartment: sharedDepartment,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+}
+
+// IsVisibleTo сообщает, доступно ли представление сотруднику из указанного отдела
+func (v *TaskView) IsVisibleTo(employeeID uuid.UUID, department string) bool {
+	return v.OwnerID == employeeID || (v.SharedDepartment != nil && *v.SharedDepartment == department)
+}
@@ -38,3 +38,15 @@ type TimeSummary struct {
 	EntryCount      int       `json:"entry_count"`
 	UniqueEmployees int       `json:"unique_employees"`
 }
+
+// TimesheetRow - часы сотрудника по задаче за день (строка табеля)
+type TimesheetRow struct {
+	EmployeeID   uuid.UUID
+	EmployeeName string
+	Department   string
+	TaskID       uuid.UUID
+	TaskKey      *string
+	TaskTitle    string
+	Date         time.Time
+	Hours        float64
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Формат архива рабочего пространства. Версия увеличивается при несовместимом изменении
+// записей; импорт принимает архивы версии не новее ArchiveVersion.
+const (
+	ArchiveFormat  = "taskmanager-workspace"
+	ArchiveVersion = 1
+)
+
+// ArchiveRecordType - тип строки архива
+type ArchiveRecordType string
+
+const (
+	RecordHeader      ArchiveRecordType = "header"
+	RecordEmployee    ArchiveRecordType = "employee"
+	RecordProject     ArchiveRecordType = "project"
+	RecordTask        ArchiveRecordType = "task"
+	RecordParticipant ArchiveRecordType = "participant"
+	RecordMessage     ArchiveRecordType = "message"
+	RecordTimeEntry   ArchiveRecordType = "time_entry"
+	RecordFooter      ArchiveRecordType = "footer"
+)
+
+// ArchiveRecordOrder - порядок записей в архиве: каждая запись ссылается только на записи
+// предыдущих типов или, для подзадач, на уже выгруженные задачи
+var ArchiveRecordOrder = []ArchiveRecordType{
+	RecordEmployee,
+	RecordProject,
+	RecordTask,
+	RecordParticipant,
+	RecordMessage,
+	RecordTimeEntry,
+}
+
+// ArchiveHeader - первая строка архива
+type ArchiveHeader struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// ArchiveFooter - последняя строка архива; по числу записей импорт обнаруживает оборванный архив
+type ArchiveFooter struct {
+	Counts map[ArchiveRecordType]int `json:"counts"`
+}
+
+// ArchivedEmployee - сотрудник в архиве; хеш пароля выгружается только по явному запросу
+type ArchivedEmployee struct {
+	ID           uuid.UUID  `json:"id"`
+	Name         string     `json:"name"`
+	Department   string     `json:"department"`
+	Position     string     `json:"position"`
+	Email        string     `json:"email"`
+	PasswordHash *string    `json:"password_hash,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+}
+
+// ArchivedProject - проект в архиве вместе с участниками
+type ArchivedProject struct {
+	ID          uuid.UUID   `json:"id"`
+	Key         string      `json:"key"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	OwnerID     uuid.UUID   `json:"owner_id"`
+	TaskCounter int         `json:"task_counter"`
+	Members     []uuid.UUID `json:"members"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	DeletedAt   *time.Time  `json:"deleted_at,omitempty"`
+}
+
+// ArchivedTask - задача в архиве. Спринт не выгружается: спринты не входят в архив.
+type ArchivedTask struct {
+	ID          uuid.UUID  `json:"id"`
+	ProjectID   *uuid.UUID `json:"project_id,omitempty"`
+	Key         *string    `json:"key,omitempty"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      TaskStatus `json:"status"`
+	Priority    int        `json:"priority"`
+	CreatedBy   uuid.UUID  `json:"created_by"`
+	Archived    bool       `json:"archived"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	BoardRank   *string    `json:"board_rank,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
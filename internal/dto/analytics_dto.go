@@ -0,0 +1,107 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+)
+
+type StatusTransitionResponse struct {
+	FromStatus *string   `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ChangedBy  *string   `json:"changed_by,omitempty"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+func ToStatusTransitionResponse(t *domain.StatusTransition) StatusTransitionResponse {
+	resp := StatusTransitionResponse{
+		ToStatus:  string(t.ToStatus),
+		ChangedAt: t.ChangedAt,
+	}
+	if t.FromStatus != nil {
+		from := string(*t.FromStatus)
+		resp.FromStatus = &from
+	}
+	if t.ChangedBy != nil {
+		changedBy := t.ChangedBy.String()
+		resp.ChangedBy = &changedBy
+	}
+	return resp
+}
+
+type BurndownPointResponse struct {
+	Date      string  `json:"date"`
+	Scope     int     `json:"scope"`
+	Completed int     `json:"completed"`
+	Remaining int     `json:"remaining"`
+	Ideal     float64 `json:"ideal"`
+}
+
+func ToBurndownResponse(points []*domain.BurndownPoint) []BurndownPointResponse {
+	responses := make([]BurndownPointResponse, len(points))
+	for i, p := range points {
+		responses[i] = BurndownPointResponse{
+			Date:      p.Date.Format("2006-01-02"),
+			Scope:     p.Scope,
+			Completed: p.Completed,
+			Remaining: p.Remaining,
+			Ideal:     p.Ideal,
+		}
+	}
+	return responses
+}
+
+type FlowPointResponse struct {
+	Date   string         `json:"date"`
+	Counts map[string]int `json:"counts"`
+}
+
+func ToCumulativeFlowResponse(points []*domain.FlowPoint) []FlowPointResponse {
+	responses := make([]FlowPointResponse, len(points))
+	for i, p := range points {
+		counts := make(map[string]int, len(p.Counts))
+		for status, count := range p.Counts {
+			counts[string(status)] = count
+		}
+		responses[i] = FlowPointResponse{
+			Date:   p.Date.Format("2006-01-02"),
+			Counts: counts,
+		}
+	}
+	return responses
+}
+
+// PercentilesResponse - перцентили длительности в часах
+type PercentilesResponse struct {
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P90 float64 `json:"p90"`
+	P95 float64 `json:"p95"`
+}
+
+type FlowTimeResponse struct {
+	Team      string               `json:"team"`
+	Tasks     int                  `json:"tasks"`
+	LeadTime  PercentilesResponse  `json:"lead_time_hours"`
+	CycleTime *PercentilesResponse `json:"cycle_time_hours"`
+}
+
+func ToFlowTimesResponse(stats []*domain.FlowTimeStats) []FlowTimeResponse {
+	responses := make([]FlowTimeResponse, len(stats))
+	for i, s := range stats {
+		responses[i] = FlowTimeResponse{
+			Team:     s.Team,
+			Tasks:    s.Tasks,
+			LeadTime: toPercentilesResponse(s.LeadTime),
+		}
+		if s.CycleTime != nil {
+			cycle := toPercentilesResponse(*s.CycleTime)
+			responses[i].CycleTime = &cycle
+		}
+	}
+	return responses
+}
+
+func toPercentilesResponse(p domain.Percentiles) PercentilesResponse {
+	return PercentilesResponse{P50: p.P50, P75: p.P75, P90: p.P90, P95: p.P95}
+}
@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+)
+
+type AttachmentResponse struct {
+	ID          string    `json:"id"`
+	TaskID      string    `json:"task_id"`
+	MessageID   *string   `json:"message_id,omitempty"`
+	UploadedBy  *string   `json:"uploaded_by,omitempty"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	Checksum    string    `json:"checksum"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func ToAttachmentResponse(a *domain.Attachment) AttachmentResponse {
+	resp := AttachmentResponse{
+		ID:          a.ID.String(),
+		TaskID:      a.TaskID.String(),
+		FileName:    a.FileName,
+		ContentType: a.ContentType,
+		SizeBytes:   a.SizeBytes,
+		Checksum:    a.Checksum,
+		CreatedAt:   a.CreatedAt,
+	}
+
+	if a.MessageID != nil {
+		messageID := a.MessageID.String()
+		resp.MessageID = &messageID
+	}
+
+	if a.UploadedBy != nil {
+		uploadedBy := a.UploadedBy.String()
+		resp.UploadedBy = &uploadedBy
+	}
+
+	return resp
+}
+
+// AttachmentLinkResponse - подписанная ссылка на скачивание, не требующая заголовка Authorization
+type AttachmentLinkResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+// MoveTaskRequest - after_id и before_id задают соседей задачи в колонке status;
+// без них задача остаётся на месте или встаёт в конец новой колонки
+type MoveTaskRequest struct {
+	Status   string  `json:"status" validate:"required,oneof=new in_progress code_review testing returned_with_errors closed"`
+	AfterID  *string `json:"after_id" validate:"omitempty,uuid"`
+	BeforeID *string `json:"before_id" validate:"omitempty,uuid"`
+}
+
+type MoveTaskResponse struct {
+	Task     TaskResponse `json:"task"`
+	Warnings []string     `json:"warnings,omitempty"`
+}
+
+type BoardColumnResponse struct {
+	Status   string         `json:"status"`
+	WIPLimit int            `json:"wip_limit,omitempty"`
+	Total    int            `json:"total"`
+	Tasks    []TaskResponse `json:"tasks"`
+}
+
+type BoardResponse struct {
+	Columns []BoardColumnResponse `json:"columns"`
+}
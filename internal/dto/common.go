@@ -13,15 +13,40 @@ type ErrorResponse struct {
 }
 
 type ErrorDetailWrapper struct {
-	Code    string                `json:"code"`
-	Message string                `json:"message"`
+	Code    string               `json:"code"`
+	Message string               `json:"message"`
 	Details []errors.ErrorDetail `json:"details,omitempty"`
 }
 
+// PaginatedResponse - страница списка. При постраничной навигации (page/page_size)
+// заполняются total, page, page_size и total_pages; при навигации курсором -
+// limit и next_cursor, а total только если он был запрошен.
 type PaginatedResponse struct {
 	Data       interface{} `json:"data"`
-	Total      int         `json:"total"`
-	Page       int         `json:"page"`
-	PageSize   int         `json:"page_size"`
-	TotalPages int         `json:"total_pages"`
+	Total      *int        `json:"total,omitempty"`
+	Page       int         `json:"page,omitempty"`
+	PageSize   int         `json:"page_size,omitempty"`
+	TotalPages *int        `json:"total_pages,omitempty"`
+	Limit      int         `json:"limit,omitempty"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+func NewPagedResponse(data interface{}, total, page, pageSize int) PaginatedResponse {
+	totalPages := (total + pageSize - 1) / pageSize
+	return PaginatedResponse{
+		Data:       data,
+		Total:      &total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: &totalPages,
+	}
+}
+
+func NewCursorResponse(data interface{}, limit int, nextCursor string, total *int) PaginatedResponse {
+	return PaginatedResponse{
+		Data:       data,
+		Total:      total,
+		Limit:      limit,
+		NextCursor: nextCursor,
+	}
 }
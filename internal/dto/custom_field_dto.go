@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+)
+
+type CreateCustomFieldRequest struct {
+	ProjectID string   `json:"project_id" validate:"omitempty,uuid"`
+	Key       string   `json:"key" validate:"required,min=1,max=50"`
+	Name      string   `json:"name" validate:"required,min=1,max=100"`
+	Type      string   `json:"type" validate:"required,oneof=text number date single_select multi_select employee"`
+	Options   []string `json:"options"`
+}
+
+type UpdateCustomFieldRequest struct {
+	Name    string   `json:"name" validate:"required,min=1,max=100"`
+	Options []string `json:"options"`
+}
+
+type CustomFieldResponse struct {
+	ID        string    `json:"id"`
+	ProjectID *string   `json:"project_id,omitempty"`
+	Key       string    `json:"key"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Options   []string  `json:"options"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func ToCustomFieldResponse(f *domain.CustomField) CustomFieldResponse {
+	resp := CustomFieldResponse{
+		ID:        f.ID.String(),
+		Key:       f.Key,
+		Name:      f.Name,
+		Type:      string(f.Type),
+		Options:   f.Options,
+		CreatedAt: f.CreatedAt,
+		UpdatedAt: f.UpdatedAt,
+	}
+
+	if f.ProjectID != nil {
+		projectID := f.ProjectID.String()
+		resp.ProjectID = &projectID
+	}
+	if resp.Options == nil {
+		resp.Options = []string{}
+	}
+
+	return resp
+}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+)
+
+type CreateLabelRequest struct {
+	Name  string `json:"name" validate:"required,min=1,max=50"`
+	Color string `json:"color"`
+}
+
+type UpdateLabelRequest struct {
+	Name  string `json:"name" validate:"required,min=1,max=50"`
+	Color string `json:"color"`
+}
+
+type AddTaskLabelRequest struct {
+	LabelID string `json:"label_id" validate:"required,uuid"`
+}
+
+type LabelResponse struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Color      string    `json:"color"`
+	UsageCount int       `json:"usage_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func ToLabelResponse(l *domain.Label) LabelResponse {
+	return LabelResponse{
+		ID:         l.ID.String(),
+		Name:       l.Name,
+		Color:      l.Color,
+		UsageCount: l.UsageCount,
+		CreatedAt:  l.CreatedAt,
+		UpdatedAt:  l.UpdatedAt,
+	}
+}
+
+// TaskLabelResponse - краткое представление метки в карточке задачи
+type TaskLabelResponse struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+func ToTaskLabelResponse(l domain.Label) TaskLabelResponse {
+	return TaskLabelResponse{
+		ID:    l.ID.String(),
+		Name:  l.Name,
+		Color: l.Color,
+	}
+}
@@ -19,6 +19,7 @@ type MessageResponse struct {
 	TaskID          string    `json:"task_id"`
 	AuthorID        *string   `json:"author_id,omitempty"`
 	Content         string    `json:"content"`
+	ContentHTML     string    `json:"content_html,omitempty"`
 	IsSystemMessage bool      `json:"is_system_message"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
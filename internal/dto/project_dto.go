@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+)
+
+type CreateProjectRequest struct {
+	Key         string `json:"key" validate:"required,min=2,max=10"`
+	Name        string `json:"name" validate:"required,min=1,max=255"`
+	Description string `json:"description"`
+}
+
+type UpdateProjectRequest struct {
+	Name        string `json:"name" validate:"required,min=1,max=255"`
+	Description string `json:"description"`
+}
+
+type AddProjectMemberRequest struct {
+	EmployeeID string `json:"employee_id" validate:"required,uuid"`
+}
+
+type ProjectResponse struct {
+	ID          string    `json:"id"`
+	Key         string    `json:"key"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	OwnerID     string    `json:"owner_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func ToProjectResponse(p *domain.Project) ProjectResponse {
+	return ProjectResponse{
+		ID:          p.ID.String(),
+		Key:         p.Key,
+		Name:        p.Name,
+		Description: p.Description,
+		OwnerID:     p.OwnerID.String(),
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+type ProjectMemberResponse struct {
+	EmployeeID string    `json:"employee_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func ToProjectMemberResponse(m *domain.ProjectMember) ProjectMemberResponse {
+	return ProjectMemberResponse{
+		EmployeeID: m.EmployeeID.String(),
+		CreatedAt:  m.CreatedAt,
+	}
+}
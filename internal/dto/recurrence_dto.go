@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+)
+
+// upcomingOccurrences - сколько ближайших повторений показывать в ответе
+const upcomingOccurrences = 5
+
+// SetRecurrenceRequest - rule в формате RRULE, например FREQ=WEEKLY;BYDAY=MO,TH;COUNT=10;
+// starts_at в RFC 3339 (по умолчанию - текущий момент), timezone - имя из базы IANA (по умолчанию UTC)
+type SetRecurrenceRequest struct {
+	Rule     string     `json:"rule" validate:"required,max=500"`
+	StartsAt *time.Time `json:"starts_at"`
+	Timezone string     `json:"timezone" validate:"max=64"`
+}
+
+type RecurrenceResponse struct {
+	ID             string      `json:"id"`
+	TemplateTaskID string      `json:"template_task_id"`
+	Rule           string      `json:"rule"`
+	StartsAt       time.Time   `json:"starts_at"`
+	Timezone       string      `json:"timezone"`
+	NextRunAt      *time.Time  `json:"next_run_at"`
+	Upcoming       []time.Time `json:"upcoming"`
+	Occurrences    int         `json:"occurrences"`
+	Failures       int         `json:"failures"`
+	RetryAt        *time.Time  `json:"retry_at,omitempty"`
+	CreatedBy      *string     `json:"created_by,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+func ToRecurrenceResponse(r *domain.TaskRecurrence) RecurrenceResponse {
+	resp := RecurrenceResponse{
+		ID:             r.ID.String(),
+		TemplateTaskID: r.TemplateTaskID.String(),
+		Rule:           r.Rule,
+		StartsAt:       r.StartsAt,
+		Timezone:       r.Timezone,
+		NextRunAt:      r.NextRunAt,
+		Upcoming:       r.Upcoming(upcomingOccurrences),
+		Occurrences:    r.Occurrences,
+		Failures:       r.Failures,
+		RetryAt:        r.RetryAt,
+		CreatedAt:      r.CreatedAt,
+		UpdatedAt:      r.UpdatedAt,
+	}
+	if r.CreatedBy != nil {
+		createdBy := r.CreatedBy.String()
+		resp.CreatedBy = &createdBy
+	}
+	return resp
+}
@@ -0,0 +1,29 @@
+package dto
+
+import "github.com/dmitry/taskmanager/internal/domain"
+
+// SearchResultResponse - найденная задача с подсвеченными фрагментами.
+// Поля title_highlight и snippet содержат экранированный HTML, где совпадения обёрнуты в <mark>.
+type SearchResultResponse struct {
+	Task           TaskResponse `json:"task"`
+	MessageID      *string      `json:"message_id,omitempty"`
+	Rank           float64      `json:"rank"`
+	TitleHighlight string       `json:"title_highlight"`
+	Snippet        string       `json:"snippet"`
+}
+
+func ToSearchResultResponse(r *domain.SearchResult) SearchResultResponse {
+	resp := SearchResultResponse{
+		Task:           ToTaskResponse(r.Task),
+		Rank:           r.Rank,
+		TitleHighlight: r.Title,
+		Snippet:        r.Snippet,
+	}
+
+	if r.MessageID != nil {
+		messageID := r.MessageID.String()
+		resp.MessageID = &messageID
+	}
+
+	return resp
+}
@@ -0,0 +1,88 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+)
+
+// SaveSprintRequest - даты в формате ГГГГ-ММ-ДД
+type SaveSprintRequest struct {
+	Name      string `json:"name" validate:"required,min=1,max=255"`
+	Goal      string `json:"goal"`
+	StartDate string `json:"start_date" validate:"required"`
+	EndDate   string `json:"end_date" validate:"required"`
+}
+
+// CloseSprintRequest - move_to_sprint_id: null возвращает незакрытые задачи в бэклог
+type CloseSprintRequest struct {
+	MoveToSprintID *string `json:"move_to_sprint_id" validate:"omitempty,uuid"`
+}
+
+type CloseSprintResponse struct {
+	Message    string `json:"message"`
+	MovedTasks int    `json:"moved_tasks"`
+}
+
+// SetTaskSprintRequest - sprint_id: null возвращает задачу в бэклог
+type SetTaskSprintRequest struct {
+	SprintID *string `json:"sprint_id" validate:"omitempty,uuid"`
+}
+
+type SprintResponse struct {
+	ID        string     `json:"id"`
+	ProjectID string     `json:"project_id"`
+	Name      string     `json:"name"`
+	Goal      string     `json:"goal"`
+	StartDate string     `json:"start_date"`
+	EndDate   string     `json:"end_date"`
+	State     string     `json:"state"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func ToSprintResponse(s *domain.Sprint) SprintResponse {
+	return SprintResponse{
+		ID:        s.ID.String(),
+		ProjectID: s.ProjectID.String(),
+		Name:      s.Name,
+		Goal:      s.Goal,
+		StartDate: s.StartDate.Format("2006-01-02"),
+		EndDate:   s.EndDate.Format("2006-01-02"),
+		State:     string(s.State),
+		StartedAt: s.StartedAt,
+		ClosedAt:  s.ClosedAt,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+type SprintReportResponse struct {
+	SprintID           string  `json:"sprint_id"`
+	CommittedCount     int     `json:"committed_count"`
+	AddedCount         int     `json:"added_count"`
+	RemovedCount       int     `json:"removed_count"`
+	CarriedOverCount   int     `json:"carried_over_count"`
+	CompletedCount     int     `json:"completed_count"`
+	CompletedCommitted int     `json:"completed_committed"`
+	CommittedHours     float64 `json:"committed_hours"`
+	CompletedHours     float64 `json:"completed_hours"`
+	TotalHours         float64 `json:"total_hours"`
+}
+
+func ToSprintReportResponse(r *domain.SprintReport) SprintReportResponse {
+	return SprintReportResponse{
+		SprintID:           r.SprintID.String(),
+		CommittedCount:     r.CommittedCount,
+		AddedCount:         r.AddedCount,
+		RemovedCount:       r.RemovedCount,
+		CarriedOverCount:   r.CarriedOverCount,
+		CompletedCount:     r.CompletedCount,
+		CompletedCommitted: r.CompletedCommitted,
+		CommittedHours:     r.CommittedHours,
+		CompletedHours:     r.CompletedHours,
+		TotalHours:         r.TotalHours,
+	}
+}
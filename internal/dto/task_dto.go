@@ -7,10 +7,12 @@ import (
 )
 
 type CreateTaskRequest struct {
-	Title        string              `json:"title" validate:"required,min=3,max=500"`
-	Description  string              `json:"description"`
-	Priority     int                 `json:"priority" validate:"min=0,max=2"`
-	DueDate      *string             `json:"due_date,omitempty"`
+	ProjectID    *string            `json:"project_id,omitempty" validate:"omitempty,uuid"`
+	ParentID     *string            `json:"parent_id,omitempty" validate:"omitempty,uuid"`
+	Title        string             `json:"title" validate:"required,min=3,max=500"`
+	Description  string             `json:"description"`
+	Priority     int                `json:"priority" validate:"min=0,max=2"`
+	DueDate      *string            `json:"due_date,omitempty"`
 	Participants []ParticipantInput `json:"participants"`
 }
 
@@ -21,10 +23,20 @@ type UpdateTaskRequest struct {
 	DueDate     *string `json:"due_date,omitempty"`
 }
 
+// SetTaskParentRequest - parent_id: null делает задачу корневой
+type SetTaskParentRequest struct {
+	ParentID *string `json:"parent_id" validate:"omitempty,uuid"`
+}
+
 type UpdateTaskStatusRequest struct {
 	Status string `json:"status" validate:"required,oneof=new in_progress code_review testing returned_with_errors closed"`
 }
 
+type UpdateTaskStatusResponse struct {
+	Message  string   `json:"message"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
 type ParticipantInput struct {
 	EmployeeID string `json:"employee_id" validate:"required,uuid"`
 	Role       string `json:"role" validate:"required,oneof=executor responsible customer"`
@@ -36,29 +48,64 @@ type AddParticipantRequest struct {
 }
 
 type TaskResponse struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Status      string    `json:"status"`
-	Priority    int       `json:"priority"`
-	CreatedBy   string    `json:"created_by"`
-	Archived    bool      `json:"archived"`
-	DueDate     *string   `json:"due_date,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID              string                 `json:"id"`
+	ProjectID       *string                `json:"project_id,omitempty"`
+	Key             *string                `json:"key,omitempty"`
+	ParentID        *string                `json:"parent_id,omitempty"`
+	SprintID        *string                `json:"sprint_id,omitempty"`
+	Title           string                 `json:"title"`
+	Description     string                 `json:"description"`
+	DescriptionHTML string                 `json:"description_html,omitempty"`
+	Status          string                 `json:"status"`
+	Priority        int                    `json:"priority"`
+	CreatedBy       string                 `json:"created_by"`
+	Archived        bool                   `json:"archived"`
+	Blocked         bool                   `json:"blocked"`
+	Labels          []TaskLabelResponse    `json:"labels"`
+	CustomFields    map[string]interface{} `json:"custom_fields"`
+	DueDate         *string                `json:"due_date,omitempty"`
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
 }
 
 func ToTaskResponse(t *domain.Task) TaskResponse {
 	resp := TaskResponse{
-		ID:          t.ID.String(),
-		Title:       t.Title,
-		Description: t.Description,
-		Status:      string(t.Status),
-		Priority:    t.Priority,
-		CreatedBy:   t.CreatedBy.String(),
-		Archived:    t.Archived,
-		CreatedAt:   t.CreatedAt,
-		UpdatedAt:   t.UpdatedAt,
+		ID:           t.ID.String(),
+		Key:          t.Key,
+		Title:        t.Title,
+		Description:  t.Description,
+		Status:       string(t.Status),
+		Priority:     t.Priority,
+		CreatedBy:    t.CreatedBy.String(),
+		Archived:     t.Archived,
+		Blocked:      t.Blocked,
+		CreatedAt:    t.CreatedAt,
+		UpdatedAt:    t.UpdatedAt,
+		Labels:       make([]TaskLabelResponse, len(t.Labels)),
+		CustomFields: t.CustomFields,
+	}
+
+	if resp.CustomFields == nil {
+		resp.CustomFields = map[string]interface{}{}
+	}
+
+	for i, l := range t.Labels {
+		resp.Labels[i] = ToTaskLabelResponse(l)
+	}
+
+	if t.ProjectID != nil {
+		projectID := t.ProjectID.String()
+		resp.ProjectID = &projectID
+	}
+
+	if t.ParentID != nil {
+		parentID := t.ParentID.String()
+		resp.ParentID = &parentID
+	}
+
+	if t.SprintID != nil {
+		sprintID := t.SprintID.String()
+		resp.SprintID = &sprintID
 	}
 
 	if t.DueDate != nil {
@@ -69,6 +116,27 @@ func ToTaskResponse(t *domain.Task) TaskResponse {
 	return resp
 }
 
+type TaskRollupResponse struct {
+	TotalSubtasks  int     `json:"total_subtasks"`
+	ClosedSubtasks int     `json:"closed_subtasks"`
+	Progress       float64 `json:"progress"`
+	TotalHours     float64 `json:"total_hours"`
+}
+
+func ToTaskRollupResponse(r *domain.TaskRollup) TaskRollupResponse {
+	return TaskRollupResponse{
+		TotalSubtasks:  r.TotalSubtasks,
+		ClosedSubtasks: r.ClosedSubtasks,
+		Progress:       r.Progress(),
+		TotalHours:     r.TotalHours,
+	}
+}
+
+type SubtasksResponse struct {
+	Subtasks []TaskResponse     `json:"subtasks"`
+	Rollup   TaskRollupResponse `json:"rollup"`
+}
+
 type TaskParticipantResponse struct {
 	ID         string    `json:"id"`
 	EmployeeID string    `json:"employee_id"`
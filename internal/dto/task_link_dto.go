@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+)
+
+type CreateTaskLinkRequest struct {
+	TaskID string `json:"task_id" validate:"required,uuid"`
+	Type   string `json:"type" validate:"required,oneof=blocks blocked_by relates_to duplicates"`
+}
+
+type TaskLinkResponse struct {
+	ID           string    `json:"id"`
+	SourceTaskID string    `json:"source_task_id"`
+	TargetTaskID string    `json:"target_task_id"`
+	Type         string    `json:"type"`
+	CreatedBy    *string   `json:"created_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func ToTaskLinkResponse(l *domain.TaskLink) TaskLinkResponse {
+	resp := TaskLinkResponse{
+		ID:           l.ID.String(),
+		SourceTaskID: l.SourceTaskID.String(),
+		TargetTaskID: l.TargetTaskID.String(),
+		Type:         string(l.Type),
+		CreatedAt:    l.CreatedAt,
+	}
+
+	if l.CreatedBy != nil {
+		createdBy := l.CreatedBy.String()
+		resp.CreatedBy = &createdBy
+	}
+
+	return resp
+}
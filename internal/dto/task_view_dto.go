@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+)
+
+type SaveTaskViewRequest struct {
+	Name             string  `json:"name" validate:"required,min=1,max=255"`
+	Query            string  `json:"query" validate:"max=2000"`
+	SharedDepartment *string `json:"shared_department,omitempty" validate:"omitempty,min=1,max=100"`
+}
+
+type TaskViewResponse struct {
+	ID               string    `json:"id"`
+	OwnerID          string    `json:"owner_id"`
+	Name             string    `json:"name"`
+	Query            string    `json:"query"`
+	SharedDepartment *string   `json:"shared_department,omitempty"`
+	IsDefault        bool      `json:"is_default"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func ToTaskViewResponse(v *domain.TaskView) TaskViewResponse {
+	return TaskViewResponse{
+		ID:               v.ID.String(),
+		OwnerID:          v.OwnerID.String(),
+		Name:             v.Name,
+		Query:            v.Query,
+		SharedDepartment: v.SharedDepartment,
+		IsDefault:        v.IsDefault,
+		CreatedAt:        v.CreatedAt,
+		UpdatedAt:        v.UpdatedAt,
+	}
+}
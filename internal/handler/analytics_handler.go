@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/dto"
+	"github.com/dmitry/taskmanager/internal/service"
+	"github.com/dmitry/taskmanager/pkg/errors"
+)
+
+type AnalyticsHandler struct {
+	service *service.AnalyticsService
+}
+
+func NewAnalyticsHandler(service *service.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		service: service,
+	}
+}
+
+// Burndown возвращает диаграмму сгорания: GET /analytics/burndown?sprint=...&from=...&to=...
+// Принимает те же параметры фильтрации, что и список задач.
+func (h *AnalyticsHandler) Burndown(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseTaskFilter(r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	period, err := parseAnalyticsPeriod(r.URL.Query())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	points, err := h.service.Burndown(r.Context(), filter, period)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.ToBurndownResponse(points))
+}
+
+// CumulativeFlow возвращает диаграмму накопленного потока: GET /analytics/cfd?from=...&to=...
+func (h *AnalyticsHandler) CumulativeFlow(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseTaskFilter(r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	period, err := parseAnalyticsPeriod(r.URL.Query())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	points, err := h.service.CumulativeFlow(r.Context(), filter, period)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.ToCumulativeFlowResponse(points))
+}
+
+// FlowTimes возвращает перцентили lead/cycle time по командам: GET /analytics/flow-times?from=...&to=...
+func (h *AnalyticsHandler) FlowTimes(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseTaskFilter(r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	period, err := parseAnalyticsPeriod(r.URL.Query())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	stats, err := h.service.FlowTimes(r.Context(), filter, period)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.ToFlowTimesResponse(stats))
+}
+
+// parseAnalyticsPeriod разбирает границы периода from и to в формате ГГГГ-ММ-ДД
+func parseAnalyticsPeriod(query url.Values) (service.AnalyticsPeriod, error) {
+	var period service.AnalyticsPeriod
+	for _, p := range []struct {
+		name string
+		dest **time.Time
+	}{
+		{"from", &period.From},
+		{"to", &period.To},
+	} {
+		value := query.Get(p.name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return period, errors.BadRequest("Неверный формат даты в параметре " + p.name + ", ожидается ГГГГ-ММ-ДД")
+		}
+		*p.dest = &t
+	}
+	return period, nil
+}
@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/dmitry/taskmanager/internal/dto"
+	"github.com/dmitry/taskmanager/internal/middleware"
+	"github.com/dmitry/taskmanager/internal/service"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// multipartOverhead - запас на заголовки и служебные поля multipart-запроса
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	service *service.AttachmentService
+}
+
+func NewAttachmentHandler(service *service.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{
+		service: service,
+	}
+}
+
+// UploadAttachment принимает multipart/form-data с полем file и необязательным полем message_id.
+// Поле message_id должно идти до файла: тело читается потоково, без буферизации в памяти.
+func (h *AttachmentHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	uploadedBy, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.service.MaxSizeBytes()+multipartOverhead)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		RespondError(w, errors.BadRequest("Ожидается multipart/form-data"))
+		return
+	}
+
+	var messageID *uuid.UUID
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			RespondError(w, errors.BadRequest("Некорректное тело multipart-запроса"))
+			return
+		}
+
+		switch part.FormName() {
+		case "message_id":
+			value, err := io.ReadAll(io.LimitReader(part, 64))
+			if err != nil {
+				RespondError(w, errors.BadRequest("Некорректное поле message_id"))
+				return
+			}
+			id, err := uuid.Parse(string(value))
+			if err != nil {
+				RespondError(w, errors.BadRequest("Неверный ID сообщения"))
+				return
+			}
+			messageID = &id
+
+		case "file":
+			attachment, err := h.service.Upload(r.Context(), service.UploadAttachmentRequest{
+				TaskID:     taskID,
+				MessageID:  messageID,
+				UploadedBy: uploadedBy,
+				FileName:   part.FileName(),
+				Content:    part,
+			})
+			if err != nil {
+				RespondError(w, err)
+				return
+			}
+
+			RespondJSON(w, http.StatusCreated, dto.ToAttachmentResponse(attachment))
+			return
+		}
+
+		part.Close()
+	}
+
+	RespondError(w, errors.BadRequest("Поле file обязательно"))
+}
+
+func (h *AttachmentHandler) GetTaskAttachments(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	attachments, err := h.service.GetTaskAttachments(r.Context(), taskID, employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	responses := make([]dto.AttachmentResponse, len(attachments))
+	for i, a := range attachments {
+		responses[i] = dto.ToAttachmentResponse(a)
+	}
+
+	RespondJSON(w, http.StatusOK, responses)
+}
+
+// GetDownloadLink выдает аутентифицированному сотруднику временную подписанную ссылку на файл
+func (h *AttachmentHandler) GetDownloadLink(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if _, err := h.service.GetAttachment(r.Context(), id, employeeID); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	signature, expiresAt := h.service.SignDownload(id)
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set("signature", signature)
+
+	RespondJSON(w, http.StatusOK, dto.AttachmentLinkResponse{
+		URL:       fmt.Sprintf("/api/v1/attachments/%s/download?%s", id, query.Encode()),
+		ExpiresAt: expiresAt,
+	})
+}
+
+// DownloadAttachment отдает файл по подписанной ссылке
+func (h *AttachmentHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	if err := h.service.VerifyDownload(id, query.Get("expires"), query.Get("signature")); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	attachment, content, err := h.service.Open(r.Context(), id)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+	defer content.Close()
+
+	// Файл всегда отдается как вложение, чтобы браузер не исполнял загруженный контент
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.SizeBytes, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+	w.Header().Set("ETag", `"`+attachment.Checksum+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	io.Copy(w, content)
+}
+
+func (h *AttachmentHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteAttachment(r.Context(), id, employeeID); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, map[string]string{"message": "Вложение успешно удалено"})
+}
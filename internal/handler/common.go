@@ -3,6 +3,7 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/dmitry/taskmanager/internal/dto"
 	"github.com/dmitry/taskmanager/pkg/errors"
@@ -59,3 +60,20 @@ func DecodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
 	}
 	return true
 }
+
+// WantsHTML сообщает, запросил ли клиент серверный рендеринг Markdown (?render=html)
+func WantsHTML(r *http.Request) bool {
+	return r.URL.Query().Get("render") == "html"
+}
+
+// UsesCursor сообщает, запросил ли клиент keyset-пагинацию (?cursor= или ?limit=)
+func UsesCursor(r *http.Request) bool {
+	query := r.URL.Query()
+	return query.Has("cursor") || query.Has("limit")
+}
+
+// WantsTotal сообщает, нужно ли в режиме курсора считать общее количество записей (?with_total=true)
+func WantsTotal(r *http.Request) bool {
+	withTotal, _ := strconv.ParseBool(r.URL.Query().Get("with_total"))
+	return withTotal
+}
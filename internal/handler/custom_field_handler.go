@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/dto"
+	"github.com/dmitry/taskmanager/internal/middleware"
+	"github.com/dmitry/taskmanager/internal/service"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/validator"
+	"github.com/google/uuid"
+)
+
+type CustomFieldHandler struct {
+	service   *service.CustomFieldService
+	tasks     *TaskHandler
+	validator *validator.Validator
+}
+
+func NewCustomFieldHandler(service *service.CustomFieldService, tasks *TaskHandler, validator *validator.Validator) *CustomFieldHandler {
+	return &CustomFieldHandler{
+		service:   service,
+		tasks:     tasks,
+		validator: validator,
+	}
+}
+
+func (h *CustomFieldHandler) GetFields(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	fields, err := h.service.GetFields(r.Context(), employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	responses := make([]dto.CustomFieldResponse, len(fields))
+	for i, f := range fields {
+		responses[i] = dto.ToCustomFieldResponse(f)
+	}
+
+	RespondJSON(w, http.StatusOK, responses)
+}
+
+func (h *CustomFieldHandler) CreateField(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateCustomFieldRequest
+	if !DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	var projectID *uuid.UUID
+	if req.ProjectID != "" {
+		id, err := uuid.Parse(req.ProjectID)
+		if err != nil {
+			RespondError(w, errors.BadRequest("Неверный ID проекта"))
+			return
+		}
+		projectID = &id
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	field, err := h.service.CreateField(r.Context(), service.CreateCustomFieldRequest{
+		ProjectID: projectID,
+		Key:       req.Key,
+		Name:      req.Name,
+		Type:      domain.CustomFieldType(req.Type),
+		Options:   req.Options,
+		CreatedBy: employeeID,
+	})
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusCreated, dto.ToCustomFieldResponse(field))
+}
+
+func (h *CustomFieldHandler) UpdateField(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateCustomFieldRequest
+	if !DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	field, err := h.service.UpdateField(r.Context(), id, employeeID, req.Name, req.Options)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.ToCustomFieldResponse(field))
+}
+
+func (h *CustomFieldHandler) DeleteField(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteField(r.Context(), id, employeeID); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, map[string]string{"message": "Поле успешно удалено"})
+}
+
+// SetTaskFields задаёт значения полей задачи: {"story_points": 5, "release": null};
+// null удаляет значение. Возвращает обновлённую задачу.
+func (h *CustomFieldHandler) SetTaskFields(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var values map[string]interface{}
+	if !DecodeJSON(w, r, &values) {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	task, err := h.service.SetTaskValues(r.Context(), taskID, employeeID, values)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	resp, err := h.tasks.toTaskResponse(r, task)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, resp)
+}
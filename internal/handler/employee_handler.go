@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/dmitry/taskmanager/internal/domain"
 	"github.com/dmitry/taskmanager/internal/dto"
 	"github.com/dmitry/taskmanager/internal/repository"
 	"github.com/dmitry/taskmanager/internal/service"
@@ -60,6 +61,7 @@ func (h *EmployeeHandler) GetEmployee(w http.ResponseWriter, r *http.Request) {
 func (h *EmployeeHandler) GetAllEmployees(w http.ResponseWriter, r *http.Request) {
 	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
 	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
 	department := r.URL.Query().Get("department")
 
 	if page < 1 {
@@ -73,6 +75,29 @@ func (h *EmployeeHandler) GetAllEmployees(w http.ResponseWriter, r *http.Request
 		Department: department,
 		Page:       page,
 		PageSize:   pageSize,
+		Cursor:     r.URL.Query().Get("cursor"),
+		Limit:      repository.NormalizeCursorLimit(limit),
+	}
+
+	if UsesCursor(r) {
+		employees, nextCursor, err := h.service.GetAllEmployeesByCursor(r.Context(), filter)
+		if err != nil {
+			RespondError(w, err)
+			return
+		}
+
+		var total *int
+		if WantsTotal(r) {
+			count, err := h.service.CountEmployees(r.Context(), filter)
+			if err != nil {
+				RespondError(w, err)
+				return
+			}
+			total = &count
+		}
+
+		RespondJSON(w, http.StatusOK, dto.NewCursorResponse(toEmployeeResponses(employees), filter.Limit, nextCursor, total))
+		return
 	}
 
 	employees, total, err := h.service.GetAllEmployees(r.Context(), filter)
@@ -81,20 +106,7 @@ func (h *EmployeeHandler) GetAllEmployees(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	responses := make([]dto.EmployeeResponse, len(employees))
-	for i, emp := range employees {
-		responses[i] = dto.ToEmployeeResponse(emp)
-	}
-
-	totalPages := (total + pageSize - 1) / pageSize
-
-	RespondJSON(w, http.StatusOK, dto.PaginatedResponse{
-		Data:       responses,
-		Total:      total,
-		Page:       page,
-		PageSize:   pageSize,
-		TotalPages: totalPages,
-	})
+	RespondJSON(w, http.StatusOK, dto.NewPagedResponse(toEmployeeResponses(employees), total, page, pageSize))
 }
 
 func (h *EmployeeHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request) {
@@ -145,3 +157,11 @@ func (h *EmployeeHandler) DeleteEmployee(w http.ResponseWriter, r *http.Request)
 
 	RespondJSON(w, http.StatusOK, map[string]string{"message": "Сотрудник успешно удалён"})
 }
+
+func toEmployeeResponses(employees []*domain.Employee) []dto.EmployeeResponse {
+	responses := make([]dto.EmployeeResponse, len(employees))
+	for i, emp := range employees {
+		responses[i] = dto.ToEmployeeResponse(emp)
+	}
+	return responses
+}
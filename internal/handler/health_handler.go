@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dmitry/taskmanager/pkg/buildinfo"
+)
+
+// Pinger - зависимость, доступность которой проверяет /readyz
+type Pinger interface {
+	HealthCheck(ctx context.Context) error
+}
+
+type HealthHandler struct {
+	dependencies map[string]Pinger
+	timeout      time.Duration
+	startedAt    time.Time
+	shuttingDown atomic.Bool
+}
+
+// NewHealthHandler создаёт проверки работоспособности; timeout ограничивает проверку
+// каждой зависимости
+func NewHealthHandler(dependencies map[string]Pinger, timeout time.Duration) *HealthHandler {
+	return &HealthHandler{
+		dependencies: dependencies,
+		timeout:      timeout,
+		startedAt:    time.Now(),
+	}
+}
+
+// SetShuttingDown переводит сервис в состояние завершения: /readyz начинает отвечать 503,
+// чтобы балансировщик перестал направлять новые запросы
+func (h *HealthHandler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+type healthResponse struct {
+	Status        string                      `json:"status"`
+	Service       string                      `json:"service"`
+	Build         buildinfo.Info              `json:"build"`
+	UptimeSeconds int64                       `json:"uptime_seconds"`
+	Checks        map[string]dependencyStatus `json:"checks,omitempty"`
+}
+
+type dependencyStatus struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Liveness отвечает, что процесс жив; зависимости не проверяются, чтобы недоступность
+// базы данных не приводила к перезапуску сервиса
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	h.respond(w, http.StatusOK, healthResponse{Status: "ok"})
+}
+
+// Readiness проверяет зависимости параллельно и отвечает 503, если хотя бы одна недоступна
+// или сервис завершает работу
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	checks := make(map[string]dependencyStatus, len(h.dependencies))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, dependency := range h.dependencies {
+		wg.Add(1)
+		go func(name string, dependency Pinger) {
+			defer wg.Done()
+			status := h.check(r.Context(), dependency)
+			mu.Lock()
+			checks[name] = status
+			mu.Unlock()
+		}(name, dependency)
+	}
+	wg.Wait()
+
+	resp := healthResponse{Status: "ok", Checks: checks}
+	code := http.StatusOK
+	for _, check := range checks {
+		if check.Status != "ok" {
+			resp.Status = "unavailable"
+			code = http.StatusServiceUnavailable
+		}
+	}
+	if h.shuttingDown.Load() {
+		resp.Status = "shutting_down"
+		code = http.StatusServiceUnavailable
+	}
+
+	h.respond(w, code, resp)
+}
+
+func (h *HealthHandler) check(ctx context.Context, dependency Pinger) dependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := dependency.HealthCheck(ctx)
+	status := dependencyStatus{
+		Status:    "ok",
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		status.Status = "error"
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// respond пишет ответ без обёртки success/data: формат проверок ожидают оркестраторы и мониторинг
+func (h *HealthHandler) respond(w http.ResponseWriter, code int, resp healthResponse) {
+	resp.Service = "taskmanager"
+	resp.Build = buildinfo.Get()
+	resp.UptimeSeconds = int64(time.Since(h.startedAt).Seconds())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmitry/taskmanager/internal/dto"
+	"github.com/dmitry/taskmanager/internal/middleware"
+	"github.com/dmitry/taskmanager/internal/service"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/validator"
+	"github.com/google/uuid"
+)
+
+type LabelHandler struct {
+	service   *service.LabelService
+	validator *validator.Validator
+}
+
+func NewLabelHandler(service *service.LabelService, validator *validator.Validator) *LabelHandler {
+	return &LabelHandler{
+		service:   service,
+		validator: validator,
+	}
+}
+
+func (h *LabelHandler) GetLabels(w http.ResponseWriter, r *http.Request) {
+	labels, err := h.service.GetLabels(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	responses := make([]dto.LabelResponse, len(labels))
+	for i, l := range labels {
+		responses[i] = dto.ToLabelResponse(l)
+	}
+
+	RespondJSON(w, http.StatusOK, responses)
+}
+
+func (h *LabelHandler) CreateLabel(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateLabelRequest
+	if !DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	label, err := h.service.CreateLabel(r.Context(), service.SaveLabelRequest{
+		Name:  req.Name,
+		Color: req.Color,
+	})
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusCreated, dto.ToLabelResponse(label))
+}
+
+func (h *LabelHandler) UpdateLabel(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateLabelRequest
+	if !DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	label, err := h.service.UpdateLabel(r.Context(), id, service.SaveLabelRequest{
+		Name:  req.Name,
+		Color: req.Color,
+	})
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.ToLabelResponse(label))
+}
+
+func (h *LabelHandler) DeleteLabel(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteLabel(r.Context(), id); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, map[string]string{"message": "Метка успешно удалена"})
+}
+
+func (h *LabelHandler) AddTaskLabel(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.AddTaskLabelRequest
+	if !DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	labelID, err := uuid.Parse(req.LabelID)
+	if err != nil {
+		RespondError(w, errors.BadRequest("Неверный ID метки"))
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if err := h.service.AddLabelToTask(r.Context(), taskID, labelID, employeeID); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, map[string]string{"message": "Метка добавлена к задаче"})
+}
+
+func (h *LabelHandler) RemoveTaskLabel(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	labelID, ok := ParseUUID(w, r, "labelId")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if err := h.service.RemoveLabelFromTask(r.Context(), taskID, labelID, employeeID); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, map[string]string{"message": "Метка снята с задачи"})
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/dto"
+	"github.com/dmitry/taskmanager/internal/middleware"
+	"github.com/dmitry/taskmanager/internal/service"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/markdown"
+	"github.com/dmitry/taskmanager/pkg/validator"
+)
+
+type MessageHandler struct {
+	service   *service.MessageService
+	renderer  *markdown.Renderer
+	validator *validator.Validator
+}
+
+func NewMessageHandler(service *service.MessageService, renderer *markdown.Renderer, validator *validator.Validator) *MessageHandler {
+	return &MessageHandler{
+		service:   service,
+		renderer:  renderer,
+		validator: validator,
+	}
+}
+
+func (h *MessageHandler) GetTaskMessages(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	messages, err := h.service.GetTaskMessages(r.Context(), taskID, employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	responses := make([]dto.MessageResponse, len(messages))
+	for i, m := range messages {
+		resp, err := h.toMessageResponse(r, m)
+		if err != nil {
+			RespondError(w, err)
+			return
+		}
+		responses[i] = resp
+	}
+
+	RespondJSON(w, http.StatusOK, responses)
+}
+
+func (h *MessageHandler) CreateMessage(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.CreateMessageRequest
+	if !DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	authorID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	message, err := h.service.CreateMessage(r.Context(), taskID, authorID, req.Content)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	resp, err := h.toMessageResponse(r, message)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusCreated, resp)
+}
+
+func (h *MessageHandler) UpdateMessage(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateMessageRequest
+	if !DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	editorID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	message, err := h.service.UpdateMessage(r.Context(), id, editorID, req.Content)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	resp, err := h.toMessageResponse(r, message)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, resp)
+}
+
+func (h *MessageHandler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	editorID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteMessage(r.Context(), id, editorID); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, map[string]string{"message": "Сообщение успешно удалено"})
+}
+
+// toMessageResponse преобразует сообщение в DTO и при ?render=html добавляет очищенный HTML
+func (h *MessageHandler) toMessageResponse(r *http.Request, m *domain.TaskMessage) (dto.MessageResponse, error) {
+	resp := dto.ToMessageResponse(m)
+
+	if WantsHTML(r) {
+		html, err := h.renderer.Render(m.Content)
+		if err != nil {
+			return resp, errors.Internal(err, "Не удалось отрендерить сообщение")
+		}
+		resp.ContentHTML = html
+	}
+
+	return resp, nil
+}
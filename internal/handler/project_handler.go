@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/dmitry/taskmanager/internal/dto"
+	"github.com/dmitry/taskmanager/internal/middleware"
+	"github.com/dmitry/taskmanager/internal/service"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/validator"
+	"github.com/google/uuid"
+)
+
+type ProjectHandler struct {
+	service   *service.ProjectService
+	tasks     *TaskHandler
+	validator *validator.Validator
+}
+
+func NewProjectHandler(service *service.ProjectService, tasks *TaskHandler, validator *validator.Validator) *ProjectHandler {
+	return &ProjectHandler{
+		service:   service,
+		tasks:     tasks,
+		validator: validator,
+	}
+}
+
+func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateProjectRequest
+	if !DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	ownerID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	project, err := h.service.CreateProject(r.Context(), strings.ToUpper(req.Key), req.Name, req.Description, ownerID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusCreated, dto.ToProjectResponse(project))
+}
+
+func (h *ProjectHandler) GetProjects(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	projects, err := h.service.GetProjects(r.Context(), employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	responses := make([]dto.ProjectResponse, len(projects))
+	for i, p := range projects {
+		responses[i] = dto.ToProjectResponse(p)
+	}
+
+	RespondJSON(w, http.StatusOK, responses)
+}
+
+func (h *ProjectHandler) GetProject(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	project, err := h.service.GetProject(r.Context(), id, employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.ToProjectResponse(project))
+}
+
+func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateProjectRequest
+	if !DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	editorID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	project, err := h.service.UpdateProject(r.Context(), id, editorID, req.Name, req.Description)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.ToProjectResponse(project))
+}
+
+func (h *ProjectHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	editorID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteProject(r.Context(), id, editorID); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, map[string]string{"message": "Проект успешно удалён"})
+}
+
+func (h *ProjectHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	members, err := h.service.GetMembers(r.Context(), id, employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	responses := make([]dto.ProjectMemberResponse, len(members))
+	for i, m := range members {
+		responses[i] = dto.ToProjectMemberResponse(m)
+	}
+
+	RespondJSON(w, http.StatusOK, responses)
+}
+
+func (h *ProjectHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.AddProjectMemberRequest
+	if !DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	employeeID, err := uuid.Parse(req.EmployeeID)
+	if err != nil {
+		RespondError(w, errors.BadRequest("Неверный ID сотрудника"))
+		return
+	}
+
+	editorID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if err := h.service.AddMember(r.Context(), id, editorID, employeeID); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusCreated, map[string]string{"message": "Участник добавлен в проект"})
+}
+
+func (h *ProjectHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, ok := ParseUUID(w, r, "employeeId")
+	if !ok {
+		return
+	}
+
+	editorID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if err := h.service.RemoveMember(r.Context(), id, editorID, employeeID); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, map[string]string{"message": "Участник исключён из проекта"})
+}
+
+// GetProjectTasks возвращает задачи проекта; поддерживает те же параметры, что и GET /tasks
+func (h *ProjectHandler) GetProjectTasks(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if _, err := h.service.GetProject(r.Context(), id, employeeID); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	filter, err := parseTaskFilter(r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+	filter.ProjectID = &id
+
+	h.tasks.respondTaskList(w, r, filter)
+}
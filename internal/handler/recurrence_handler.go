@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmitry/taskmanager/internal/dto"
+	"github.com/dmitry/taskmanager/internal/middleware"
+	"github.com/dmitry/taskmanager/internal/service"
+	"github.com/dmitry/taskmanager/pkg/validator"
+)
+
+type RecurrenceHandler struct {
+	service   *service.RecurrenceService
+	validator *validator.Validator
+}
+
+func NewRecurrenceHandler(service *service.RecurrenceService, validator *validator.Validator) *RecurrenceHandler {
+	return &RecurrenceHandler{
+		service:   service,
+		validator: validator,
+	}
+}
+
+func (h *RecurrenceHandler) GetRecurrence(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	recurrence, err := h.service.GetRecurrence(r.Context(), taskID, employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.ToRecurrenceResponse(recurrence))
+}
+
+// SetRecurrence задаёт или заменяет расписание повторения задачи
+func (h *RecurrenceHandler) SetRecurrence(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetRecurrenceRequest
+	if !DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	recurrence, err := h.service.SetRecurrence(r.Context(), taskID, employeeID, service.SetRecurrenceRequest{
+		Rule:     req.Rule,
+		StartsAt: req.StartsAt,
+		Timezone: req.Timezone,
+	})
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.ToRecurrenceResponse(recurrence))
+}
+
+func (h *RecurrenceHandler) DeleteRecurrence(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteRecurrence(r.Context(), taskID, employeeID); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, map[string]string{"message": "Расписание повторения удалено"})
+}
@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/middleware"
+	"github.com/dmitry/taskmanager/internal/service"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/logger"
+	"github.com/dmitry/taskmanager/pkg/xlsx"
+)
+
+// exportWriteTimeout - время на выгрузку файла; общий WriteTimeout сервера для неё слишком мал
+const exportWriteTimeout = 10 * time.Minute
+
+var timesheetColumns = []string{"Сотрудник", "Отдел", "Задача", "Название задачи", "Дата", "Часы"}
+
+type ReportHandler struct {
+	timeEntries *service.TimeEntryService
+}
+
+func NewReportHandler(timeEntries *service.TimeEntryService) *ReportHandler {
+	return &ReportHandler{
+		timeEntries: timeEntries,
+	}
+}
+
+// Timesheet выгружает табель: GET /reports/timesheet?employee_id=&department=&from=&to=&format=csv|xlsx
+func (h *ReportHandler) Timesheet(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	employeeID, err := uuidParam(query, "employee_id")
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	var department *string
+	if value := query.Get("department"); value != "" {
+		department = &value
+	}
+
+	period, err := parseAnalyticsPeriod(query)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	viewerID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	var writer timesheetExport
+	switch format := query.Get("format"); format {
+	case "", "csv":
+		writer = &csvTimesheetWriter{w: w}
+	case "xlsx":
+		writer = &xlsxTimesheetWriter{w: w}
+	default:
+		RespondError(w, errors.BadRequest("Неверный формат выгрузки: "+format+", ожидается csv или xlsx"))
+		return
+	}
+
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(exportWriteTimeout)); err != nil {
+		logger.FromContext(r.Context()).Warn("Не удалось продлить время записи ответа", "error", err)
+	}
+
+	req := service.TimesheetRequest{
+		EmployeeID: employeeID,
+		Department: department,
+		Period:     period,
+	}
+	if err := h.timeEntries.Timesheet(r.Context(), viewerID, req, writer); err != nil {
+		// После начала выгрузки ответ об ошибке уже не отправить: файл обрывается
+		if writer.Started() {
+			logger.FromContext(r.Context()).Error("Выгрузка табеля прервана", "error", err)
+			return
+		}
+		RespondError(w, err)
+	}
+}
+
+// setAttachmentHeaders объявляет ответ файлом для скачивания
+func setAttachmentHeaders(w http.ResponseWriter, contentType, filename string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.WriteHeader(http.StatusOK)
+}
+
+// timesheetExport - выгрузка табеля в файл; Started сообщает, отправлено ли уже начало файла
+type timesheetExport interface {
+	service.TimesheetWriter
+	Started() bool
+}
+
+func formatHours(hours float64) string {
+	return strconv.FormatFloat(hours, 'f', -1, 64)
+}
+
+func timesheetTaskKey(row *domain.TimesheetRow) string {
+	if row.TaskKey != nil {
+		return *row.TaskKey
+	}
+	return row.TaskID.String()
+}
+
+// csvTimesheetWriter пишет табель в CSV; заголовки ответа отправляются при первой строке
+type csvTimesheetWriter struct {
+	w   http.ResponseWriter
+	csv *csv.Writer
+}
+
+func (c *csvTimesheetWriter) Started() bool {
+	return c.csv != nil
+}
+
+func (c *csvTimesheetWriter) write(record []string) error {
+	if c.csv == nil {
+		setAttachmentHeaders(c.w, "text/csv; charset=utf-8", "timesheet.csv")
+		// BOM нужен Excel, чтобы распознать UTF-8
+		if _, err := c.w.Write([]byte("\ufeff")); err != nil {
+			return err
+		}
+		c.csv = csv.NewWriter(c.w)
+		if err := c.csv.Write(timesheetColumns); err != nil {
+			return err
+		}
+	}
+	return c.csv.Write(record)
+}
+
+func (c *csvTimesheetWriter) Row(row *domain.TimesheetRow) error {
+	return c.write([]string{
+		csvText(row.EmployeeName), csvText(row.Department), csvText(timesheetTaskKey(row)), csvText(row.TaskTitle),
+		row.Date.Format("2006-01-02"), formatHours(row.Hours),
+	})
+}
+
+func (c *csvTimesheetWriter) EmployeeTotal(employeeName, department string, hours float64) error {
+	return c.write([]string{csvText(employeeName), csvText(department), "", "Итого по сотруднику", "", formatHours(hours)})
+}
+
+// csvText защищает от CSV-инъекции: текст, который Excel принял бы за формулу
+// (начинается с =, +, -, @, табуляции или перевода каретки), предваряется апострофом
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func (c *csvTimesheetWriter) Total(hours float64) error {
+	if err := c.write([]string{"Итого", "", "", "", "", formatHours(hours)}); err != nil {
+		return err
+	}
+	c.csv.Flush()
+	return c.csv.Error()
+}
+
+// xlsxTimesheetWriter пишет табель в книгу Excel; заголовки ответа отправляются при первой строке
+type xlsxTimesheetWriter struct {
+	w    http.ResponseWriter
+	book *xlsx.Writer
+}
+
+func (x *xlsxTimesheetWriter) Started() bool {
+	return x.book != nil
+}
+
+func (x *xlsxTimesheetWriter) write(cells ...xlsx.Cell) error {
+	if x.book == nil {
+		setAttachmentHeaders(x.w, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "timesheet.xlsx")
+		book, err := xlsx.NewWriter(x.w, "Табель")
+		if err != nil {
+			return err
+		}
+		x.book = book
+
+		header := make([]xlsx.Cell, len(timesheetColumns))
+		for i, column := range timesheetColumns {
+			header[i] = xlsx.Cell{Value: column, Bold: true}
+		}
+		if err := x.book.WriteRow(header...); err != nil {
+			return err
+		}
+	}
+	return x.book.WriteRow(cells...)
+}
+
+func (x *xlsxTimesheetWriter) Row(row *domain.TimesheetRow) error {
+	return x.write(
+		xlsx.Cell{Value: row.EmployeeName},
+		xlsx.Cell{Value: row.Department},
+		xlsx.Cell{Value: timesheetTaskKey(row)},
+		xlsx.Cell{Value: row.TaskTitle},
+		xlsx.Cell{Value: row.Date.Format("2006-01-02")},
+		xlsx.Cell{Value: row.Hours},
+	)
+}
+
+func (x *xlsxTimesheetWriter) EmployeeTotal(employeeName, department string, hours float64) error {
+	return x.write(
+		xlsx.Cell{Value: employeeName, Bold: true},
+		xlsx.Cell{Value: department, Bold: true},
+		xlsx.Cell{},
+		xlsx.Cell{Value: "Итого по сотруднику", Bold: true},
+		xlsx.Cell{},
+		xlsx.Cell{Value: hours, Bold: true},
+	)
+}
+
+func (x *xlsxTimesheetWriter) Total(hours float64) error {
+	if err := x.write(
+		xlsx.Cell{Value: "Итого", Bold: true},
+		xlsx.Cell{}, xlsx.Cell{}, xlsx.Cell{}, xlsx.Cell{},
+		xlsx.Cell{Value: hours, Bold: true},
+	); err != nil {
+		return err
+	}
+	return x.book.Close()
+}
@@ -0,0 +1,24 @@
+package handler
+
+import "testing"
+
+func TestCSVText(t *testing.T) {
+	tests := map[string]string{
+		"":                           "",
+		"Отчёт за март":              "Отчёт за март",
+		"CORE-12":                    "CORE-12",
+		`=HYPERLINK("http://x","y")`: `'=HYPERLINK("http://x","y")`,
+		"+cmd|' /C calc'!A0":         "'+cmd|' /C calc'!A0",
+		"-2+3":                       "'-2+3",
+		"@SUM(A1:A2)":                "'@SUM(A1:A2)",
+		"\t=1":                       "'\t=1",
+		"\r=1":                       "'\r=1",
+		"a=1":                        "a=1",
+	}
+
+	for value, want := range tests {
+		if got := csvText(value); got != want {
+			t.Errorf("csvText(%q) = %q, ожидалось %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmitry/taskmanager/internal/dto"
+	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/internal/service"
+)
+
+type SearchHandler struct {
+	service *service.SearchService
+}
+
+func NewSearchHandler(service *service.SearchService) *SearchHandler {
+	return &SearchHandler{
+		service: service,
+	}
+}
+
+// Search выполняет полнотекстовый поиск: GET /search?q=...
+// Принимает те же параметры фильтрации, что и список задач; результаты упорядочены по релевантности.
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	taskFilter, err := parseTaskFilter(r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	filter := repository.SearchFilter{
+		Query:      r.URL.Query().Get("q"),
+		TaskFilter: taskFilter,
+	}
+
+	results, total, err := h.service.Search(r.Context(), filter)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	responses := make([]dto.SearchResultResponse, len(results))
+	for i, result := range results {
+		responses[i] = dto.ToSearchResultResponse(result)
+	}
+
+	RespondJSON(w, http.StatusOK, dto.NewPagedResponse(responses, total, filter.Page, filter.PageSize))
+}
@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/dto"
+	"github.com/dmitry/taskmanager/internal/middleware"
+	"github.com/dmitry/taskmanager/internal/service"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/validator"
+)
+
+type SprintHandler struct {
+	service   *service.SprintService
+	tasks     *TaskHandler
+	validator *validator.Validator
+}
+
+func NewSprintHandler(service *service.SprintService, tasks *TaskHandler, validator *validator.Validator) *SprintHandler {
+	return &SprintHandler{
+		service:   service,
+		tasks:     tasks,
+		validator: validator,
+	}
+}
+
+func (h *SprintHandler) CreateSprint(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.SaveSprintRequest
+	if !DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	creatorID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	sprint, err := h.service.CreateSprint(r.Context(), projectID, creatorID, toSaveSprintRequest(req))
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusCreated, dto.ToSprintResponse(sprint))
+}
+
+// GetSprints возвращает спринты проекта; параметр state отбирает спринты в одном состоянии
+func (h *SprintHandler) GetSprints(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var state *domain.SprintState
+	if value := r.URL.Query().Get("state"); value != "" {
+		parsed := domain.SprintState(value)
+		if !parsed.IsValid() {
+			RespondError(w, errors.BadRequest("Неверное состояние спринта: "+value))
+			return
+		}
+		state = &parsed
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	sprints, err := h.service.GetSprints(r.Context(), projectID, employeeID, state)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	responses := make([]dto.SprintResponse, len(sprints))
+	for i, s := range sprints {
+		responses[i] = dto.ToSprintResponse(s)
+	}
+
+	RespondJSON(w, http.StatusOK, responses)
+}
+
+func (h *SprintHandler) GetSprint(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	sprint, err := h.service.GetSprint(r.Context(), id, employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.ToSprintResponse(sprint))
+}
+
+func (h *SprintHandler) UpdateSprint(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.SaveSprintRequest
+	if !DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	editorID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	sprint, err := h.service.UpdateSprint(r.Context(), id, editorID, toSaveSprintRequest(req))
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.ToSprintResponse(sprint))
+}
+
+func (h *SprintHandler) DeleteSprint(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	editorID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteSprint(r.Context(), id, editorID); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, map[string]string{"message": "Спринт успешно удалён"})
+}
+
+func (h *SprintHandler) StartSprint(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	editorID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	sprint, err := h.service.StartSprint(r.Context(), id, editorID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.ToSprintResponse(sprint))
+}
+
+func (h *SprintHandler) CloseSprint(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.CloseSprintRequest
+	if !DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	moveTo, err := parseOptionalUUID(req.MoveToSprintID, "Неверный ID спринта")
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	editorID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	moved, err := h.service.CloseSprint(r.Context(), id, editorID, moveTo)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.CloseSprintResponse{
+		Message:    "Спринт закрыт",
+		MovedTasks: moved,
+	})
+}
+
+func (h *SprintHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	report, err := h.service.GetReport(r.Context(), id, employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.ToSprintReportResponse(report))
+}
+
+// GetSprintTasks возвращает задачи спринта; поддерживает те же параметры, что и GET /tasks
+func (h *SprintHandler) GetSprintTasks(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if _, err := h.service.GetSprint(r.Context(), id, employeeID); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	filter, err := parseTaskFilter(r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+	filter.SprintID = &id
+	filter.Backlog = false
+
+	h.tasks.respondTaskList(w, r, filter)
+}
+
+func (h *SprintHandler) SetTaskSprint(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetTaskSprintRequest
+	if !DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	sprintID, err := parseOptionalUUID(req.SprintID, "Неверный ID спринта")
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if err := h.service.SetTaskSprint(r.Context(), taskID, employeeID, sprintID); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, map[string]string{"message": "Спринт задачи успешно изменён"})
+}
+
+func toSaveSprintRequest(req dto.SaveSprintRequest) service.SaveSprintRequest {
+	return service.SaveSprintRequest{
+		Name:      req.Name,
+		Goal:      req.Goal,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+	}
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/dto"
+	"github.com/dmitry/taskmanager/internal/middleware"
+	"github.com/dmitry/taskmanager/internal/service"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// GetBoard возвращает доску по тем же фильтрам, что и список задач; limit ограничивает
+// число задач в каждой колонке
+func (h *TaskHandler) GetBoard(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseTaskFilter(r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	columns, err := h.service.GetBoard(r.Context(), filter, filter.Limit)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	resp := dto.BoardResponse{Columns: make([]dto.BoardColumnResponse, len(columns))}
+	for i, column := range columns {
+		tasks, err := h.toTaskResponses(r, column.Tasks)
+		if err != nil {
+			RespondError(w, err)
+			return
+		}
+		resp.Columns[i] = dto.BoardColumnResponse{
+			Status:   string(column.Status),
+			WIPLimit: column.WIPLimit,
+			Total:    column.Total,
+			Tasks:    tasks,
+		}
+	}
+
+	RespondJSON(w, http.StatusOK, resp)
+}
+
+func (h *TaskHandler) MoveTask(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.MoveTaskRequest
+	if !DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	move := service.MoveTaskRequest{
+		TaskID:     id,
+		EmployeeID: employeeID,
+		Status:     domain.TaskStatus(req.Status),
+	}
+	if move.AfterID, err = parseOptionalUUID(req.AfterID, "Неверный ID задачи after_id"); err != nil {
+		RespondError(w, err)
+		return
+	}
+	if move.BeforeID, err = parseOptionalUUID(req.BeforeID, "Неверный ID задачи before_id"); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	warnings, err := h.service.MoveTask(r.Context(), move)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	task, err := h.service.GetTask(r.Context(), id, employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	resp, err := h.toTaskResponse(r, task)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.MoveTaskResponse{Task: resp, Warnings: warnings})
+}
+
+func parseOptionalUUID(value *string, message string) (*uuid.UUID, error) {
+	if value == nil {
+		return nil, nil
+	}
+	parsed, err := uuid.Parse(*value)
+	if err != nil {
+		return nil, errors.BadRequest(message)
+	}
+	return &parsed, nil
+}
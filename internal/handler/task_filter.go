@@ -0,0 +1,294 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/middleware"
+	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// parseTaskFilter разбирает параметры фильтрации, сортировки и пагинации списка задач:
+//
+//	status=new,in_progress       несколько статусов (через запятую или повтором параметра)
+//	priority=2                   точный приоритет
+//	priority_min=1&priority_max=2
+//	archived=false
+//	created_by=<uuid|me>
+//	participant=<uuid|me>&participant_role=executor
+//	project=<uuid>               задачи проекта
+//	parent=<uuid|none>           подзадачи указанной задачи или только корневые задачи
+//	sprint=<uuid|none>           задачи спринта или бэклог (задачи вне спринтов)
+//	label=bug,feature            задачи хотя бы с одной из меток
+//	label_all=bug,urgent         задачи со всеми указанными метками
+//	cf.<ключ>=a,b                значение пользовательского поля равно одному из значений
+//	cf.<ключ>.min, cf.<ключ>.max диапазон значений числового поля или поля-даты
+//	due_from, due_to, created_from, created_to, updated_from, updated_to
+//	                             дата ГГГГ-ММ-ДД (включительно) или RFC 3339
+//	text=подстрока               поиск подстроки в названии и описании
+//	sort=priority,-due_date      поля сортировки, "-" - по убыванию; cf.<ключ> - по полю
+//	page, page_size              постраничная навигация
+//	cursor, limit                навигация курсором (см. UsesCursor)
+//
+// Значение "me" в created_by и participant заменяется текущим сотрудником.
+// Выборка всегда ограничена задачами, доступными текущему сотруднику (см. TaskFilter.VisibleTo).
+func parseTaskFilter(r *http.Request) (repository.TaskFilter, error) {
+	me, _ := middleware.GetEmployeeIDFromContext(r.Context())
+	return parseTaskFilterQuery(r.URL.Query(), me)
+}
+
+// parseTaskFilterQuery разбирает параметры списка задач из готового набора значений;
+// me - сотрудник, подставляемый вместо "me" (uuid.Nil, если он неизвестен)
+func parseTaskFilterQuery(query url.Values, me uuid.UUID) (repository.TaskFilter, error) {
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	pageSize, _ := strconv.Atoi(query.Get("page_size"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	filter := repository.TaskFilter{
+		Page:     page,
+		PageSize: pageSize,
+		Text:     strings.TrimSpace(query.Get("text")),
+		Cursor:   query.Get("cursor"),
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	filter.Limit = repository.NormalizeCursorLimit(limit)
+
+	for _, value := range listParam(query, "status") {
+		status := domain.TaskStatus(value)
+		if !status.IsValid() {
+			return filter, errors.BadRequest("Неверный статус задачи: " + value)
+		}
+		filter.Status = append(filter.Status, status)
+	}
+
+	var err error
+	if filter.Priority, err = intParam(query, "priority"); err != nil {
+		return filter, err
+	}
+	if filter.PriorityMin, err = intParam(query, "priority_min"); err != nil {
+		return filter, err
+	}
+	if filter.PriorityMax, err = intParam(query, "priority_max"); err != nil {
+		return filter, err
+	}
+
+	if value := query.Get("archived"); value != "" {
+		archived, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, errors.BadRequest("Неверное значение archived")
+		}
+		filter.Archived = &archived
+	}
+
+	if filter.CreatedBy, err = employeeParam(query, "created_by", me); err != nil {
+		return filter, err
+	}
+	if filter.ParticipantID, err = employeeParam(query, "participant", me); err != nil {
+		return filter, err
+	}
+
+	if filter.ProjectID, err = uuidParam(query, "project"); err != nil {
+		return filter, err
+	}
+
+	if query.Get("parent") == "none" {
+		filter.TopLevel = true
+	} else if filter.ParentID, err = uuidParam(query, "parent"); err != nil {
+		return filter, err
+	}
+
+	if query.Get("sprint") == "none" {
+		filter.Backlog = true
+	} else if filter.SprintID, err = uuidParam(query, "sprint"); err != nil {
+		return filter, err
+	}
+
+	filter.LabelsAny = listParam(query, "label")
+	filter.LabelsAll = listParam(query, "label_all")
+
+	if filter.CustomFields, err = customFieldParams(query); err != nil {
+		return filter, err
+	}
+
+	if me != uuid.Nil {
+		filter.VisibleTo = &me
+	}
+
+	if value := query.Get("participant_role"); value != "" {
+		role := domain.ParticipantRole(value)
+		if !role.IsValid() {
+			return filter, errors.BadRequest("Неверная роль участника: " + value)
+		}
+		filter.ParticipantRole = &role
+	}
+
+	timeParams := []struct {
+		name  string
+		dest  **time.Time
+		upper bool
+	}{
+		{"due_from", &filter.DueFrom, false},
+		{"due_to", &filter.DueTo, true},
+		{"created_from", &filter.CreatedFrom, false},
+		{"created_to", &filter.CreatedTo, true},
+		{"updated_from", &filter.UpdatedFrom, false},
+		{"updated_to", &filter.UpdatedTo, true},
+	}
+	for _, p := range timeParams {
+		if *p.dest, err = timeParam(query, p.name, p.upper); err != nil {
+			return filter, err
+		}
+	}
+
+	for _, value := range listParam(query, "sort") {
+		field := repository.SortField{Field: value}
+		if strings.HasPrefix(value, "-") {
+			field = repository.SortField{Field: value[1:], Desc: true}
+		}
+		if !repository.IsTaskSortField(field.Field) && !isCustomFieldSort(field.Field) {
+			return filter, errors.BadRequest("Недопустимое поле сортировки: " + field.Field)
+		}
+		filter.Sort = append(filter.Sort, field)
+	}
+
+	return filter, nil
+}
+
+// customFieldParams собирает условия по пользовательским полям из параметров cf.*;
+// определения полей и значения проверяет сервис
+func customFieldParams(query url.Values) ([]repository.CustomFieldFilter, error) {
+	byKey := map[string]*repository.CustomFieldFilter{}
+	keys := []string{}
+
+	for name := range query {
+		if !strings.HasPrefix(name, repository.CustomFieldPrefix) {
+			continue
+		}
+
+		key := strings.TrimPrefix(name, repository.CustomFieldPrefix)
+		bound := ""
+		if i := strings.LastIndex(key, "."); i >= 0 {
+			key, bound = key[:i], key[i+1:]
+		}
+		if !domain.IsValidCustomFieldKey(key) || (bound != "" && bound != "min" && bound != "max") {
+			return nil, errors.BadRequest("Неверный параметр пользовательского поля: " + name)
+		}
+
+		condition, ok := byKey[key]
+		if !ok {
+			condition = &repository.CustomFieldFilter{Key: key}
+			byKey[key] = condition
+			keys = append(keys, key)
+		}
+
+		switch bound {
+		case "min":
+			condition.Min = strings.TrimSpace(query.Get(name))
+		case "max":
+			condition.Max = strings.TrimSpace(query.Get(name))
+		default:
+			condition.Values = listParam(query, name)
+		}
+	}
+
+	sort.Strings(keys)
+	conditions := make([]repository.CustomFieldFilter, 0, len(keys))
+	for _, key := range keys {
+		conditions = append(conditions, *byKey[key])
+	}
+
+	return conditions, nil
+}
+
+func isCustomFieldSort(field string) bool {
+	return strings.HasPrefix(field, repository.CustomFieldPrefix) &&
+		domain.IsValidCustomFieldKey(strings.TrimPrefix(field, repository.CustomFieldPrefix))
+}
+
+// listParam возвращает значения параметра, заданные через запятую и/или повтором
+func listParam(query url.Values, name string) []string {
+	values := []string{}
+	for _, raw := range query[name] {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func intParam(query url.Values, name string) (*int, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, errors.BadRequest("Неверное числовое значение " + name)
+	}
+	return &n, nil
+}
+
+func uuidParam(query url.Values, name string) (*uuid.UUID, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, errors.BadRequest("Неверный UUID в параметре " + name)
+	}
+	return &id, nil
+}
+
+// employeeParam разбирает UUID сотрудника с поддержкой подстановки "me"
+func employeeParam(query url.Values, name string, me uuid.UUID) (*uuid.UUID, error) {
+	if query.Get(name) != "me" {
+		return uuidParam(query, name)
+	}
+
+	if me == uuid.Nil {
+		return nil, errors.BadRequest("Значение me в параметре " + name + " недоступно без авторизации")
+	}
+	return &me, nil
+}
+
+// timeParam разбирает дату или метку времени. Для верхней границы дата без времени
+// означает конец дня, чтобы диапазон включал весь указанный день.
+func timeParam(query url.Values, name string, upper bool) (*time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, errors.BadRequest("Неверный формат даты в параметре " + name + ", ожидается ГГГГ-ММ-ДД или RFC 3339")
+	}
+	if upper {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &t, nil
+}
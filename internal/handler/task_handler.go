@@ -2,7 +2,7 @@ package handler
 
 import (
 	"net/http"
-	"strconv"
+	"strings"
 
 	"github.com/dmitry/taskmanager/internal/domain"
 	"github.com/dmitry/taskmanager/internal/dto"
@@ -10,18 +10,22 @@ import (
 	"github.com/dmitry/taskmanager/internal/repository"
 	"github.com/dmitry/taskmanager/internal/service"
 	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/markdown"
 	"github.com/dmitry/taskmanager/pkg/validator"
 	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 )
 
 type TaskHandler struct {
 	service   *service.TaskService
+	renderer  *markdown.Renderer
 	validator *validator.Validator
 }
 
-func NewTaskHandler(service *service.TaskService, validator *validator.Validator) *TaskHandler {
+func NewTaskHandler(service *service.TaskService, renderer *markdown.Renderer, validator *validator.Validator) *TaskHandler {
 	return &TaskHandler{
 		service:   service,
+		renderer:  renderer,
 		validator: validator,
 	}
 }
@@ -43,6 +47,24 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var projectID, parentID *uuid.UUID
+	if req.ProjectID != nil {
+		id, err := uuid.Parse(*req.ProjectID)
+		if err != nil {
+			RespondError(w, errors.BadRequest("Неверный ID проекта"))
+			return
+		}
+		projectID = &id
+	}
+	if req.ParentID != nil {
+		id, err := uuid.Parse(*req.ParentID)
+		if err != nil {
+			RespondError(w, errors.BadRequest("Неверный ID родительской задачи"))
+			return
+		}
+		parentID = &id
+	}
+
 	participants := make([]service.ParticipantInput, len(req.Participants))
 	for i, p := range req.Participants {
 		empID, err := uuid.Parse(p.EmployeeID)
@@ -57,6 +79,8 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 	}
 
 	task, err := h.service.CreateTask(r.Context(), service.CreateTaskRequest{
+		ProjectID:    projectID,
+		ParentID:     parentID,
 		Title:        req.Title,
 		Description:  req.Description,
 		Priority:     req.Priority,
@@ -69,7 +93,13 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	RespondJSON(w, http.StatusCreated, dto.ToTaskResponse(task))
+	resp, err := h.toTaskResponse(r, task)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusCreated, resp)
 }
 
 func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
@@ -78,55 +108,125 @@ func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := h.service.GetTask(r.Context(), id)
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	task, err := h.service.GetTask(r.Context(), id, employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	resp, err := h.toTaskResponse(r, task)
 	if err != nil {
 		RespondError(w, err)
 		return
 	}
 
-	RespondJSON(w, http.StatusOK, dto.ToTaskResponse(task))
+	RespondJSON(w, http.StatusOK, resp)
 }
 
-func (h *TaskHandler) GetAllTasks(w http.ResponseWriter, r *http.Request) {
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+// GetStatusTransitions возвращает историю смен статуса задачи в хронологическом порядке
+func (h *TaskHandler) GetStatusTransitions(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
 
-	if page < 1 {
-		page = 1
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
 	}
-	if pageSize < 1 {
-		pageSize = 20
+
+	transitions, err := h.service.GetStatusTransitions(r.Context(), id, employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
 	}
 
-	filter := repository.TaskFilter{
-		Page:     page,
-		PageSize: pageSize,
+	responses := make([]dto.StatusTransitionResponse, len(transitions))
+	for i, t := range transitions {
+		responses[i] = dto.ToStatusTransitionResponse(t)
 	}
 
-	if statusStr := r.URL.Query().Get("status"); statusStr != "" {
-		filter.Status = []domain.TaskStatus{domain.TaskStatus(statusStr)}
+	RespondJSON(w, http.StatusOK, responses)
+}
+
+// GetTaskByKey возвращает задачу проекта по человекочитаемому ключу, например CORE-123
+func (h *TaskHandler) GetTaskByKey(w http.ResponseWriter, r *http.Request) {
+	key := strings.ToUpper(mux.Vars(r)["key"])
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
 	}
 
-	tasks, total, err := h.service.GetAllTasks(r.Context(), filter)
+	task, err := h.service.GetTaskByKey(r.Context(), key, employeeID)
 	if err != nil {
 		RespondError(w, err)
 		return
 	}
 
-	responses := make([]dto.TaskResponse, len(tasks))
-	for i, task := range tasks {
-		responses[i] = dto.ToTaskResponse(task)
+	resp, err := h.toTaskResponse(r, task)
+	if err != nil {
+		RespondError(w, err)
+		return
 	}
 
-	totalPages := (total + pageSize - 1) / pageSize
+	RespondJSON(w, http.StatusOK, resp)
+}
 
-	RespondJSON(w, http.StatusOK, dto.PaginatedResponse{
-		Data:       responses,
-		Total:      total,
-		Page:       page,
-		PageSize:   pageSize,
-		TotalPages: totalPages,
-	})
+func (h *TaskHandler) GetAllTasks(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseTaskFilter(r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	h.respondTaskList(w, r, filter)
+}
+
+// respondTaskList отвечает страницей задач по фильтру в режиме курсора или постраничном режиме
+func (h *TaskHandler) respondTaskList(w http.ResponseWriter, r *http.Request, filter repository.TaskFilter) {
+	if UsesCursor(r) {
+		tasks, nextCursor, err := h.service.GetAllTasksByCursor(r.Context(), filter)
+		if err != nil {
+			RespondError(w, err)
+			return
+		}
+
+		var total *int
+		if WantsTotal(r) {
+			count, err := h.service.CountTasks(r.Context(), filter)
+			if err != nil {
+				RespondError(w, err)
+				return
+			}
+			total = &count
+		}
+
+		h.respondTaskCursorPage(w, r, tasks, filter.Limit, nextCursor, total)
+		return
+	}
+
+	tasks, total, err := h.service.GetAllTasks(r.Context(), filter)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	responses, err := h.toTaskResponses(r, tasks)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.NewPagedResponse(responses, total, filter.Page, filter.PageSize))
 }
 
 func (h *TaskHandler) UpdateTaskStatus(w http.ResponseWriter, r *http.Request) {
@@ -145,13 +245,22 @@ func (h *TaskHandler) UpdateTaskStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.service.UpdateTaskStatus(r.Context(), id, domain.TaskStatus(req.Status))
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	warnings, err := h.service.UpdateTaskStatus(r.Context(), id, employeeID, domain.TaskStatus(req.Status))
 	if err != nil {
 		RespondError(w, err)
 		return
 	}
 
-	RespondJSON(w, http.StatusOK, map[string]string{"message": "Статус задачи успешно обновлён"})
+	RespondJSON(w, http.StatusOK, dto.UpdateTaskStatusResponse{
+		Message:  "Статус задачи успешно обновлён",
+		Warnings: warnings,
+	})
 }
 
 func (h *TaskHandler) ArchiveTask(w http.ResponseWriter, r *http.Request) {
@@ -160,7 +269,13 @@ func (h *TaskHandler) ArchiveTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.service.ArchiveTask(r.Context(), id)
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	err = h.service.ArchiveTask(r.Context(), id, employeeID)
 	if err != nil {
 		RespondError(w, err)
 		return
@@ -169,13 +284,90 @@ func (h *TaskHandler) ArchiveTask(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, http.StatusOK, map[string]string{"message": "Задача успешно архивирована"})
 }
 
+// GetSubtasks возвращает непосредственные подзадачи и сводку прогресса и времени по поддереву
+func (h *TaskHandler) GetSubtasks(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	subtasks, rollup, err := h.service.GetSubtasks(r.Context(), id, employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	responses, err := h.toTaskResponses(r, subtasks)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.SubtasksResponse{
+		Subtasks: responses,
+		Rollup:   dto.ToTaskRollupResponse(rollup),
+	})
+}
+
+func (h *TaskHandler) SetParent(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetTaskParentRequest
+	if !DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	var parentID *uuid.UUID
+	if req.ParentID != nil {
+		parsed, err := uuid.Parse(*req.ParentID)
+		if err != nil {
+			RespondError(w, errors.BadRequest("Неверный ID родительской задачи"))
+			return
+		}
+		parentID = &parsed
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if err := h.service.SetParent(r.Context(), id, parentID, employeeID); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, map[string]string{"message": "Родительская задача успешно изменена"})
+}
+
 func (h *TaskHandler) GetTaskParticipants(w http.ResponseWriter, r *http.Request) {
 	id, ok := ParseUUID(w, r, "id")
 	if !ok {
 		return
 	}
 
-	participants, err := h.service.GetParticipants(r.Context(), id)
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	participants, err := h.service.GetParticipants(r.Context(), id, employeeID)
 	if err != nil {
 		RespondError(w, err)
 		return
@@ -211,7 +403,13 @@ func (h *TaskHandler) AddParticipant(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.service.AddParticipant(r.Context(), taskID, employeeID, domain.ParticipantRole(req.Role))
+	actorID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	err = h.service.AddParticipant(r.Context(), taskID, actorID, employeeID, domain.ParticipantRole(req.Role))
 	if err != nil {
 		RespondError(w, err)
 		return
@@ -226,39 +424,81 @@ func (h *TaskHandler) GetEmployeeTasks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	filter, err := parseTaskFilter(r)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if UsesCursor(r) {
+		tasks, nextCursor, err := h.service.GetTasksForEmployeeByCursor(r.Context(), employeeID, filter)
+		if err != nil {
+			RespondError(w, err)
+			return
+		}
+
+		var total *int
+		if WantsTotal(r) {
+			count, err := h.service.CountTasksForEmployee(r.Context(), employeeID, filter)
+			if err != nil {
+				RespondError(w, err)
+				return
+			}
+			total = &count
+		}
 
-	if page < 1 {
-		page = 1
+		h.respondTaskCursorPage(w, r, tasks, filter.Limit, nextCursor, total)
+		return
 	}
-	if pageSize < 1 {
-		pageSize = 20
+
+	tasks, total, err := h.service.GetTasksForEmployee(r.Context(), employeeID, filter)
+	if err != nil {
+		RespondError(w, err)
+		return
 	}
 
-	filter := repository.TaskFilter{
-		Page:     page,
-		PageSize: pageSize,
+	responses, err := h.toTaskResponses(r, tasks)
+	if err != nil {
+		RespondError(w, err)
+		return
 	}
 
-	tasks, total, err := h.service.GetTasksForEmployee(r.Context(), employeeID, filter)
+	RespondJSON(w, http.StatusOK, dto.NewPagedResponse(responses, total, filter.Page, filter.PageSize))
+}
+
+func (h *TaskHandler) respondTaskCursorPage(w http.ResponseWriter, r *http.Request, tasks []*domain.Task, limit int, nextCursor string, total *int) {
+	responses, err := h.toTaskResponses(r, tasks)
 	if err != nil {
 		RespondError(w, err)
 		return
 	}
 
-	responses := make([]dto.TaskResponse, len(tasks))
-	for i, task := range tasks {
-		responses[i] = dto.ToTaskResponse(task)
+	RespondJSON(w, http.StatusOK, dto.NewCursorResponse(responses, limit, nextCursor, total))
+}
+
+// toTaskResponse преобразует задачу в DTO и при ?render=html добавляет очищенный HTML описания
+func (h *TaskHandler) toTaskResponse(r *http.Request, task *domain.Task) (dto.TaskResponse, error) {
+	resp := dto.ToTaskResponse(task)
+
+	if WantsHTML(r) {
+		html, err := h.renderer.Render(task.Description)
+		if err != nil {
+			return resp, errors.Internal(err, "Не удалось отрендерить описание задачи")
+		}
+		resp.DescriptionHTML = html
 	}
 
-	totalPages := (total + pageSize - 1) / pageSize
+	return resp, nil
+}
 
-	RespondJSON(w, http.StatusOK, dto.PaginatedResponse{
-		Data:       responses,
-		Total:      total,
-		Page:       page,
-		PageSize:   pageSize,
-		TotalPages: totalPages,
-	})
+func (h *TaskHandler) toTaskResponses(r *http.Request, tasks []*domain.Task) ([]dto.TaskResponse, error) {
+	responses := make([]dto.TaskResponse, len(tasks))
+	for i, task := range tasks {
+		resp, err := h.toTaskResponse(r, task)
+		if err != nil {
+			return nil, err
+		}
+		responses[i] = resp
+	}
+	return responses, nil
 }
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmitry/taskmanager/internal/dto"
+	"github.com/dmitry/taskmanager/internal/middleware"
+	"github.com/dmitry/taskmanager/internal/service"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/validator"
+	"github.com/google/uuid"
+)
+
+type TaskLinkHandler struct {
+	service   *service.TaskLinkService
+	validator *validator.Validator
+}
+
+func NewTaskLinkHandler(service *service.TaskLinkService, validator *validator.Validator) *TaskLinkHandler {
+	return &TaskLinkHandler{
+		service:   service,
+		validator: validator,
+	}
+}
+
+func (h *TaskLinkHandler) GetTaskLinks(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	links, err := h.service.GetTaskLinks(r.Context(), taskID, employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	responses := make([]dto.TaskLinkResponse, len(links))
+	for i, l := range links {
+		responses[i] = dto.ToTaskLinkResponse(l)
+	}
+
+	RespondJSON(w, http.StatusOK, responses)
+}
+
+// CreateLink связывает задачу {id} с задачей task_id: "{id} blocks task_id",
+// "{id} blocked_by task_id" и т.д.
+func (h *TaskLinkHandler) CreateLink(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.CreateTaskLinkRequest
+	if !DecodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	otherTaskID, err := uuid.Parse(req.TaskID)
+	if err != nil {
+		RespondError(w, errors.BadRequest("Неверный ID задачи"))
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	link, err := h.service.CreateLink(r.Context(), service.CreateTaskLinkRequest{
+		TaskID:      taskID,
+		OtherTaskID: otherTaskID,
+		Type:        req.Type,
+		CreatedBy:   employeeID,
+	})
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusCreated, dto.ToTaskLinkResponse(link))
+}
+
+func (h *TaskLinkHandler) DeleteLink(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteLink(r.Context(), id, employeeID); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, map[string]string{"message": "Связь успешно удалена"})
+}
@@ -0,0 +1,299 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/dto"
+	"github.com/dmitry/taskmanager/internal/middleware"
+	"github.com/dmitry/taskmanager/internal/service"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/validator"
+	"github.com/google/uuid"
+)
+
+// viewRequestParams - параметры навигации и отображения, которые берутся из запроса,
+// а не из сохранённого представления
+var viewRequestParams = []string{"page", "page_size", "cursor", "limit", "render", "with_total"}
+
+type TaskViewHandler struct {
+	service   *service.TaskViewService
+	tasks     *TaskHandler
+	validator *validator.Validator
+}
+
+func NewTaskViewHandler(service *service.TaskViewService, tasks *TaskHandler, validator *validator.Validator) *TaskViewHandler {
+	return &TaskViewHandler{
+		service:   service,
+		tasks:     tasks,
+		validator: validator,
+	}
+}
+
+func (h *TaskViewHandler) GetViews(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	views, err := h.service.GetViews(r.Context(), employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	responses := make([]dto.TaskViewResponse, len(views))
+	for i, v := range views {
+		responses[i] = dto.ToTaskViewResponse(v)
+	}
+
+	RespondJSON(w, http.StatusOK, responses)
+}
+
+func (h *TaskViewHandler) CreateView(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decodeSaveRequest(w, r)
+	if !ok {
+		return
+	}
+
+	ownerID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	view, err := h.service.CreateView(r.Context(), ownerID, req)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusCreated, dto.ToTaskViewResponse(view))
+}
+
+func (h *TaskViewHandler) GetView(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	view, err := h.service.GetView(r.Context(), id, employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.ToTaskViewResponse(view))
+}
+
+func (h *TaskViewHandler) GetDefaultView(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	view, err := h.service.GetDefaultView(r.Context(), employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.ToTaskViewResponse(view))
+}
+
+func (h *TaskViewHandler) UpdateView(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := h.decodeSaveRequest(w, r)
+	if !ok {
+		return
+	}
+
+	editorID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	view, err := h.service.UpdateView(r.Context(), id, editorID, req)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, dto.ToTaskViewResponse(view))
+}
+
+func (h *TaskViewHandler) DeleteView(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	editorID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteView(r.Context(), id, editorID); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, map[string]string{"message": "Представление успешно удалено"})
+}
+
+func (h *TaskViewHandler) SetDefaultView(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if err := h.service.SetDefaultView(r.Context(), id, employeeID); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, map[string]string{"message": "Представление закреплено по умолчанию"})
+}
+
+func (h *TaskViewHandler) ClearDefaultView(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	if err := h.service.ClearDefaultView(r.Context(), employeeID); err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, map[string]string{"message": "Представление по умолчанию откреплено"})
+}
+
+// GetViewTasks выполняет сохранённый фильтр от имени текущего сотрудника.
+// Пагинация и формат ответа задаются параметрами самого запроса, как в GET /tasks.
+func (h *TaskViewHandler) GetViewTasks(w http.ResponseWriter, r *http.Request) {
+	id, ok := ParseUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	view, err := h.service.GetView(r.Context(), id, employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	h.respondViewTasks(w, r, view, employeeID)
+}
+
+func (h *TaskViewHandler) GetDefaultViewTasks(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := middleware.GetEmployeeIDFromContext(r.Context())
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	view, err := h.service.GetDefaultView(r.Context(), employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	h.respondViewTasks(w, r, view, employeeID)
+}
+
+func (h *TaskViewHandler) respondViewTasks(w http.ResponseWriter, r *http.Request, view *domain.TaskView, employeeID uuid.UUID) {
+	query, err := url.ParseQuery(view.Query)
+	if err != nil {
+		RespondError(w, errors.Internal(err, "Сохранённый запрос представления повреждён"))
+		return
+	}
+
+	requestQuery := r.URL.Query()
+	for _, name := range viewRequestParams {
+		if requestQuery.Has(name) {
+			query[name] = requestQuery[name]
+		}
+	}
+
+	filter, err := parseTaskFilterQuery(query, employeeID)
+	if err != nil {
+		RespondError(w, err)
+		return
+	}
+
+	h.tasks.respondTaskList(w, r, filter)
+}
+
+func (h *TaskViewHandler) decodeSaveRequest(w http.ResponseWriter, r *http.Request) (service.SaveTaskViewRequest, bool) {
+	var req dto.SaveTaskViewRequest
+	if !DecodeJSON(w, r, &req) {
+		return service.SaveTaskViewRequest{}, false
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		RespondError(w, err)
+		return service.SaveTaskViewRequest{}, false
+	}
+
+	query, err := normalizeViewQuery(req.Query)
+	if err != nil {
+		RespondError(w, err)
+		return service.SaveTaskViewRequest{}, false
+	}
+
+	return service.SaveTaskViewRequest{
+		Name:             strings.TrimSpace(req.Name),
+		Query:            query,
+		SharedDepartment: req.SharedDepartment,
+	}, true
+}
+
+// normalizeViewQuery проверяет сохраняемый запрос тем же разбором, что и GET /tasks,
+// и убирает из него параметры навигации и отображения
+func normalizeViewQuery(raw string) (string, error) {
+	query, err := url.ParseQuery(strings.TrimPrefix(strings.TrimSpace(raw), "?"))
+	if err != nil {
+		return "", errors.BadRequest("Неверный формат запроса представления")
+	}
+
+	for _, name := range viewRequestParams {
+		query.Del(name)
+	}
+
+	// "me" подставляется при выполнении, для проверки достаточно любого сотрудника
+	if _, err := parseTaskFilterQuery(query, uuid.New()); err != nil {
+		return "", err
+	}
+
+	return query.Encode(), nil
+}
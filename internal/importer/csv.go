@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// csvColumns - названия колонок CSV (без учёта регистра), включая названия из выгрузки Jira в CSV
+var csvColumns = map[string][]string{
+	"key":         {"key", "issue key", "id"},
+	"parent":      {"parent", "parent key", "parent id"},
+	"title":       {"title", "summary", "name"},
+	"description": {"description", "desc"},
+	"status":      {"status", "list"},
+	"priority":    {"priority"},
+	"reporter":    {"reporter", "created by", "creator"},
+	"assignee":    {"assignee", "assignees"},
+	"due_date":    {"due date", "due_date", "due"},
+	"created":     {"created", "created at", "created_at"},
+	"updated":     {"updated", "updated at", "updated_at"},
+}
+
+// parseCSV читает таблицу с заголовком. Разделитель - запятая или точка с запятой (как сохраняет
+// Excel в русской локали), определяется по заголовку. Обязательна только колонка названия;
+// несколько исполнителей в одной ячейке разделяются запятой или точкой с запятой.
+// Комментарии и списания времени в CSV не переносятся.
+func parseCSV(r io.Reader) ([]*Issue, error) {
+	buffered := bufio.NewReader(r)
+	// Excel добавляет в начало файла UTF-8 BOM
+	if bom, _ := buffered.Peek(3); bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+		buffered.Discard(3)
+	}
+
+	reader := csv.NewReader(buffered)
+	if head, _ := buffered.Peek(4096); semicolonSeparated(head) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("неверный CSV: %w", err)
+	}
+	columns := csvColumnIndexes(header)
+	if _, ok := columns["title"]; !ok {
+		return nil, fmt.Errorf("неверный CSV: нет колонки названия (title или summary)")
+	}
+
+	issues := []*Issue{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("неверный CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		issue := &Issue{
+			Key:         value("key"),
+			ParentKey:   value("parent"),
+			Title:       value("title"),
+			Description: value("description"),
+			Status:      value("status"),
+			Priority:    value("priority"),
+			Reporter:    value("reporter"),
+		}
+		if issue.Key == "" {
+			issue.Key = fmt.Sprintf("строка %d", line)
+		}
+		for _, assignee := range strings.FieldsFunc(value("assignee"), func(r rune) bool { return r == ',' || r == ';' }) {
+			if assignee = strings.TrimSpace(assignee); assignee != "" {
+				issue.Assignees = append(issue.Assignees, assignee)
+			}
+		}
+
+		if issue.DueDate, err = parseTime(value("due_date")); err != nil {
+			return nil, fmt.Errorf("строка %d: %w", line, err)
+		}
+		if issue.CreatedAt, err = timeValue(value("created")); err != nil {
+			return nil, fmt.Errorf("строка %d: %w", line, err)
+		}
+		if issue.UpdatedAt, err = timeValue(value("updated")); err != nil {
+			return nil, fmt.Errorf("строка %d: %w", line, err)
+		}
+
+		issues = append(issues, issue)
+	}
+
+	return issues, nil
+}
+
+// csvColumnIndexes находит номера известных колонок по заголовку
+func csvColumnIndexes(header []string) map[string]int {
+	columns := map[string]int{}
+	for i, name := range header {
+		name = normalize(name)
+		for column, aliases := range csvColumns {
+			for _, alias := range aliases {
+				if _, seen := columns[column]; !seen && name == alias {
+					columns[column] = i
+				}
+			}
+		}
+	}
+	return columns
+}
+
+// semicolonSeparated сообщает, что в первой строке точек с запятой больше, чем запятых
+func semicolonSeparated(head []byte) bool {
+	if i := bytes.IndexByte(head, '\n'); i >= 0 {
+		head = head[:i]
+	}
+	return bytes.Count(head, []byte(";")) > bytes.Count(head, []byte(","))
+}
@@ -0,0 +1,112 @@
+// Package importer читает выгрузки внешних трекеров задач (Jira, Trello, CSV) в общий вид,
+// не зависящий от источника. Сопоставление со статусами, приоритетами и сотрудниками
+// Task Manager задаётся Mapping, а сохраняет задачи сервис импорта.
+package importer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Source - формат выгрузки
+type Source string
+
+const (
+	SourceJira   Source = "jira"
+	SourceTrello Source = "trello"
+	SourceCSV    Source = "csv"
+)
+
+func (s Source) IsValid() bool {
+	switch s {
+	case SourceJira, SourceTrello, SourceCSV:
+		return true
+	}
+	return false
+}
+
+// Issue - задача источника. Пользователи указаны так, как их называет источник:
+// email, имя пользователя или идентификатор учётной записи (см. Mapping.Users).
+type Issue struct {
+	// Key - идентификатор задачи в источнике: ключ Jira, id карточки Trello, колонка key в CSV
+	Key         string
+	ParentKey   string
+	Title       string
+	Description string
+	Status      string
+	Priority    string
+	Reporter    string
+	Assignees   []string
+	Archived    bool
+	DueDate     *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Comments    []Comment
+	Worklogs    []Worklog
+}
+
+type Comment struct {
+	Author    string
+	Body      string
+	CreatedAt time.Time
+}
+
+// Worklog - списанное на задачу время
+type Worklog struct {
+	Author  string
+	Hours   float64
+	Started time.Time
+	Comment string
+}
+
+// Parse читает выгрузку source целиком
+func Parse(source Source, r io.Reader) ([]*Issue, error) {
+	switch source {
+	case SourceJira:
+		return parseJira(r)
+	case SourceTrello:
+		return parseTrello(r)
+	case SourceCSV:
+		return parseCSV(r)
+	}
+	return nil, fmt.Errorf("неизвестный источник %q", source)
+}
+
+// timeLayouts - форматы дат в выгрузках: RFC 3339, Jira REST, Jira CSV, даты без времени
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000-0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"02/Jan/06 3:04 PM",
+	"02.01.2006 15:04",
+	"2006-01-02",
+	"02.01.2006",
+	"01/02/2006",
+}
+
+// parseTime разбирает дату в одном из timeLayouts; пустая строка - не ошибка
+func parseTime(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("неизвестный формат даты %q", value)
+}
+
+// timeValue разбирает дату; пустая строка даёт нулевое время
+func timeValue(value string) (time.Time, error) {
+	t, err := parseTime(value)
+	if err != nil || t == nil {
+		return time.Time{}, err
+	}
+	return *t, nil
+}
@@ -0,0 +1,362 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+)
+
+func date(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func datePtr(value string) *time.Time {
+	t := date(value)
+	return &t
+}
+
+func parseFixture(t *testing.T, source Source, name string) []*Issue {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	issues, err := Parse(source, f)
+	if err != nil {
+		t.Fatalf("Parse(%s, %s): %v", source, name, err)
+	}
+	return issues
+}
+
+// inUTC приводит даты к UTC, чтобы сравнивать моменты времени, а не зоны выгрузки
+func inUTC(issues []*Issue) []Issue {
+	utc := func(t time.Time) time.Time { return t.UTC() }
+	result := make([]Issue, len(issues))
+	for i, issue := range issues {
+		copied := *issue
+		copied.CreatedAt = utc(copied.CreatedAt)
+		copied.UpdatedAt = utc(copied.UpdatedAt)
+		if copied.DueDate != nil {
+			due := utc(*copied.DueDate)
+			copied.DueDate = &due
+		}
+		copied.Comments = append([]Comment(nil), issue.Comments...)
+		for j := range copied.Comments {
+			copied.Comments[j].CreatedAt = utc(copied.Comments[j].CreatedAt)
+		}
+		copied.Worklogs = append([]Worklog(nil), issue.Worklogs...)
+		for j := range copied.Worklogs {
+			copied.Worklogs[j].Started = utc(copied.Worklogs[j].Started)
+		}
+		result[i] = copied
+	}
+	return result
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		source Source
+		file   string
+		want   []*Issue
+	}{
+		{
+			name:   "Jira REST v2, одна страница поиска",
+			source: SourceJira,
+			file:   "jira_search_v2.json",
+			want: []*Issue{
+				{
+					Key:         "CORE-1",
+					Title:       "Настроить CI",
+					Description: "Сборка и тесты на каждый коммит",
+					Status:      "In Progress",
+					Priority:    "High",
+					Reporter:    "anna@example.com",
+					// Jira Server без email отдаёт имя пользователя
+					Assignees: []string{"ivan"},
+					DueDate:   datePtr("2024-03-15T00:00:00Z"),
+					CreatedAt: date("2024-03-01T07:15:00Z"),
+					UpdatedAt: date("2024-03-02T09:00:00Z"),
+					Comments: []Comment{
+						{Author: "ivan@example.com", Body: "Взял в работу", CreatedAt: date("2024-03-01T08:00:00Z")},
+					},
+					Worklogs: []Worklog{
+						{Author: "ivan@example.com", Hours: 1.5, Started: date("2024-03-01T09:00:00Z"), Comment: "Пайплайн"},
+					},
+				},
+				{
+					Key:       "CORE-2",
+					ParentKey: "CORE-1",
+					Title:     "Кэш зависимостей",
+					Status:    "To Do",
+					// Без reporter автором считается creator
+					Reporter:  "anna@example.com",
+					CreatedAt: date("2024-03-01T07:20:00Z"),
+					UpdatedAt: date("2024-03-01T07:20:00Z"),
+				},
+			},
+		},
+		{
+			name:   "Jira REST v3, страницы и отдельная задача, тексты в ADF",
+			source: SourceJira,
+			file:   "jira_pages_v3.json",
+			want: []*Issue{
+				{
+					Key:         "WEB-7",
+					Title:       "Форма входа",
+					Description: "Что сделать\n\n- поле email\n\n- поле пароля\n\nСогласовать с @Иван\nдо пятницы",
+					Status:      "Done",
+					Priority:    "Medium",
+					Reporter:    "5b10ac8d82e05b22cc7d4ef5",
+					Assignees:   []string{"5b10ac8d82e05b22cc7d4ef5"},
+					CreatedAt:   date("2024-02-10T09:00:00Z"),
+					UpdatedAt:   date("2024-02-12T09:00:00Z"),
+					Comments: []Comment{
+						{Author: "5b10ac8d82e05b22cc7d4ef5", Body: "Готово", CreatedAt: date("2024-02-12T08:00:00Z")},
+					},
+				},
+				{
+					Key:       "WEB-8",
+					Title:     "Восстановление пароля",
+					Status:    "Blocked",
+					CreatedAt: date("2024-02-11T09:00:00Z"),
+					UpdatedAt: date("2024-02-11T09:00:00Z"),
+				},
+				{
+					Key:       "WEB-9",
+					Title:     "Выход из системы",
+					Status:    "Open",
+					CreatedAt: date("2024-02-11T10:00:00Z"),
+					UpdatedAt: date("2024-02-11T10:00:00Z"),
+				},
+			},
+		},
+		{
+			name:   "Trello, действия от новых к старым",
+			source: SourceTrello,
+			file:   "trello_board.json",
+			want: []*Issue{
+				{
+					Key:         "65f0c0a1aaaaaaaaaaaaaaaa",
+					Title:       "Пост о релизе",
+					Description: "Черновик в документе",
+					Status:      "Doing",
+					Reporter:    "anna_petrova",
+					Assignees:   []string{"anna_petrova", "ivan_s"},
+					DueDate:     datePtr("2024-03-20T15:00:00Z"),
+					// Время создания берётся из первых 8 цифр идентификатора карточки
+					CreatedAt: time.Unix(0x65f0c0a1, 0).UTC(),
+					UpdatedAt: date("2024-03-13T08:30:00Z"),
+					Comments: []Comment{
+						{Author: "ivan_s", Body: "Черновик готов", CreatedAt: date("2024-03-12T09:00:00Z")},
+						{Author: "anna_petrova", Body: "Согласовано", CreatedAt: date("2024-03-13T08:30:00Z")},
+					},
+				},
+				{
+					Key:       "65f0c0a2bbbbbbbbbbbbbbbb",
+					Title:     "Старая рассылка",
+					Status:    "To Do",
+					Archived:  true,
+					CreatedAt: time.Unix(0x65f0c0a2, 0).UTC(),
+					UpdatedAt: date("2024-03-12T10:00:00Z"),
+				},
+			},
+		},
+		{
+			name:   "CSV из Excel: BOM, точка с запятой, CRLF",
+			source: SourceCSV,
+			file:   "excel_semicolon.csv",
+			want: []*Issue{
+				{
+					Key:         "OPS-1",
+					Title:       "Обновить сертификаты",
+					Description: "Домены: api, web; срок - до конца месяца",
+					Status:      "В работе",
+					Priority:    "Высокий",
+					Reporter:    "anna@example.com",
+					Assignees:   []string{"ivan@example.com", "petr@example.com"},
+					DueDate:     datePtr("2024-03-31T00:00:00Z"),
+					CreatedAt:   date("2024-03-01T09:30:00Z"),
+				},
+				{
+					Key:       "OPS-2",
+					ParentKey: "OPS-1",
+					Title:     "Проверить продление",
+					Status:    "To Do",
+					Reporter:  "anna@example.com",
+					CreatedAt: date("2024-03-02T00:00:00Z"),
+				},
+				{
+					// Без ключа задача называется по номеру строки файла
+					Key:      "строка 4",
+					Title:    "Без ключа",
+					Status:   "Done",
+					Priority: "Low",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := inUTC(parseFixture(t, tt.source, tt.file))
+			want := inUTC(tt.want)
+			if len(got) != len(want) {
+				t.Fatalf("получено задач: %d, ожидалось %d", len(got), len(want))
+			}
+			for i := range want {
+				if !reflect.DeepEqual(got[i], want[i]) {
+					t.Errorf("задача %d:\nполучено  %+v\nожидалось %+v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source Source
+		input  string
+	}{
+		{"Jira не JSON", SourceJira, "<html>"},
+		{"Jira неверная дата", SourceJira, `{"issues": [{"key": "A-1", "fields": {"created": "вчера"}}]}`},
+		{"Trello не JSON", SourceTrello, "cards"},
+		{"CSV без колонки названия", SourceCSV, "key,status\nA-1,Open\n"},
+		{"CSV неверная дата", SourceCSV, "title,due date\nЗадача,скоро\n"},
+		{"неизвестный источник", Source("asana"), "{}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.source, strings.NewReader(tt.input)); err == nil {
+				t.Error("ожидалась ошибка")
+			}
+		})
+	}
+}
+
+func TestDefaultMapping(t *testing.T) {
+	m := DefaultMapping()
+
+	statuses := map[string]domain.TaskStatus{
+		"To Do":         domain.TaskStatusNew,
+		" in progress ": domain.TaskStatusInProgress,
+		"Code Review":   domain.TaskStatusCodeReview,
+		"QA":            domain.TaskStatusTesting,
+		"Reopened":      domain.TaskStatusReturnedWithErrors,
+		"DONE":          domain.TaskStatusClosed,
+	}
+	for name, want := range statuses {
+		if got, ok := m.Status(name); !ok || got != want {
+			t.Errorf("Status(%q) = %q, %v; ожидалось %q", name, got, ok, want)
+		}
+	}
+	if got, ok := m.Status("Blocked"); ok || got != "" {
+		t.Errorf("несопоставленный статус без default_status: %q, %v", got, ok)
+	}
+
+	if got, ok := m.Priority(""); !ok || got != 1 {
+		t.Errorf("пустой приоритет: %d, %v", got, ok)
+	}
+	if got, ok := m.Priority("Highest"); !ok || got != 2 {
+		t.Errorf("Priority(Highest) = %d, %v", got, ok)
+	}
+	if got, ok := m.Priority("Critical"); ok || got != 1 {
+		t.Errorf("несопоставленный приоритет: %d, %v", got, ok)
+	}
+
+	if got := m.Email("anna@example.com"); got != "anna@example.com" {
+		t.Errorf("email пользователя: %q", got)
+	}
+	if got := m.Email("anna_petrova"); got != "" {
+		t.Errorf("несопоставленное имя пользователя: %q", got)
+	}
+	if m.DefaultCreator != "" {
+		t.Errorf("создатель по умолчанию: %q", m.DefaultCreator)
+	}
+}
+
+func TestLoadMapping(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "mapping.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	m, err := LoadMapping(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := map[string]domain.TaskStatus{
+		"в работе":    domain.TaskStatusInProgress,
+		"Done":        domain.TaskStatusTesting, // заменён
+		"In Progress": domain.TaskStatusInProgress,
+		"Неизвестный": domain.TaskStatusNew, // default_status
+	}
+	for name, want := range statuses {
+		if got, _ := m.Status(name); got != want {
+			t.Errorf("Status(%q) = %q, ожидалось %q", name, got, want)
+		}
+	}
+
+	priorities := map[string]int{
+		"высокий": 2,
+		"Medium":  0, // заменён
+		"High":    2, // из значений по умолчанию
+		"":        0, // default_priority: 0 заменяет 1
+	}
+	for name, want := range priorities {
+		if got, _ := m.Priority(name); got != want {
+			t.Errorf("Priority(%q) = %d, ожидалось %d", name, got, want)
+		}
+	}
+
+	if got := m.Email("5b10ac8d82e05b22cc7d4ef5"); got != "ivan@example.com" {
+		t.Errorf("Email(accountId) = %q", got)
+	}
+	if m.DefaultCreator != "admin@example.com" {
+		t.Errorf("DefaultCreator = %q", m.DefaultCreator)
+	}
+}
+
+func TestLoadMappingErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"пустой файл", "", ""},
+		{"неизвестное поле", "state: new\n", "field state not found"},
+		{
+			"неверные значения",
+			"statuses:\n  Open: opened\ndefault_status: done\npriorities:\n  Critical: 5\ndefault_priority: -1\n",
+			`default_priority: приоритет должен быть от 0 до 2; default_status: неизвестный статус "done"; ` +
+				`priorities.critical: приоритет должен быть от 0 до 2; statuses.open: неизвестный статус "opened"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadMapping(strings.NewReader(tt.input))
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("неожиданная ошибка: %v", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("ошибка %v, ожидалось %q", err, tt.want)
+			}
+		})
+	}
+}
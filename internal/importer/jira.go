@@ -0,0 +1,254 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// jiraExport - ответ поиска Jira REST API (/rest/api/2/search или /rest/api/3/search);
+// выгрузка из нескольких страниц может быть массивом таких ответов или массивом задач
+type jiraExport struct {
+	Issues []jiraIssue `json:"issues"`
+}
+
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string          `json:"summary"`
+		Description json.RawMessage `json:"description"`
+		Status      struct {
+			Name string `json:"name"`
+		} `json:"status"`
+		Priority *struct {
+			Name string `json:"name"`
+		} `json:"priority"`
+		Reporter *jiraUser `json:"reporter"`
+		Creator  *jiraUser `json:"creator"`
+		Assignee *jiraUser `json:"assignee"`
+		Parent   *struct {
+			Key string `json:"key"`
+		} `json:"parent"`
+		Created string `json:"created"`
+		Updated string `json:"updated"`
+		DueDate string `json:"duedate"`
+		Comment struct {
+			Comments []struct {
+				Author  *jiraUser       `json:"author"`
+				Body    json.RawMessage `json:"body"`
+				Created string          `json:"created"`
+			} `json:"comments"`
+		} `json:"comment"`
+		Worklog struct {
+			Worklogs []struct {
+				Author           *jiraUser       `json:"author"`
+				TimeSpentSeconds int             `json:"timeSpentSeconds"`
+				Started          string          `json:"started"`
+				Comment          json.RawMessage `json:"comment"`
+			} `json:"worklogs"`
+		} `json:"worklog"`
+	} `json:"fields"`
+}
+
+// jiraUser - пользователь Jira Cloud (accountId) или Jira Server (name)
+type jiraUser struct {
+	EmailAddress string `json:"emailAddress"`
+	AccountID    string `json:"accountId"`
+	Name         string `json:"name"`
+}
+
+// id возвращает email пользователя, а если Jira его скрывает - accountId или имя
+func (u *jiraUser) id() string {
+	switch {
+	case u == nil:
+		return ""
+	case u.EmailAddress != "":
+		return u.EmailAddress
+	case u.AccountID != "":
+		return u.AccountID
+	}
+	return u.Name
+}
+
+func parseJira(r io.Reader) ([]*Issue, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := jiraIssues(bytes.TrimSpace(data))
+	if err != nil {
+		return nil, fmt.Errorf("неверная выгрузка Jira: %w", err)
+	}
+
+	issues := make([]*Issue, 0, len(raw))
+	for _, ji := range raw {
+		issue, err := ji.toIssue()
+		if err != nil {
+			return nil, fmt.Errorf("задача %s: %w", ji.Key, err)
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// jiraIssues принимает ответ поиска, массив ответов (страницы) или массив задач
+func jiraIssues(data []byte) ([]jiraIssue, error) {
+	if len(data) > 0 && data[0] == '{' {
+		var export jiraExport
+		if err := json.Unmarshal(data, &export); err != nil {
+			return nil, err
+		}
+		return export.Issues, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	issues := []jiraIssue{}
+	for _, item := range items {
+		var probe struct {
+			Issues json.RawMessage `json:"issues"`
+		}
+		if err := json.Unmarshal(item, &probe); err != nil {
+			return nil, err
+		}
+
+		if probe.Issues != nil {
+			var page jiraExport
+			if err := json.Unmarshal(item, &page); err != nil {
+				return nil, err
+			}
+			issues = append(issues, page.Issues...)
+			continue
+		}
+
+		var issue jiraIssue
+		if err := json.Unmarshal(item, &issue); err != nil {
+			return nil, err
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+func (ji *jiraIssue) toIssue() (*Issue, error) {
+	f := &ji.Fields
+	issue := &Issue{
+		Key:         ji.Key,
+		Title:       f.Summary,
+		Description: jiraText(f.Description),
+		Status:      f.Status.Name,
+		Reporter:    f.Reporter.id(),
+	}
+	if issue.Reporter == "" {
+		issue.Reporter = f.Creator.id()
+	}
+	if f.Priority != nil {
+		issue.Priority = f.Priority.Name
+	}
+	if assignee := f.Assignee.id(); assignee != "" {
+		issue.Assignees = []string{assignee}
+	}
+	if f.Parent != nil {
+		issue.ParentKey = f.Parent.Key
+	}
+
+	var err error
+	if issue.DueDate, err = parseTime(f.DueDate); err != nil {
+		return nil, err
+	}
+	if issue.CreatedAt, err = timeValue(f.Created); err != nil {
+		return nil, err
+	}
+	if issue.UpdatedAt, err = timeValue(f.Updated); err != nil {
+		return nil, err
+	}
+
+	for _, c := range f.Comment.Comments {
+		created, err := timeValue(c.Created)
+		if err != nil {
+			return nil, err
+		}
+		issue.Comments = append(issue.Comments, Comment{
+			Author:    c.Author.id(),
+			Body:      jiraText(c.Body),
+			CreatedAt: created,
+		})
+	}
+
+	for _, w := range f.Worklog.Worklogs {
+		started, err := timeValue(w.Started)
+		if err != nil {
+			return nil, err
+		}
+		issue.Worklogs = append(issue.Worklogs, Worklog{
+			Author:  w.Author.id(),
+			Hours:   float64(w.TimeSpentSeconds) / 3600,
+			Started: started,
+			Comment: jiraText(w.Comment),
+		})
+	}
+
+	return issue, nil
+}
+
+// jiraText возвращает текст поля: строку API v2 или текст документа ADF из API v3
+func jiraText(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+
+	var doc adfNode
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return ""
+	}
+	var b strings.Builder
+	doc.write(&b)
+	return strings.TrimSpace(b.String())
+}
+
+// adfNode - узел Atlassian Document Format
+type adfNode struct {
+	Type    string    `json:"type"`
+	Text    string    `json:"text"`
+	Content []adfNode `json:"content"`
+	Attrs   struct {
+		Text string `json:"text"`
+	} `json:"attrs"`
+}
+
+// write выводит текст узла; блоки разделяются переводами строк, элементы списков
+// начинаются с "- "
+func (n *adfNode) write(b *strings.Builder) {
+	switch n.Type {
+	case "text":
+		b.WriteString(n.Text)
+		return
+	case "hardBreak":
+		b.WriteString("\n")
+		return
+	case "mention", "emoji":
+		b.WriteString(n.Attrs.Text)
+		return
+	case "listItem":
+		b.WriteString("- ")
+	}
+
+	for i := range n.Content {
+		n.Content[i].write(b)
+	}
+
+	switch n.Type {
+	case "paragraph", "heading", "codeBlock", "blockquote", "rule":
+		b.WriteString("\n\n")
+	}
+}
@@ -0,0 +1,172 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// Mapping сопоставляет значения источника с Task Manager. Названия статусов и приоритетов
+// сравниваются без учёта регистра.
+type Mapping struct {
+	Statuses map[string]domain.TaskStatus
+	// DefaultStatus получают задачи с несопоставленным статусом; если он пуст, такие задачи
+	// не импортируются
+	DefaultStatus domain.TaskStatus
+	Priorities    map[string]int
+	// DefaultPriority получают задачи без приоритета или с несопоставленным приоритетом
+	DefaultPriority int
+	// Users - email сотрудника для пользователя источника, если тот указан не своим email
+	// (имя пользователя Trello, accountId Jira) или email различается
+	Users map[string]string
+	// DefaultCreator - email сотрудника, который становится создателем задач с несопоставленным
+	// автором; если он пуст, такие задачи не импортируются
+	DefaultCreator string
+}
+
+// mappingFile - файл сопоставления; отсутствующие значения не заменяют значения по умолчанию
+type mappingFile struct {
+	Statuses        map[string]domain.TaskStatus `yaml:"statuses"`
+	DefaultStatus   domain.TaskStatus            `yaml:"default_status"`
+	Priorities      map[string]int               `yaml:"priorities"`
+	DefaultPriority *int                         `yaml:"default_priority"`
+	Users           map[string]string            `yaml:"users"`
+	DefaultCreator  string                       `yaml:"default_creator"`
+}
+
+// DefaultMapping - распространённые названия статусов и приоритетов Jira и Trello
+func DefaultMapping() *Mapping {
+	return &Mapping{
+		Statuses: map[string]domain.TaskStatus{
+			"backlog":                  domain.TaskStatusNew,
+			"open":                     domain.TaskStatusNew,
+			"to do":                    domain.TaskStatusNew,
+			"todo":                     domain.TaskStatusNew,
+			"new":                      domain.TaskStatusNew,
+			"selected for development": domain.TaskStatusNew,
+			"in progress":              domain.TaskStatusInProgress,
+			"doing":                    domain.TaskStatusInProgress,
+			"in review":                domain.TaskStatusCodeReview,
+			"code review":              domain.TaskStatusCodeReview,
+			"review":                   domain.TaskStatusCodeReview,
+			"testing":                  domain.TaskStatusTesting,
+			"in testing":               domain.TaskStatusTesting,
+			"qa":                       domain.TaskStatusTesting,
+			"reopened":                 domain.TaskStatusReturnedWithErrors,
+			"done":                     domain.TaskStatusClosed,
+			"closed":                   domain.TaskStatusClosed,
+			"resolved":                 domain.TaskStatusClosed,
+		},
+		Priorities: map[string]int{
+			"highest": 2,
+			"high":    2,
+			"medium":  1,
+			"low":     0,
+			"lowest":  0,
+		},
+		DefaultPriority: 1,
+		Users:           map[string]string{},
+	}
+}
+
+// LoadMapping читает сопоставление в YAML (или JSON) поверх DefaultMapping: указанные
+// значения заменяют значения по умолчанию
+func LoadMapping(r io.Reader) (*Mapping, error) {
+	var loaded mappingFile
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&loaded); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("неверный файл сопоставления: %w", err)
+	}
+
+	m := DefaultMapping()
+	for name, status := range loaded.Statuses {
+		m.Statuses[normalize(name)] = status
+	}
+	for name, priority := range loaded.Priorities {
+		m.Priorities[normalize(name)] = priority
+	}
+	for user, email := range loaded.Users {
+		m.Users[user] = email
+	}
+	if loaded.DefaultStatus != "" {
+		m.DefaultStatus = loaded.DefaultStatus
+	}
+	if loaded.DefaultPriority != nil {
+		m.DefaultPriority = *loaded.DefaultPriority
+	}
+	m.DefaultCreator = loaded.DefaultCreator
+
+	return m, m.validate()
+}
+
+func (m *Mapping) validate() error {
+	problems := []string{}
+	for name, status := range m.Statuses {
+		if !status.IsValid() {
+			problems = append(problems, fmt.Sprintf("statuses.%s: неизвестный статус %q", name, status))
+		}
+	}
+	if m.DefaultStatus != "" && !m.DefaultStatus.IsValid() {
+		problems = append(problems, fmt.Sprintf("default_status: неизвестный статус %q", m.DefaultStatus))
+	}
+	for name, priority := range m.Priorities {
+		if !isValidPriority(priority) {
+			problems = append(problems, fmt.Sprintf("priorities.%s: приоритет должен быть от 0 до 2", name))
+		}
+	}
+	if !isValidPriority(m.DefaultPriority) {
+		problems = append(problems, "default_priority: приоритет должен быть от 0 до 2")
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("неверный файл сопоставления: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Status возвращает статус для статуса источника; ok=false, если статус не сопоставлен
+// (тогда возвращается DefaultStatus, возможно пустой)
+func (m *Mapping) Status(name string) (domain.TaskStatus, bool) {
+	if status, ok := m.Statuses[normalize(name)]; ok {
+		return status, true
+	}
+	return m.DefaultStatus, false
+}
+
+// Priority возвращает приоритет; ok=false, если указанный приоритет не сопоставлен.
+// Пустой приоритет источника даёт DefaultPriority без ошибки.
+func (m *Mapping) Priority(name string) (int, bool) {
+	if strings.TrimSpace(name) == "" {
+		return m.DefaultPriority, true
+	}
+	if priority, ok := m.Priorities[normalize(name)]; ok {
+		return priority, true
+	}
+	return m.DefaultPriority, false
+}
+
+// Email возвращает email сотрудника для пользователя источника или "", если пользователь
+// не сопоставлен и сам не похож на email
+func (m *Mapping) Email(user string) string {
+	if email, ok := m.Users[user]; ok {
+		return email
+	}
+	if strings.Contains(user, "@") {
+		return user
+	}
+	return ""
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func isValidPriority(priority int) bool {
+	return priority >= 0 && priority <= 2
+}
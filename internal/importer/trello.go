@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// trelloBoard - выгрузка доски Trello в JSON (Меню - Печать и экспорт - Экспорт в JSON).
+// Трекер не хранит email участников, поэтому пользователи задаются именами Trello
+// и сопоставляются через Mapping.Users.
+type trelloBoard struct {
+	Lists []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"lists"`
+	Members []struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"members"`
+	Cards []struct {
+		ID        string   `json:"id"`
+		Name      string   `json:"name"`
+		Desc      string   `json:"desc"`
+		IDList    string   `json:"idList"`
+		IDMembers []string `json:"idMembers"`
+		Due       string   `json:"due"`
+		Closed    bool     `json:"closed"`
+		// DateLastActivity - время последнего изменения карточки
+		DateLastActivity string `json:"dateLastActivity"`
+	} `json:"cards"`
+	Actions []struct {
+		Type            string `json:"type"`
+		Date            string `json:"date"`
+		IDMemberCreator string `json:"idMemberCreator"`
+		Data            struct {
+			Text string `json:"text"`
+			Card struct {
+				ID string `json:"id"`
+			} `json:"card"`
+		} `json:"data"`
+	} `json:"actions"`
+}
+
+func parseTrello(r io.Reader) ([]*Issue, error) {
+	var board trelloBoard
+	if err := json.NewDecoder(r).Decode(&board); err != nil {
+		return nil, fmt.Errorf("неверная выгрузка Trello: %w", err)
+	}
+
+	lists := make(map[string]string, len(board.Lists))
+	for _, l := range board.Lists {
+		lists[l.ID] = l.Name
+	}
+	members := make(map[string]string, len(board.Members))
+	for _, m := range board.Members {
+		members[m.ID] = m.Username
+	}
+
+	issues := make([]*Issue, 0, len(board.Cards))
+	byID := make(map[string]*Issue, len(board.Cards))
+	for _, card := range board.Cards {
+		issue := &Issue{
+			Key:         card.ID,
+			Title:       card.Name,
+			Description: card.Desc,
+			Status:      lists[card.IDList],
+			Archived:    card.Closed,
+			CreatedAt:   trelloCreatedAt(card.ID),
+		}
+		for _, id := range card.IDMembers {
+			issue.Assignees = append(issue.Assignees, members[id])
+		}
+
+		var err error
+		if issue.DueDate, err = parseTime(card.Due); err != nil {
+			return nil, fmt.Errorf("карточка %s: %w", card.ID, err)
+		}
+		if issue.UpdatedAt, err = timeValue(card.DateLastActivity); err != nil {
+			return nil, fmt.Errorf("карточка %s: %w", card.ID, err)
+		}
+
+		issues = append(issues, issue)
+		byID[card.ID] = issue
+	}
+
+	// Действия выгружаются от новых к старым; комментарии нужны в порядке написания
+	for i := len(board.Actions) - 1; i >= 0; i-- {
+		action := board.Actions[i]
+		issue, ok := byID[action.Data.Card.ID]
+		if !ok {
+			continue
+		}
+
+		switch action.Type {
+		case "createCard":
+			issue.Reporter = members[action.IDMemberCreator]
+		case "commentCard":
+			created, err := timeValue(action.Date)
+			if err != nil {
+				return nil, fmt.Errorf("комментарий к карточке %s: %w", issue.Key, err)
+			}
+			issue.Comments = append(issue.Comments, Comment{
+				Author:    members[action.IDMemberCreator],
+				Body:      action.Data.Text,
+				CreatedAt: created,
+			})
+		}
+	}
+
+	return issues, nil
+}
+
+// trelloCreatedAt извлекает время создания из идентификатора карточки: первые 8 шестнадцатеричных
+// цифр - секунды Unix, как в ObjectId MongoDB
+func trelloCreatedAt(id string) time.Time {
+	if len(id) < 8 {
+		return time.Time{}
+	}
+	seconds, err := strconv.ParseInt(id[:8], 16, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0).UTC()
+}
@@ -0,0 +1,108 @@
+// Package metrics описывает метрики Prometheus сервиса. Метрики регистрируются в реестре
+// по умолчанию и отдаются обработчиком Handler на /metrics.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+)
+
+const namespace = "taskmanager"
+
+// HTTP-запросы; route - шаблон маршрута mux (например /api/v1/tasks/{id}), а не путь запроса,
+// чтобы число рядов не зависело от идентификаторов в URL
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Число обработанных HTTP-запросов",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Длительность обработки HTTP-запросов",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+// Доменные события
+var (
+	TasksCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tasks_created_total",
+		Help:      "Число созданных задач",
+	})
+
+	StatusTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "task_status_transitions_total",
+		Help:      "Число смен статуса задач",
+	}, []string{"from", "to"})
+
+	LoginFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_failures_total",
+		Help:      "Число неудачных попыток входа",
+	})
+)
+
+// RegisterDB добавляет метрики пула соединений с базой данных из sql.DBStats
+func RegisterDB(db *sql.DB, name string) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterRedis добавляет метрики пула соединений Redis
+func RegisterRedis(client *redis.Client) {
+	prometheus.MustRegister(&redisPoolCollector{client: client})
+}
+
+// Handler отдаёт метрики в формате Prometheus
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+var (
+	redisHits = prometheus.NewDesc(namespace+"_redis_pool_hits_total",
+		"Число случаев, когда в пуле нашлось свободное соединение", nil, nil)
+	redisMisses = prometheus.NewDesc(namespace+"_redis_pool_misses_total",
+		"Число случаев, когда свободного соединения в пуле не нашлось", nil, nil)
+	redisTimeouts = prometheus.NewDesc(namespace+"_redis_pool_timeouts_total",
+		"Число таймаутов ожидания соединения из пула", nil, nil)
+	redisTotalConns = prometheus.NewDesc(namespace+"_redis_pool_connections",
+		"Число соединений в пуле", nil, nil)
+	redisIdleConns = prometheus.NewDesc(namespace+"_redis_pool_idle_connections",
+		"Число простаивающих соединений в пуле", nil, nil)
+	redisStaleConns = prometheus.NewDesc(namespace+"_redis_pool_stale_connections_total",
+		"Число устаревших соединений, удалённых из пула", nil, nil)
+)
+
+// redisPoolCollector читает статистику пула Redis при каждом сборе метрик
+type redisPoolCollector struct {
+	client *redis.Client
+}
+
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- redisHits
+	ch <- redisMisses
+	ch <- redisTimeouts
+	ch <- redisTotalConns
+	ch <- redisIdleConns
+	ch <- redisStaleConns
+}
+
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(redisHits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(redisMisses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(redisTimeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(redisTotalConns, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(redisIdleConns, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(redisStaleConns, prometheus.CounterValue, float64(stats.StaleConns))
+}
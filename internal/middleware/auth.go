@@ -7,12 +7,16 @@ import (
 
 	"github.com/dmitry/taskmanager/internal/service"
 	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/logger"
 	"github.com/google/uuid"
 )
 
 type contextKey string
 
-const EmployeeIDKey contextKey = "employee_id"
+const (
+	EmployeeIDKey contextKey = "employee_id"
+	RequestIDKey  contextKey = "request_id"
+)
 
 func AuthMiddleware(jwtService *service.JWTService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -38,6 +42,7 @@ func AuthMiddleware(jwtService *service.JWTService) func(http.Handler) http.Hand
 			}
 
 			ctx := context.WithValue(r.Context(), EmployeeIDKey, claims.EmployeeID)
+			ctx = logger.NewContext(ctx, logger.FromContext(ctx).With("employee_id", claims.EmployeeID))
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -51,6 +56,12 @@ func GetEmployeeIDFromContext(ctx context.Context) (uuid.UUID, error) {
 	return employeeID, nil
 }
 
+// GetRequestIDFromContext возвращает идентификатор запроса, заданный LoggingMiddleware
+func GetRequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(RequestIDKey).(string)
+	return requestID
+}
+
 func respondError(w http.ResponseWriter, err error) {
 	appErr, ok := err.(*errors.AppError)
 	if !ok {
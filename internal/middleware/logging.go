@@ -1,13 +1,21 @@
 package middleware
 
 import (
+	"context"
+	"math"
+	"math/rand/v2"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/dmitry/taskmanager/pkg/logger"
 	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 )
 
+// maxRequestIDLength ограничивает входящий X-Request-ID, чтобы клиент не раздувал журнал
+const maxRequestIDLength = 128
+
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
@@ -25,10 +33,48 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return size, err
 }
 
-func LoggingMiddleware(logger *logger.Logger) func(http.Handler) http.Handler {
+// Unwrap открывает исходный ResponseWriter для http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// LogSampling - доля запросов, для которых пишутся информационные записи журнала;
+// меняется на ходу при перезагрузке конфигурации
+type LogSampling struct {
+	bits atomic.Uint64
+}
+
+func NewLogSampling(rate float64) *LogSampling {
+	s := &LogSampling{}
+	s.Set(rate)
+	return s
+}
+
+func (s *LogSampling) Set(rate float64) {
+	s.bits.Store(math.Float64bits(rate))
+}
+
+func (s *LogSampling) Rate() float64 {
+	return math.Float64frombits(s.bits.Load())
+}
+
+// keep решает, попадает ли очередной запрос в выборку
+func (s *LogSampling) keep() bool {
+	rate := s.Rate()
+	return rate >= 1 || rand.Float64() < rate
+}
+
+// LoggingMiddleware пишет журнал запросов и кладёт в контекст логгер запроса с request_id,
+// маршрутом и идентификаторами трассировки (см. logger.FromContext). Входящий X-Request-ID
+// сохраняется, если он корректен, иначе генерируется новый. Информационные записи пишутся
+// для доли запросов из sampling; ответы 5xx пишутся всегда.
+func LoggingMiddleware(log *logger.Logger, sampling *LogSampling) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			requestID := uuid.New().String()
+			requestID := r.Header.Get("X-Request-ID")
+			if !isValidRequestID(requestID) {
+				requestID = uuid.New().String()
+			}
 			start := time.Now()
 
 			wrapped := &responseWriter{
@@ -38,8 +84,13 @@ func LoggingMiddleware(logger *logger.Logger) func(http.Handler) http.Handler {
 
 			wrapped.Header().Set("X-Request-ID", requestID)
 
-			logger.Info("входящий_запрос",
-				"request_id", requestID,
+			requestLog := log.WithTrace(r.Context()).With("request_id", requestID, "route", routeTemplate(r))
+			sampledLog := requestLog.Sampled(sampling.keep())
+
+			ctx := context.WithValue(r.Context(), RequestIDKey, requestID)
+			ctx = logger.NewContext(ctx, sampledLog)
+
+			sampledLog.Info("входящий_запрос",
 				"method", r.Method,
 				"path", r.URL.Path,
 				"query", r.URL.RawQuery,
@@ -47,11 +98,15 @@ func LoggingMiddleware(logger *logger.Logger) func(http.Handler) http.Handler {
 				"user_agent", r.UserAgent(),
 			)
 
-			next.ServeHTTP(wrapped, r)
+			next.ServeHTTP(wrapped, r.WithContext(ctx))
+
+			completedLog := sampledLog
+			if wrapped.statusCode >= http.StatusInternalServerError {
+				completedLog = requestLog
+			}
 
 			duration := time.Since(start)
-			logger.Info("запрос_завершён",
-				"request_id", requestID,
+			completedLog.Info("запрос_завершён",
 				"status", wrapped.statusCode,
 				"duration_ms", duration.Milliseconds(),
 				"size", wrapped.size,
@@ -59,3 +114,26 @@ func LoggingMiddleware(logger *logger.Logger) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// routeTemplate возвращает шаблон маршрута mux, например /api/v1/tasks/{id}
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return ""
+}
+
+// isValidRequestID допускает только печатные ASCII-символы без пробелов
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// analyticsDays - дни периода: $1 - первый, $2 - последний
+const analyticsDays = `SELECT generate_series($1::date, $2::date, interval '1 day')::date AS day`
+
+// statusAtDayEnd - статус задачи s.id на конец дня d.day; строки нет, если задача ещё не создана
+const statusAtDayEnd = `
+	SELECT tr.to_status AS status
+	FROM task_status_transitions tr
+	WHERE tr.task_id = s.id AND tr.changed_at < d.day + 1
+	ORDER BY tr.changed_at DESC, tr.id DESC
+	LIMIT 1`
+
+// flowPercentiles - перцентили, возвращаемые FlowTimes
+const flowPercentiles = `ARRAY[0.5, 0.75, 0.9, 0.95]`
+
+type analyticsRepository struct {
+	db *sql.DB
+}
+
+func NewAnalyticsRepository(db *sql.DB) AnalyticsRepository {
+	return &analyticsRepository{db: db}
+}
+
+func (r *analyticsRepository) Burndown(ctx context.Context, filter TaskFilter, sprintID *uuid.UUID, from, to time.Time) ([]*domain.BurndownPoint, error) {
+	args := []interface{}{from, to}
+	scope := `SELECT t.id, NULL::timestamptz AS added_at, NULL::timestamptz AS removed_at FROM tasks t`
+	if sprintID != nil {
+		args = append(args, *sprintID)
+		scope = `SELECT t.id, st.added_at, st.removed_at FROM tasks t
+			INNER JOIN sprint_tasks st ON st.task_id = t.id AND st.sprint_id = $3`
+	}
+
+	conditions, conditionArgs, _ := buildTaskConditions(filter, len(args)+1)
+	args = append(args, conditionArgs...)
+
+	query := `
+		WITH days AS (` + analyticsDays + `),
+		scope AS (` + scope + ` WHERE t.deleted_at IS NULL` + conditions + `)
+		SELECT d.day, COUNT(st.status), COUNT(st.status) FILTER (WHERE st.status = 'closed')
+		FROM days d
+		LEFT JOIN scope s ON (s.added_at IS NULL OR s.added_at < d.day + 1)
+			AND (s.removed_at IS NULL OR s.removed_at >= d.day + 1)
+		LEFT JOIN LATERAL (` + statusAtDayEnd + `
+		) st ON true
+		GROUP BY d.day
+		ORDER BY d.day
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось построить диаграмму сгорания")
+	}
+	defer rows.Close()
+
+	points := []*domain.BurndownPoint{}
+	for rows.Next() {
+		p := &domain.BurndownPoint{}
+		if err := rows.Scan(&p.Date, &p.Scope, &p.Completed); err != nil {
+			return nil, errors.Internal(err, "Не удалось построить диаграмму сгорания")
+		}
+		p.Remaining = p.Scope - p.Completed
+		points = append(points, p)
+	}
+
+	return points, nil
+}
+
+func (r *analyticsRepository) CumulativeFlow(ctx context.Context, filter TaskFilter, from, to time.Time) ([]*domain.FlowPoint, error) {
+	conditions, conditionArgs, _ := buildTaskConditions(filter, 3)
+	args := append([]interface{}{from, to}, conditionArgs...)
+
+	query := `
+		WITH days AS (` + analyticsDays + `),
+		scope AS (SELECT t.id FROM tasks t WHERE t.deleted_at IS NULL` + conditions + `)
+		SELECT d.day, st.status, COUNT(*)
+		FROM days d
+		CROSS JOIN scope s
+		INNER JOIN LATERAL (` + statusAtDayEnd + `
+		) st ON true
+		GROUP BY d.day, st.status
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось построить диаграмму накопленного потока")
+	}
+	defer rows.Close()
+
+	// Дни без задач и статусы без задач тоже попадают в результат с нулями
+	points := []*domain.FlowPoint{}
+	byDay := map[string]*domain.FlowPoint{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		p := &domain.FlowPoint{Date: day, Counts: map[domain.TaskStatus]int{}}
+		for _, status := range domain.BoardStatuses {
+			p.Counts[status] = 0
+		}
+		points = append(points, p)
+		byDay[day.Format("2006-01-02")] = p
+	}
+
+	for rows.Next() {
+		var day time.Time
+		var status domain.TaskStatus
+		var count int
+		if err := rows.Scan(&day, &status, &count); err != nil {
+			return nil, errors.Internal(err, "Не удалось построить диаграмму накопленного потока")
+		}
+		if p := byDay[day.Format("2006-01-02")]; p != nil {
+			p.Counts[status] = count
+		}
+	}
+
+	return points, nil
+}
+
+func (r *analyticsRepository) FlowTimes(ctx context.Context, filter TaskFilter, from, to time.Time) ([]*domain.FlowTimeStats, error) {
+	conditions, conditionArgs, _ := buildTaskConditions(filter, 3)
+	args := append([]interface{}{from, to}, conditionArgs...)
+
+	// Задача с исполнителями из нескольких отделов учитывается в каждом из них,
+	// задача без исполнителя - в команде с пустым названием
+	query := `
+		WITH times AS (
+			SELECT t.id,
+				EXTRACT(EPOCH FROM (c.closed_at - t.created_at)) / 3600 AS lead_hours,
+				EXTRACT(EPOCH FROM (c.closed_at - w.started_at)) / 3600 AS cycle_hours
+			FROM tasks t
+			CROSS JOIN LATERAL (
+				SELECT MAX(tr.changed_at) AS closed_at FROM task_status_transitions tr
+				WHERE tr.task_id = t.id AND tr.to_status = 'closed'
+			) c
+			CROSS JOIN LATERAL (
+				SELECT MIN(tr.changed_at) AS started_at FROM task_status_transitions tr
+				WHERE tr.task_id = t.id AND tr.to_status = 'in_progress'
+			) w
+			WHERE t.deleted_at IS NULL AND t.status = 'closed'
+				AND c.closed_at >= $1::date AND c.closed_at < $2::date + 1` + conditions + `
+		),
+		teams AS (
+			SELECT DISTINCT tm.id, tm.lead_hours, tm.cycle_hours, COALESCE(e.department, '') AS team
+			FROM times tm
+			LEFT JOIN task_participants p ON p.task_id = tm.id AND p.role = 'executor'
+			LEFT JOIN employees e ON e.id = p.employee_id
+		)
+		SELECT team, COUNT(*),
+			percentile_cont(` + flowPercentiles + `) WITHIN GROUP (ORDER BY lead_hours),
+			percentile_cont(` + flowPercentiles + `) WITHIN GROUP (ORDER BY cycle_hours)
+		FROM teams
+		GROUP BY team
+		ORDER BY team
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось рассчитать время выполнения задач")
+	}
+	defer rows.Close()
+
+	stats := []*domain.FlowTimeStats{}
+	for rows.Next() {
+		s := &domain.FlowTimeStats{}
+		var lead, cycle []float64
+		if err := rows.Scan(&s.Team, &s.Tasks, pq.Array(&lead), pq.Array(&cycle)); err != nil {
+			return nil, errors.Internal(err, "Не удалось рассчитать время выполнения задач")
+		}
+		s.LeadTime = toPercentiles(lead)
+		if len(cycle) > 0 {
+			p := toPercentiles(cycle)
+			s.CycleTime = &p
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, nil
+}
+
+func toPercentiles(values []float64) domain.Percentiles {
+	var p domain.Percentiles
+	if len(values) == 4 {
+		p.P50, p.P75, p.P90, p.P95 = values[0], values[1], values[2], values[3]
+	}
+	return p
+}
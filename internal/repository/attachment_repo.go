@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// attachmentBlobLockClass - первая часть ключа advisory-блокировки файла вложения; вторая -
+// хеш контрольной суммы. Блокировка сериализует загрузку одинакового содержимого и удаление
+// файла, на который больше не ссылается ни одно вложение.
+const attachmentBlobLockClass int32 = 0x626c6f62
+
+type attachmentRepository struct {
+	db *sql.DB
+}
+
+func NewAttachmentRepository(db *sql.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func (r *attachmentRepository) CreateWithTx(ctx context.Context, tx *sql.Tx, a *domain.Attachment) error {
+	query := `
+		INSERT INTO attachments (id, task_id, message_id, uploaded_by, file_name, content_type, size_bytes, checksum, storage_key, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := tx.ExecContext(ctx, query, a.ID, a.TaskID, a.MessageID, a.UploadedBy,
+		a.FileName, a.ContentType, a.SizeBytes, a.Checksum, a.StorageKey, a.CreatedAt)
+	if err != nil {
+		return errors.Internal(err, "Не удалось сохранить вложение")
+	}
+
+	return nil
+}
+
+func (r *attachmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Attachment, error) {
+	query := `
+		SELECT id, task_id, message_id, uploaded_by, file_name, content_type, size_bytes, checksum, storage_key, created_at
+		FROM attachments
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	a := &domain.Attachment{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&a.ID, &a.TaskID, &a.MessageID, &a.UploadedBy, &a.FileName,
+		&a.ContentType, &a.SizeBytes, &a.Checksum, &a.StorageKey, &a.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("Вложение не найдено")
+	}
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить вложение")
+	}
+
+	return a, nil
+}
+
+func (r *attachmentRepository) GetByTask(ctx context.Context, taskID uuid.UUID) ([]*domain.Attachment, error) {
+	query := `
+		SELECT id, task_id, message_id, uploaded_by, file_name, content_type, size_bytes, checksum, storage_key, created_at
+		FROM attachments
+		WHERE task_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at ASC
+	`
+
+	return r.list(ctx, query, taskID)
+}
+
+func (r *attachmentRepository) GetByMessage(ctx context.Context, messageID uuid.UUID) ([]*domain.Attachment, error) {
+	query := `
+		SELECT id, task_id, message_id, uploaded_by, file_name, content_type, size_bytes, checksum, storage_key, created_at
+		FROM attachments
+		WHERE message_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at ASC
+	`
+
+	return r.list(ctx, query, messageID)
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE attachments SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return errors.Internal(err, "Не удалось удалить вложение")
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NotFound("Вложение не найдено")
+	}
+
+	return nil
+}
+
+func (r *attachmentRepository) LockBlobWithTx(ctx context.Context, tx *sql.Tx, checksum string) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, attachmentBlobLockClass, checksum); err != nil {
+		return errors.Internal(err, "Не удалось заблокировать файл вложения")
+	}
+	return nil
+}
+
+func (r *attachmentRepository) CountByChecksumWithTx(ctx context.Context, tx *sql.Tx, checksum string) (int, error) {
+	query := `SELECT COUNT(*) FROM attachments WHERE checksum = $1 AND deleted_at IS NULL`
+
+	var count int
+	if err := tx.QueryRowContext(ctx, query, checksum).Scan(&count); err != nil {
+		return 0, errors.Internal(err, "Не удалось подсчитать вложения")
+	}
+
+	return count, nil
+}
+
+func (r *attachmentRepository) list(ctx context.Context, query string, args ...interface{}) ([]*domain.Attachment, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить список вложений")
+	}
+	defer rows.Close()
+
+	attachments := []*domain.Attachment{}
+	for rows.Next() {
+		a := &domain.Attachment{}
+		err := rows.Scan(&a.ID, &a.TaskID, &a.MessageID, &a.UploadedBy, &a.FileName,
+			&a.ContentType, &a.SizeBytes, &a.Checksum, &a.StorageKey, &a.CreatedAt)
+		if err != nil {
+			return nil, errors.Internal(err, "Не удалось обработать данные вложения")
+		}
+		attachments = append(attachments, a)
+	}
+
+	return attachments, nil
+}
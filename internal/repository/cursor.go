@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultCursorLimit = 20
+	maxCursorLimit     = 100
+)
+
+// columnKind - тип значения ключа сортировки, нужен для разбора курсора
+type columnKind int
+
+const (
+	kindInt columnKind = iota
+	kindString
+	kindTime
+	kindUUID
+)
+
+// cursorPayload - содержимое курсора: сортировка, для которой он выдан,
+// и значения её ключей у последней записи страницы
+type cursorPayload struct {
+	Sort   string            `json:"s"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// keysetKey - ключ сортировки и значение из курсора (nil соответствует NULL)
+type keysetKey struct {
+	expr  string
+	desc  bool
+	kind  columnKind
+	value interface{}
+}
+
+// encodeCursor упаковывает значения ключей в непрозрачную строку
+func encodeCursor(sort string, values []interface{}) (string, error) {
+	payload := cursorPayload{Sort: sort, Values: make([]json.RawMessage, len(values))}
+	for i, v := range values {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return "", errors.Internal(err, "Не удалось сформировать курсор")
+		}
+		payload.Values[i] = raw
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", errors.Internal(err, "Не удалось сформировать курсор")
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor заполняет значения ключей из курсора. Курсор, выданный для другой
+// сортировки или повреждённый, отклоняется.
+func decodeCursor(cursor, sort string, keys []keysetKey) error {
+	invalid := errors.BadRequest("Недействительный курсор")
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return invalid
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return invalid
+	}
+	if payload.Sort != sort {
+		return errors.BadRequest("Курсор выдан для другой сортировки")
+	}
+	if len(payload.Values) != len(keys) {
+		return invalid
+	}
+
+	for i := range keys {
+		value, err := decodeCursorValue(keys[i].kind, payload.Values[i])
+		if err != nil {
+			return invalid
+		}
+		keys[i].value = value
+	}
+
+	return nil
+}
+
+func decodeCursorValue(kind columnKind, raw json.RawMessage) (interface{}, error) {
+	if string(raw) == "null" {
+		return nil, nil
+	}
+
+	switch kind {
+	case kindInt:
+		var v int
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	case kindString:
+		var v string
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	case kindTime:
+		var v time.Time
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	case kindUUID:
+		var v uuid.UUID
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	}
+
+	return nil, fmt.Errorf("неизвестный тип ключа: %d", kind)
+}
+
+// buildKeysetCondition формирует условие "строго после записи из курсора" для
+// сортировки по keys с NULLS LAST в обоих направлениях:
+//
+//	(k1 после v1) OR (k1 = v1 AND k2 после v2) OR ...
+func buildKeysetCondition(keys []keysetKey, argPos int) (string, []interface{}, int) {
+	args := []interface{}{}
+	equals := []string{}
+	alternatives := []string{}
+
+	for _, key := range keys {
+		var after, equal string
+
+		if key.value == nil {
+			// После NULL при NULLS LAST идут только другие NULL
+			equal = key.expr + " IS NULL"
+		} else {
+			op := ">"
+			if key.desc {
+				op = "<"
+			}
+			after = fmt.Sprintf("(%s %s $%d OR %s IS NULL)", key.expr, op, argPos, key.expr)
+			equal = fmt.Sprintf("%s = $%d", key.expr, argPos)
+			args = append(args, key.value)
+			argPos++
+		}
+
+		if after != "" {
+			alternatives = append(alternatives, "("+strings.Join(append(append([]string{}, equals...), after), " AND ")+")")
+		}
+		equals = append(equals, equal)
+	}
+
+	if len(alternatives) == 0 {
+		return " AND FALSE", args, argPos
+	}
+
+	return " AND (" + strings.Join(alternatives, " OR ") + ")", args, argPos
+}
+
+// NormalizeCursorLimit ограничивает размер страницы в режиме курсора
+func NormalizeCursorLimit(limit int) int {
+	if limit < 1 {
+		return defaultCursorLimit
+	}
+	if limit > maxCursorLimit {
+		return maxCursorLimit
+	}
+	return limit
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	id := uuid.MustParse("3f1c2b9e-7a45-4d1e-9c0b-2f6a8e4d5c71")
+	due := time.Date(2024, 3, 4, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		kinds  []columnKind
+		values []interface{}
+	}{
+		{"int", []columnKind{kindInt, kindUUID}, []interface{}{2, id}},
+		{"string", []columnKind{kindString, kindUUID}, []interface{}{"Задача \"в кавычках\"", id}},
+		{"time", []columnKind{kindTime, kindUUID}, []interface{}{due, id}},
+		{"null", []columnKind{kindTime, kindUUID}, []interface{}{nil, id}},
+		{"several", []columnKind{kindInt, kindTime, kindString, kindUUID}, []interface{}{1, nil, "new", id}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := encodeCursor("sig", tt.values)
+			if err != nil {
+				t.Fatalf("encodeCursor: %v", err)
+			}
+
+			keys := make([]keysetKey, len(tt.kinds))
+			for i, kind := range tt.kinds {
+				keys[i].kind = kind
+			}
+			if err := decodeCursor(cursor, "sig", keys); err != nil {
+				t.Fatalf("decodeCursor: %v", err)
+			}
+
+			for i, key := range keys {
+				if got, want := key.value, tt.values[i]; !reflect.DeepEqual(got, want) {
+					t.Errorf("значение %d: получено %#v, ожидалось %#v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	valid, err := encodeCursor("-priority", []interface{}{2, uuid.New()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrongKind, err := encodeCursor("-priority", []interface{}{"два", uuid.New()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Курсоры выданы для ключей priority и id
+	tests := []struct {
+		name     string
+		cursor   string
+		sort     string
+		keyCount int
+		message  string
+	}{
+		{"другая сортировка", valid, "priority", 2, "Курсор выдан для другой сортировки"},
+		{"не base64", "***", "-priority", 2, "Недействительный курсор"},
+		{"не JSON", "bm90LWpzb24", "-priority", 2, "Недействительный курсор"},
+		{"другое число ключей", valid, "-priority", 1, "Недействительный курсор"},
+		{"неверный тип значения", wrongKind, "-priority", 2, "Недействительный курсор"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := []keysetKey{{kind: kindInt}, {kind: kindUUID}}[:tt.keyCount]
+
+			err := decodeCursor(tt.cursor, tt.sort, keys)
+			appErr, ok := err.(*errors.AppError)
+			if !ok || appErr.Code != errors.ErrCodeBadRequest || appErr.Message != tt.message {
+				t.Fatalf("получено %v, ожидалось %q", err, tt.message)
+			}
+		})
+	}
+}
+
+func TestTaskKeysetKeys(t *testing.T) {
+	tests := []struct {
+		sort      []SortField
+		signature string
+		exprs     []string
+	}{
+		{nil, "-created_at", []string{"t.created_at", "t.id"}},
+		{[]SortField{{Field: "priority"}}, "priority", []string{"t.priority", "t.id"}},
+		{[]SortField{{Field: "status", Desc: true}, {Field: "title"}}, "-status,title", []string{"t.status", "t.title", "t.id"}},
+		{[]SortField{{Field: "due_date"}}, "due_date", []string{"t.due_date", "t.id"}},
+		{[]SortField{{Field: "updated_at", Desc: true}}, "-updated_at", []string{"t.updated_at", "t.id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.signature, func(t *testing.T) {
+			keys, signature, err := taskKeysetKeys(tt.sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if signature != tt.signature {
+				t.Errorf("подпись %q, ожидалась %q", signature, tt.signature)
+			}
+
+			exprs := []string{}
+			for _, key := range keys {
+				exprs = append(exprs, key.expr)
+			}
+			if !reflect.DeepEqual(exprs, tt.exprs) {
+				t.Errorf("ключи %v, ожидались %v", exprs, tt.exprs)
+			}
+			if last := keys[len(keys)-1]; !last.desc || last.kind != kindUUID {
+				t.Errorf("последним ключом должен быть t.id по убыванию")
+			}
+		})
+	}
+
+	if _, _, err := taskKeysetKeys([]SortField{{Field: "cf.estimate"}}); err == nil {
+		t.Error("сортировка по пользовательскому полю должна отклоняться")
+	}
+	if _, _, err := taskKeysetKeys([]SortField{{Field: "password"}}); err == nil {
+		t.Error("неизвестное поле сортировки должно отклоняться")
+	}
+}
+
+func TestBuildKeysetCondition(t *testing.T) {
+	id := uuid.MustParse("3f1c2b9e-7a45-4d1e-9c0b-2f6a8e4d5c71")
+	due := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		keys []keysetKey
+		sql  string
+		args []interface{}
+		next int
+	}{
+		{
+			name: "priority asc",
+			keys: []keysetKey{{expr: "t.priority", kind: kindInt, value: 2}, {expr: "t.id", desc: true, kind: kindUUID, value: id}},
+			sql:  " AND (((t.priority > $3 OR t.priority IS NULL)) OR (t.priority = $3 AND (t.id < $4 OR t.id IS NULL)))",
+			args: []interface{}{2, id},
+			next: 5,
+		},
+		{
+			name: "status desc",
+			keys: []keysetKey{{expr: "t.status", desc: true, kind: kindString, value: "new"}, {expr: "t.id", desc: true, kind: kindUUID, value: id}},
+			sql:  " AND (((t.status < $3 OR t.status IS NULL)) OR (t.status = $3 AND (t.id < $4 OR t.id IS NULL)))",
+			args: []interface{}{"new", id},
+			next: 5,
+		},
+		{
+			name: "due_date со значением",
+			keys: []keysetKey{{expr: "t.due_date", kind: kindTime, value: due}, {expr: "t.id", desc: true, kind: kindUUID, value: id}},
+			sql:  " AND (((t.due_date > $3 OR t.due_date IS NULL)) OR (t.due_date = $3 AND (t.id < $4 OR t.id IS NULL)))",
+			args: []interface{}{due, id},
+			next: 5,
+		},
+		{
+			// После NULL при NULLS LAST идут только другие NULL с меньшим id
+			name: "due_date NULL",
+			keys: []keysetKey{{expr: "t.due_date", kind: kindTime}, {expr: "t.id", desc: true, kind: kindUUID, value: id}},
+			sql:  " AND ((t.due_date IS NULL AND (t.id < $3 OR t.id IS NULL)))",
+			args: []interface{}{id},
+			next: 4,
+		},
+		{
+			name: "несколько ключей с NULL посередине",
+			keys: []keysetKey{
+				{expr: "t.priority", desc: true, kind: kindInt, value: 1},
+				{expr: "t.due_date", kind: kindTime},
+				{expr: "t.id", desc: true, kind: kindUUID, value: id},
+			},
+			sql:  " AND (((t.priority < $3 OR t.priority IS NULL)) OR (t.priority = $3 AND t.due_date IS NULL AND (t.id < $4 OR t.id IS NULL)))",
+			args: []interface{}{1, id},
+			next: 5,
+		},
+		{
+			name: "все ключи NULL",
+			keys: []keysetKey{{expr: "t.due_date", kind: kindTime}},
+			sql:  " AND FALSE",
+			args: []interface{}{},
+			next: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, next := buildKeysetCondition(tt.keys, 3)
+			if sql != tt.sql {
+				t.Errorf("условие:\n%s\nожидалось:\n%s", sql, tt.sql)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("аргументы %v, ожидались %v", args, tt.args)
+			}
+			if next != tt.next {
+				t.Errorf("следующий плейсхолдер %d, ожидался %d", next, tt.next)
+			}
+		})
+	}
+}
+
+// TestTaskCursorFlow проходит путь курсора целиком: значения задачи -> курсор -> условие
+func TestTaskCursorFlow(t *testing.T) {
+	sort := []SortField{{Field: "due_date"}, {Field: "priority", Desc: true}}
+	task := domain.NewTask("Отчёт", "", 2, uuid.New(), nil)
+
+	keys, signature, err := taskKeysetKeys(sort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor, err := encodeCursor(signature, taskCursorValues(task, sort))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := decodeCursor(cursor, signature, keys); err != nil {
+		t.Fatal(err)
+	}
+
+	sql, args, _ := buildKeysetCondition(keys, 1)
+	want := " AND ((t.due_date IS NULL AND (t.priority < $1 OR t.priority IS NULL)) OR " +
+		"(t.due_date IS NULL AND t.priority = $1 AND (t.id < $2 OR t.id IS NULL)))"
+	if sql != want {
+		t.Errorf("условие:\n%s\nожидалось:\n%s", sql, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{2, task.ID}) {
+		t.Errorf("аргументы %v", args)
+	}
+
+	if err := decodeCursor(cursor, "-due_date,-priority", keys); err == nil {
+		t.Error("курсор другой сортировки должен отклоняться")
+	}
+}
+
+func TestNormalizeCursorLimit(t *testing.T) {
+	for limit, want := range map[int]int{-1: 20, 0: 20, 1: 1, 50: 50, 100: 100, 101: 100} {
+		if got := NormalizeCursorLimit(limit); got != want {
+			t.Errorf("NormalizeCursorLimit(%d) = %d, ожидалось %d", limit, got, want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const customFieldColumns = `id, project_id, key, name, type, options, created_by, created_at, updated_at`
+
+type customFieldRepository struct {
+	db *sql.DB
+}
+
+func NewCustomFieldRepository(db *sql.DB) CustomFieldRepository {
+	return &customFieldRepository{db: db}
+}
+
+func (r *customFieldRepository) Create(ctx context.Context, field *domain.CustomField) error {
+	options, err := json.Marshal(field.Options)
+	if err != nil {
+		return errors.Internal(err, "Не удалось сохранить варианты поля")
+	}
+
+	query := `
+		INSERT INTO custom_fields (id, project_id, key, name, type, options, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err = r.db.ExecContext(ctx, query, field.ID, field.ProjectID, field.Key, field.Name, field.Type,
+		options, field.CreatedBy, field.CreatedAt, field.UpdatedAt)
+	if err != nil {
+		return errors.Internal(err, "Не удалось создать поле")
+	}
+
+	return nil
+}
+
+func (r *customFieldRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.CustomField, error) {
+	query := `SELECT ` + customFieldColumns + ` FROM custom_fields WHERE id = $1`
+
+	field := &domain.CustomField{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(customFieldScanDest(field)...)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("Поле не найдено")
+	}
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить поле")
+	}
+
+	return field, nil
+}
+
+func (r *customFieldRepository) GetByKeys(ctx context.Context, keys []string) ([]*domain.CustomField, error) {
+	query := `SELECT ` + customFieldColumns + ` FROM custom_fields WHERE key = ANY($1)`
+
+	return r.query(ctx, query, pq.Array(keys))
+}
+
+func (r *customFieldRepository) KeyExists(ctx context.Context, key string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM custom_fields WHERE key = $1)`
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, key).Scan(&exists); err != nil {
+		return false, errors.Internal(err, "Не удалось проверить ключ поля")
+	}
+
+	return exists, nil
+}
+
+func (r *customFieldRepository) GetForEmployee(ctx context.Context, employeeID uuid.UUID) ([]*domain.CustomField, error) {
+	query := `
+		SELECT ` + customFieldColumns + `
+		FROM custom_fields f
+		WHERE f.project_id IS NULL OR EXISTS (
+			SELECT 1 FROM project_members pm WHERE pm.project_id = f.project_id AND pm.employee_id = $1
+		)
+		ORDER BY f.project_id NULLS FIRST, f.name ASC
+	`
+
+	return r.query(ctx, query, employeeID)
+}
+
+func (r *customFieldRepository) query(ctx context.Context, query string, args ...interface{}) ([]*domain.CustomField, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить поля")
+	}
+	defer rows.Close()
+
+	fields := []*domain.CustomField{}
+	for rows.Next() {
+		field := &domain.CustomField{}
+		if err := rows.Scan(customFieldScanDest(field)...); err != nil {
+			return nil, errors.Internal(err, "Не удалось обработать поле")
+		}
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// Update изменяет название и варианты поля; ключ и тип неизменны
+func (r *customFieldRepository) Update(ctx context.Context, field *domain.CustomField) error {
+	options, err := json.Marshal(field.Options)
+	if err != nil {
+		return errors.Internal(err, "Не удалось сохранить варианты поля")
+	}
+
+	query := `
+		UPDATE custom_fields
+		SET name = $2, options = $3
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err = r.db.QueryRowContext(ctx, query, field.ID, field.Name, options).Scan(&field.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return errors.NotFound("Поле не найдено")
+	}
+	if err != nil {
+		return errors.Internal(err, "Не удалось обновить поле")
+	}
+
+	return nil
+}
+
+// Delete удаляет поле вместе со всеми его значениями
+func (r *customFieldRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM custom_fields WHERE id = $1`, id)
+	if err != nil {
+		return errors.Internal(err, "Не удалось удалить поле")
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NotFound("Поле не найдено")
+	}
+
+	return nil
+}
+
+func (r *customFieldRepository) SetValueWithTx(ctx context.Context, tx *sql.Tx, taskID, fieldID uuid.UUID, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return errors.Internal(err, "Не удалось сохранить значение поля")
+	}
+
+	query := `
+		INSERT INTO task_custom_values (task_id, field_id, value, updated_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (task_id, field_id) DO UPDATE SET value = EXCLUDED.value, updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := tx.ExecContext(ctx, query, taskID, fieldID, data); err != nil {
+		return errors.Internal(err, "Не удалось сохранить значение поля")
+	}
+
+	return nil
+}
+
+func (r *customFieldRepository) DeleteValueWithTx(ctx context.Context, tx *sql.Tx, taskID, fieldID uuid.UUID) error {
+	query := `DELETE FROM task_custom_values WHERE task_id = $1 AND field_id = $2`
+
+	if _, err := tx.ExecContext(ctx, query, taskID, fieldID); err != nil {
+		return errors.Internal(err, "Не удалось удалить значение поля")
+	}
+
+	return nil
+}
+
+func customFieldScanDest(field *domain.CustomField) []interface{} {
+	return []interface{}{
+		&field.ID, &field.ProjectID, &field.Key, &field.Name, &field.Type, jsonColumn{&field.Options},
+		&field.CreatedBy, &field.CreatedAt, &field.UpdatedAt,
+	}
+}
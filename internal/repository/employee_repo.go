@@ -108,22 +108,13 @@ func (r *employeeRepository) GetAll(ctx context.Context, filter EmployeeFilter)
 		FROM employees
 		WHERE deleted_at IS NULL
 	`
-	countQuery := `SELECT COUNT(*) FROM employees WHERE deleted_at IS NULL`
 
-	args := []interface{}{}
-	argPos := 1
+	conditions, args, argPos := buildEmployeeConditions(filter, 1)
+	query += conditions
 
-	if filter.Department != "" {
-		query += fmt.Sprintf(" AND department = $%d", argPos)
-		countQuery += fmt.Sprintf(" AND department = $%d", argPos)
-		args = append(args, filter.Department)
-		argPos++
-	}
-
-	var total int
-	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
+	total, err := r.Count(ctx, filter)
 	if err != nil {
-		return nil, 0, errors.Internal(err, "Не удалось подсчитать сотрудников")
+		return nil, 0, err
 	}
 
 	if filter.Page < 1 {
@@ -134,12 +125,87 @@ func (r *employeeRepository) GetAll(ctx context.Context, filter EmployeeFilter)
 	}
 
 	offset := (filter.Page - 1) * filter.PageSize
-	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argPos, argPos+1)
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", argPos, argPos+1)
 	args = append(args, filter.PageSize, offset)
 
+	employees, err := r.queryEmployees(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return employees, total, nil
+}
+
+// GetAllByCursor возвращает страницу сотрудников после filter.Cursor (порядок: сначала новые)
+// и курсор следующей страницы (пустой, если страница последняя)
+func (r *employeeRepository) GetAllByCursor(ctx context.Context, filter EmployeeFilter) ([]*domain.Employee, string, error) {
+	query := `
+		SELECT id, name, department, position, email, created_at, updated_at
+		FROM employees
+		WHERE deleted_at IS NULL
+	`
+
+	conditions, args, argPos := buildEmployeeConditions(filter, 1)
+	query += conditions
+
+	keys := []keysetKey{
+		{expr: "created_at", desc: true, kind: kindTime},
+		{expr: "id", desc: true, kind: kindUUID},
+	}
+	const signature = "-created_at"
+
+	if filter.Cursor != "" {
+		if err := decodeCursor(filter.Cursor, signature, keys); err != nil {
+			return nil, "", err
+		}
+		keyset, keysetArgs, nextPos := buildKeysetCondition(keys, argPos)
+		query += keyset
+		args = append(args, keysetArgs...)
+		argPos = nextPos
+	}
+
+	limit := NormalizeCursorLimit(filter.Limit)
+	query += fmt.Sprintf(" ORDER BY created_at DESC NULLS LAST, id DESC LIMIT $%d", argPos)
+	args = append(args, limit+1)
+
+	employees, err := r.queryEmployees(ctx, query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(employees) <= limit {
+		return employees, "", nil
+	}
+
+	employees = employees[:limit]
+	last := employees[limit-1]
+	next, err := encodeCursor(signature, []interface{}{last.CreatedAt, last.ID})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return employees, next, nil
+}
+
+// Count возвращает количество сотрудников, подходящих под фильтр
+func (r *employeeRepository) Count(ctx context.Context, filter EmployeeFilter) (int, error) {
+	query := `SELECT COUNT(*) FROM employees WHERE deleted_at IS NULL`
+
+	conditions, args, _ := buildEmployeeConditions(filter, 1)
+	query += conditions
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return 0, errors.Internal(err, "Не удалось подсчитать сотрудников")
+	}
+
+	return total, nil
+}
+
+func (r *employeeRepository) queryEmployees(ctx context.Context, query string, args ...interface{}) ([]*domain.Employee, error) {
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, 0, errors.Internal(err, "Не удалось получить список сотрудников")
+		return nil, errors.Internal(err, "Не удалось получить список сотрудников")
 	}
 	defer rows.Close()
 
@@ -156,12 +222,41 @@ func (r *employeeRepository) GetAll(ctx context.Context, filter EmployeeFilter)
 			&employee.UpdatedAt,
 		)
 		if err != nil {
-			return nil, 0, errors.Internal(err, "Не удалось обработать данные сотрудника")
+			return nil, errors.Internal(err, "Не удалось обработать данные сотрудника")
 		}
 		employees = append(employees, employee)
 	}
 
-	return employees, total, nil
+	return employees, nil
+}
+
+func buildEmployeeConditions(filter EmployeeFilter, argPos int) (string, []interface{}, int) {
+	conditions := ""
+	args := []interface{}{}
+
+	if filter.Department != "" {
+		conditions += fmt.Sprintf(" AND department = $%d", argPos)
+		args = append(args, filter.Department)
+		argPos++
+	}
+
+	return conditions, args, argPos
+}
+
+func (r *employeeRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	query := `UPDATE employees SET password_hash = $1 WHERE id = $2 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, passwordHash, id)
+	if err != nil {
+		return errors.Internal(err, "Не удалось обновить пароль сотрудника")
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NotFound("Сотрудник не найден")
+	}
+
+	return nil
 }
 
 func (r *employeeRepository) Update(ctx context.Context, employee *domain.Employee) error {
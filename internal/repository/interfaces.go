@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/dmitry/taskmanager/internal/domain"
 	"github.com/google/uuid"
@@ -12,6 +13,10 @@ type EmployeeFilter struct {
 	Department string
 	Page       int
 	PageSize   int
+
+	// Keyset-пагинация: Cursor - значение next_cursor предыдущей страницы
+	Cursor string
+	Limit  int
 }
 
 type EmployeeRepository interface {
@@ -19,23 +24,81 @@ type EmployeeRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Employee, error)
 	GetByEmail(ctx context.Context, email string) (*domain.Employee, error)
 	GetAll(ctx context.Context, filter EmployeeFilter) ([]*domain.Employee, int, error)
+	GetAllByCursor(ctx context.Context, filter EmployeeFilter) ([]*domain.Employee, string, error)
+	Count(ctx context.Context, filter EmployeeFilter) (int, error)
 	Update(ctx context.Context, employee *domain.Employee) error
+	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
 type TaskFilter struct {
-	Status     []domain.TaskStatus
-	Priority   *int
-	Archived   *bool
-	EmployeeID *uuid.UUID
-	Page       int
-	PageSize   int
+	Status          []domain.TaskStatus
+	Priority        *int
+	PriorityMin     *int
+	PriorityMax     *int
+	Archived        *bool
+	CreatedBy       *uuid.UUID
+	ParticipantID   *uuid.UUID
+	ParticipantRole *domain.ParticipantRole
+	DueFrom         *time.Time
+	DueTo           *time.Time
+	CreatedFrom     *time.Time
+	CreatedTo       *time.Time
+	UpdatedFrom     *time.Time
+	UpdatedTo       *time.Time
+	Text            string
+	Sort            []SortField
+	Page            int
+	PageSize        int
+
+	// Keyset-пагинация: Cursor - значение next_cursor предыдущей страницы
+	Cursor string
+	Limit  int
+
+	ProjectID *uuid.UUID
+	ParentID  *uuid.UUID
+	// TopLevel оставляет только задачи без родителя
+	TopLevel bool
+	SprintID *uuid.UUID
+	// Backlog оставляет только задачи вне спринтов
+	Backlog bool
+	// LabelsAny - задачи хотя бы с одной из меток, LabelsAll - со всеми метками;
+	// имена сравниваются без учёта регистра
+	LabelsAny []string
+	LabelsAll []string
+	// CustomFields - условия по пользовательским полям. Перед выборкой сервис заполняет
+	// CustomFieldDefs - определения полей из условий и сортировки по ключу
+	CustomFields    []CustomFieldFilter
+	CustomFieldDefs map[string]*domain.CustomField
+	// VisibleTo ограничивает выборку задачами, доступными сотруднику:
+	// задачи вне проектов и задачи проектов, в которых он состоит
+	VisibleTo *uuid.UUID
+}
+
+// CustomFieldFilter - условие по пользовательскому полю: значение равно одному из Values
+// (для multi_select - содержит один из вариантов) и/или лежит в диапазоне [Min, Max]
+type CustomFieldFilter struct {
+	Key    string
+	Values []string
+	Min    string
+	Max    string
+}
+
+// CustomFieldPrefix - префикс пользовательского поля в параметрах фильтра и сортировки:
+// cf.story_points=5, sort=cf.story_points
+const CustomFieldPrefix = "cf."
+
+// SortField - поле сортировки списка; Desc задает обратный порядок
+type SortField struct {
+	Field string
+	Desc  bool
 }
 
 type TaskRepository interface {
 	Create(ctx context.Context, task *domain.Task) error
 	CreateWithTx(ctx context.Context, tx *sql.Tx, task *domain.Task) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Task, error)
+	GetByKey(ctx context.Context, key string) (*domain.Task, error)
 	GetAll(ctx context.Context, filter TaskFilter) ([]*domain.Task, int, error)
 	Update(ctx context.Context, task *domain.Task) error
 	UpdateWithTx(ctx context.Context, tx *sql.Tx, task *domain.Task) error
@@ -43,6 +106,31 @@ type TaskRepository interface {
 	UpdateStatus(ctx context.Context, id uuid.UUID, status domain.TaskStatus) (domain.TaskStatus, error)
 	Archive(ctx context.Context, id uuid.UUID) error
 	GetTasksForEmployee(ctx context.Context, employeeID uuid.UUID, filter TaskFilter) ([]*domain.Task, int, error)
+	GetAllByCursor(ctx context.Context, filter TaskFilter) ([]*domain.Task, string, error)
+	GetTasksForEmployeeByCursor(ctx context.Context, employeeID uuid.UUID, filter TaskFilter) ([]*domain.Task, string, error)
+	Count(ctx context.Context, filter TaskFilter) (int, error)
+	GetSubtasks(ctx context.Context, parentID uuid.UUID) ([]*domain.Task, error)
+	GetRollup(ctx context.Context, id uuid.UUID) (*domain.TaskRollup, error)
+	// SetParent переносит задачу под parentID (nil - сделать корневой); поддерево задачи
+	// должно уложиться в maxDepth уровней
+	SetParent(ctx context.Context, id uuid.UUID, parentID *uuid.UUID, maxDepth int) error
+	// CheckParentDepthWithTx блокирует иерархию задач до конца транзакции и проверяет, что
+	// поддерево высотой height поместится под parentID, не превысив maxDepth уровней
+	CheckParentDepthWithTx(ctx context.Context, tx *sql.Tx, parentID uuid.UUID, height, maxDepth int) error
+	// GetOpenBlockers возвращает незакрытые задачи, блокирующие указанную
+	GetOpenBlockers(ctx context.Context, id uuid.UUID) ([]*domain.Task, error)
+
+	GetBoard(ctx context.Context, statuses []domain.TaskStatus, filter TaskFilter, perColumn int) ([]*domain.Task, error)
+	CountByStatus(ctx context.Context, filter TaskFilter) (map[domain.TaskStatus]int, error)
+	// LockBoardWithTx сериализует перемещения задач по доске до конца транзакции
+	LockBoardWithTx(ctx context.Context, tx *sql.Tx) error
+	// GetForMoveWithTx перечитывает задачу с блокировкой строки до конца транзакции
+	GetForMoveWithTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*domain.Task, error)
+	CountInColumnWithTx(ctx context.Context, tx *sql.Tx, status domain.TaskStatus, projectID *uuid.UUID, excludeID uuid.UUID) (int, error)
+	GetBoardPositionWithTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) (domain.TaskStatus, string, error)
+	LastRankWithTx(ctx context.Context, tx *sql.Tx, status domain.TaskStatus) (string, error)
+	AdjacentRankWithTx(ctx context.Context, tx *sql.Tx, status domain.TaskStatus, rank string, excludeID uuid.UUID, before bool) (string, error)
+	MoveWithTx(ctx context.Context, tx *sql.Tx, id uuid.UUID, status domain.TaskStatus, rank string) (domain.TaskStatus, error)
 }
 
 type TaskParticipantRepository interface {
@@ -69,14 +157,26 @@ type TimeEntryFilter struct {
 	PageSize  int
 }
 
+// TimesheetFilter - отбор записей времени для табеля; From и To - даты включительно
+type TimesheetFilter struct {
+	EmployeeID *uuid.UUID
+	Department *string
+	From       time.Time
+	To         time.Time
+}
+
 type TimeEntryRepository interface {
 	Create(ctx context.Context, entry *domain.TimeEntry) error
+	CreateWithTx(ctx context.Context, tx *sql.Tx, entry *domain.TimeEntry) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.TimeEntry, error)
 	GetByTask(ctx context.Context, taskID uuid.UUID) ([]*domain.TimeEntry, error)
 	GetByEmployee(ctx context.Context, employeeID uuid.UUID, filter TimeEntryFilter) ([]*domain.TimeEntry, error)
 	Update(ctx context.Context, entry *domain.TimeEntry) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetTaskTimeSummary(ctx context.Context, taskID uuid.UUID) (*domain.TimeSummary, error)
+	// StreamTimesheet передаёт строки табеля в fn по мере чтения, упорядоченные по отделу,
+	// сотруднику, задаче и дню; ошибка fn прерывает чтение
+	StreamTimesheet(ctx context.Context, filter TimesheetFilter, fn func(*domain.TimesheetRow) error) error
 }
 
 type RefreshTokenRepository interface {
@@ -86,3 +186,192 @@ type RefreshTokenRepository interface {
 	RevokeAllByEmployee(ctx context.Context, employeeID uuid.UUID) error
 	DeleteExpired(ctx context.Context) error
 }
+
+// AttachmentRepository хранит вложения. Вложения с одинаковым содержимым ссылаются на один
+// файл в хранилище; проверка и загрузка файла, создание вложения и удаление файла без ссылок
+// выполняются под LockBlobWithTx.
+type AttachmentRepository interface {
+	CreateWithTx(ctx context.Context, tx *sql.Tx, attachment *domain.Attachment) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Attachment, error)
+	GetByTask(ctx context.Context, taskID uuid.UUID) ([]*domain.Attachment, error)
+	GetByMessage(ctx context.Context, messageID uuid.UUID) ([]*domain.Attachment, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	// LockBlobWithTx блокирует файл с контрольной суммой checksum до конца транзакции
+	LockBlobWithTx(ctx context.Context, tx *sql.Tx, checksum string) error
+	CountByChecksumWithTx(ctx context.Context, tx *sql.Tx, checksum string) (int, error)
+}
+
+type TaskLinkRepository interface {
+	Create(ctx context.Context, link *domain.TaskLink) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.TaskLink, error)
+	GetByTask(ctx context.Context, taskID, viewerID uuid.UUID) ([]*domain.TaskLink, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type ProjectRepository interface {
+	// Create сохраняет проект и добавляет владельца в участники
+	Create(ctx context.Context, project *domain.Project) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Project, error)
+	KeyExists(ctx context.Context, key string) (bool, error)
+	GetForEmployee(ctx context.Context, employeeID uuid.UUID) ([]*domain.Project, error)
+	Update(ctx context.Context, project *domain.Project) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// LockWithTx блокирует строку проекта до конца транзакции. Создание задач блокирует
+	// проект раньше доски (LockBoardWithTx), и этот порядок нужно соблюдать во всех транзакциях.
+	LockWithTx(ctx context.Context, tx *sql.Tx, projectID uuid.UUID) error
+	// NextTaskNumberWithTx выдаёт следующий порядковый номер задачи проекта;
+	// строка проекта блокируется до конца транзакции, поэтому номера не повторяются
+	NextTaskNumberWithTx(ctx context.Context, tx *sql.Tx, projectID uuid.UUID) (int, error)
+	AddMember(ctx context.Context, member *domain.ProjectMember) error
+	AddMemberWithTx(ctx context.Context, tx *sql.Tx, member *domain.ProjectMember) error
+	RemoveMember(ctx context.Context, projectID, employeeID uuid.UUID) error
+	GetMembers(ctx context.Context, projectID uuid.UUID) ([]*domain.ProjectMember, error)
+	IsMember(ctx context.Context, projectID, employeeID uuid.UUID) (bool, error)
+}
+
+// TaskViewRepository хранит сохранённые представления. Поле IsDefault заполняется
+// относительно сотрудника viewerID.
+type TaskViewRepository interface {
+	Create(ctx context.Context, view *domain.TaskView) error
+	GetByID(ctx context.Context, id, viewerID uuid.UUID) (*domain.TaskView, error)
+	GetVisible(ctx context.Context, employeeID uuid.UUID, department string) ([]*domain.TaskView, error)
+	GetDefault(ctx context.Context, employeeID uuid.UUID) (*domain.TaskView, error)
+	Update(ctx context.Context, view *domain.TaskView) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	SetDefault(ctx context.Context, employeeID, viewID uuid.UUID) error
+	ClearDefault(ctx context.Context, employeeID uuid.UUID) error
+}
+
+type LabelRepository interface {
+	Create(ctx context.Context, label *domain.Label) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Label, error)
+	// NameExists сообщает, занято ли имя (без учёта регистра) другой меткой, кроме exceptID
+	NameExists(ctx context.Context, name string, exceptID uuid.UUID) (bool, error)
+	// GetAll возвращает все метки с числом задач, на которых они стоят
+	GetAll(ctx context.Context) ([]*domain.Label, error)
+	Update(ctx context.Context, label *domain.Label) error
+	// DeleteWithTx удаляет метку и возвращает задачи, с которых она была снята
+	DeleteWithTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) ([]uuid.UUID, error)
+	// AddToTaskWithTx и RemoveFromTaskWithTx сообщают, изменился ли набор меток задачи
+	AddToTaskWithTx(ctx context.Context, tx *sql.Tx, taskID, labelID uuid.UUID) (bool, error)
+	RemoveFromTaskWithTx(ctx context.Context, tx *sql.Tx, taskID, labelID uuid.UUID) (bool, error)
+}
+
+type CustomFieldRepository interface {
+	Create(ctx context.Context, field *domain.CustomField) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.CustomField, error)
+	// GetByKeys возвращает найденные поля с указанными ключами
+	GetByKeys(ctx context.Context, keys []string) ([]*domain.CustomField, error)
+	KeyExists(ctx context.Context, key string) (bool, error)
+	// GetForEmployee возвращает общие поля и поля проектов, в которых состоит сотрудник
+	GetForEmployee(ctx context.Context, employeeID uuid.UUID) ([]*domain.CustomField, error)
+	Update(ctx context.Context, field *domain.CustomField) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	SetValueWithTx(ctx context.Context, tx *sql.Tx, taskID, fieldID uuid.UUID, value interface{}) error
+	DeleteValueWithTx(ctx context.Context, tx *sql.Tx, taskID, fieldID uuid.UUID) error
+}
+
+type SprintRepository interface {
+	Create(ctx context.Context, sprint *domain.Sprint) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Sprint, error)
+	// GetByProject возвращает спринты проекта по дате начала; state == nil - в любом состоянии
+	GetByProject(ctx context.Context, projectID uuid.UUID, state *domain.SprintState) ([]*domain.Sprint, error)
+	// Update изменяет название, цель и даты спринта
+	Update(ctx context.Context, sprint *domain.Sprint) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// LockProjectWithTx блокирует спринты проекта до конца транзакции и возвращает ID
+	// активного спринта (nil, если его нет), чтобы смены состояний не выполнялись параллельно
+	LockProjectWithTx(ctx context.Context, tx *sql.Tx, projectID uuid.UUID) (*uuid.UUID, error)
+	// StartWithTx делает спринт активным и фиксирует его текущие задачи как запланированные
+	StartWithTx(ctx context.Context, tx *sql.Tx, id uuid.UUID, startedAt time.Time) error
+	CloseWithTx(ctx context.Context, tx *sql.Tx, id uuid.UUID, closedAt time.Time) error
+	// UnfinishedTaskIDsWithTx возвращает незакрытые задачи спринта
+	UnfinishedTaskIDsWithTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) ([]uuid.UUID, error)
+	// AddTaskWithTx переводит задачу в спринт и отмечает её в составе спринта
+	AddTaskWithTx(ctx context.Context, tx *sql.Tx, sprintID, taskID uuid.UUID) error
+	// RemoveTaskWithTx убирает задачу из спринта в бэклог; в составе спринта она
+	// остаётся с отметкой времени removedAt
+	RemoveTaskWithTx(ctx context.Context, tx *sql.Tx, sprintID, taskID uuid.UUID, removedAt time.Time) error
+	GetReport(ctx context.Context, id uuid.UUID) (*domain.SprintReport, error)
+}
+
+type StatusTransitionRepository interface {
+	CreateWithTx(ctx context.Context, tx *sql.Tx, transition *domain.StatusTransition) error
+	GetByTask(ctx context.Context, taskID uuid.UUID) ([]*domain.StatusTransition, error)
+}
+
+// RecurrenceRepository хранит расписания повторения задач. Планировщик работает во всех
+// репликах API; ClaimDueWithTx и ClaimOccurrenceWithTx не дают двум репликам создать
+// одно и то же повторение.
+type RecurrenceRepository interface {
+	// Upsert создаёт расписание задачи-шаблона или заменяет существующее
+	Upsert(ctx context.Context, recurrence *domain.TaskRecurrence) error
+	GetByTemplate(ctx context.Context, templateTaskID uuid.UUID) (*domain.TaskRecurrence, error)
+	DeleteByTemplate(ctx context.Context, templateTaskID uuid.UUID) error
+	// ClaimDueWithTx блокирует до конца транзакции ближайшее наступившее расписание,
+	// пропуская заблокированные другими репликами и отложенные после ошибки до RetryAt;
+	// nil - наступивших расписаний нет
+	ClaimDueWithTx(ctx context.Context, tx *sql.Tx, now time.Time) (*domain.TaskRecurrence, error)
+	// UpdateScheduleWithTx сохраняет следующее повторение и сбрасывает счётчик ошибок
+	UpdateScheduleWithTx(ctx context.Context, tx *sql.Tx, recurrence *domain.TaskRecurrence) error
+	// UpdateRetry сохраняет Failures и RetryAt после неудачной попытки, вне транзакции попытки
+	UpdateRetry(ctx context.Context, recurrence *domain.TaskRecurrence) error
+	// ClaimOccurrenceWithTx отмечает повторение созданным; false - оно уже было создано
+	ClaimOccurrenceWithTx(ctx context.Context, tx *sql.Tx, recurrenceID uuid.UUID, occurrenceAt time.Time) (bool, error)
+	SetOccurrenceTaskWithTx(ctx context.Context, tx *sql.Tx, recurrenceID uuid.UUID, occurrenceAt time.Time, taskID uuid.UUID) error
+}
+
+// AnalyticsRepository строит отчёты по истории статусов задач, подходящих под фильтр.
+// Границы периода from и to - даты (включительно), статус задачи берётся на конец дня.
+type AnalyticsRepository interface {
+	// Burndown возвращает объём и закрытые задачи по дням. Если sprintID задан, в объём
+	// каждого дня входят задачи, состоявшие в спринте на этот день.
+	Burndown(ctx context.Context, filter TaskFilter, sprintID *uuid.UUID, from, to time.Time) ([]*domain.BurndownPoint, error)
+	CumulativeFlow(ctx context.Context, filter TaskFilter, from, to time.Time) ([]*domain.FlowPoint, error)
+	// FlowTimes считает lead time и cycle time задач, закрытых в периоде, по командам
+	FlowTimes(ctx context.Context, filter TaskFilter, from, to time.Time) ([]*domain.FlowTimeStats, error)
+}
+
+type SearchFilter struct {
+	Query string
+	TaskFilter
+}
+
+type SearchRepository interface {
+	Search(ctx context.Context, filter SearchFilter) ([]*domain.SearchResult, int, error)
+}
+
+// MaintenanceRepository - служебные операции над данными для администрирования
+type MaintenanceRepository interface {
+	// PurgeDeletedWithTx безвозвратно удаляет строки, мягко удалённые раньше before, и возвращает
+	// число удалённых строк по таблицам и вложения, удалённые вместе с задачами
+	PurgeDeletedWithTx(ctx context.Context, tx *sql.Tx, before time.Time) ([]domain.PurgeCount, []*domain.Attachment, error)
+}
+
+// WorkspaceRepository выгружает и загружает данные рабочего пространства целиком
+// для переноса между окружениями и резервных копий
+type WorkspaceRepository interface {
+	// Stream*WithTx передают строки таблицы в fn по одной, не загружая таблицу в память.
+	// Задачи выгружаются уровнями: родительская задача всегда раньше подзадач.
+	StreamEmployeesWithTx(ctx context.Context, tx *sql.Tx, withPasswordHashes bool, fn func(*domain.ArchivedEmployee) error) error
+	StreamProjectsWithTx(ctx context.Context, tx *sql.Tx, fn func(*domain.ArchivedProject) error) error
+	StreamTasksWithTx(ctx context.Context, tx *sql.Tx, fn func(*domain.ArchivedTask) error) error
+	StreamParticipantsWithTx(ctx context.Context, tx *sql.Tx, fn func(*domain.TaskParticipant) error) error
+	StreamMessagesWithTx(ctx context.Context, tx *sql.Tx, fn func(*domain.TaskMessage) error) error
+	StreamTimeEntriesWithTx(ctx context.Context, tx *sql.Tx, fn func(*domain.TimeEntry) error) error
+
+	// ExistingIDsWithTx возвращает идентификаторы из ids, уже занятые записями типа recordType
+	ExistingIDsWithTx(ctx context.Context, tx *sql.Tx, recordType domain.ArchiveRecordType, ids []uuid.UUID) (map[uuid.UUID]bool, error)
+	// EmployeeIDsByEmailWithTx находит существующих сотрудников по адресам почты
+	EmployeeIDsByEmailWithTx(ctx context.Context, tx *sql.Tx, emails []string) (map[string]uuid.UUID, error)
+	ExistingProjectKeysWithTx(ctx context.Context, tx *sql.Tx, keys []string) (map[string]bool, error)
+	ExistingTaskKeysWithTx(ctx context.Context, tx *sql.Tx, keys []string) (map[string]bool, error)
+
+	// Insert*WithTx сохраняют запись архива как есть, вместе с датами создания и изменения
+	InsertEmployeeWithTx(ctx context.Context, tx *sql.Tx, employee *domain.ArchivedEmployee) error
+	InsertProjectWithTx(ctx context.Context, tx *sql.Tx, project *domain.ArchivedProject) error
+	InsertTaskWithTx(ctx context.Context, tx *sql.Tx, task *domain.ArchivedTask) error
+	InsertParticipantWithTx(ctx context.Context, tx *sql.Tx, participant *domain.TaskParticipant) error
+	InsertMessageWithTx(ctx context.Context, tx *sql.Tx, message *domain.TaskMessage) error
+	InsertTimeEntryWithTx(ctx context.Context, tx *sql.Tx, entry *domain.TimeEntry) error
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type labelRepository struct {
+	db *sql.DB
+}
+
+func NewLabelRepository(db *sql.DB) LabelRepository {
+	return &labelRepository{db: db}
+}
+
+func (r *labelRepository) Create(ctx context.Context, label *domain.Label) error {
+	query := `
+		INSERT INTO labels (id, name, color, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, label.ID, label.Name, label.Color, label.CreatedAt, label.UpdatedAt)
+	if err != nil {
+		return errors.Internal(err, "Не удалось создать метку")
+	}
+
+	return nil
+}
+
+func (r *labelRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Label, error) {
+	query := `
+		SELECT id, name, color, created_at, updated_at
+		FROM labels
+		WHERE id = $1
+	`
+
+	label := &domain.Label{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&label.ID, &label.Name, &label.Color, &label.CreatedAt, &label.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("Метка не найдена")
+	}
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить метку")
+	}
+
+	return label, nil
+}
+
+func (r *labelRepository) NameExists(ctx context.Context, name string, exceptID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM labels WHERE LOWER(name) = LOWER($1) AND id <> $2)`
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, name, exceptID).Scan(&exists); err != nil {
+		return false, errors.Internal(err, "Не удалось проверить имя метки")
+	}
+
+	return exists, nil
+}
+
+// GetAll возвращает метки по алфавиту; удалённые задачи в числе использований не учитываются
+func (r *labelRepository) GetAll(ctx context.Context) ([]*domain.Label, error) {
+	query := `
+		SELECT l.id, l.name, l.color, l.created_at, l.updated_at, COUNT(t.id)
+		FROM labels l
+		LEFT JOIN task_labels tl ON tl.label_id = l.id
+		LEFT JOIN tasks t ON t.id = tl.task_id AND t.deleted_at IS NULL
+		GROUP BY l.id
+		ORDER BY LOWER(l.name) ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить метки")
+	}
+	defer rows.Close()
+
+	labels := []*domain.Label{}
+	for rows.Next() {
+		label := &domain.Label{}
+		err := rows.Scan(&label.ID, &label.Name, &label.Color, &label.CreatedAt, &label.UpdatedAt, &label.UsageCount)
+		if err != nil {
+			return nil, errors.Internal(err, "Не удалось обработать метку")
+		}
+		labels = append(labels, label)
+	}
+
+	return labels, nil
+}
+
+func (r *labelRepository) Update(ctx context.Context, label *domain.Label) error {
+	query := `
+		UPDATE labels
+		SET name = $2, color = $3
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, label.ID, label.Name, label.Color).Scan(&label.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return errors.NotFound("Метка не найдена")
+	}
+	if err != nil {
+		return errors.Internal(err, "Не удалось обновить метку")
+	}
+
+	return nil
+}
+
+func (r *labelRepository) DeleteWithTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		WITH removed AS (
+			DELETE FROM task_labels WHERE label_id = $1 RETURNING task_id
+		)
+		SELECT task_id FROM removed
+	`
+
+	rows, err := tx.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось снять метку с задач")
+	}
+	defer rows.Close()
+
+	taskIDs := []uuid.UUID{}
+	for rows.Next() {
+		var taskID uuid.UUID
+		if err := rows.Scan(&taskID); err != nil {
+			return nil, errors.Internal(err, "Не удалось обработать задачу метки")
+		}
+		taskIDs = append(taskIDs, taskID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Internal(err, "Не удалось снять метку с задач")
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM labels WHERE id = $1`, id)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось удалить метку")
+	}
+
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return nil, errors.NotFound("Метка не найдена")
+	}
+
+	return taskIDs, nil
+}
+
+func (r *labelRepository) AddToTaskWithTx(ctx context.Context, tx *sql.Tx, taskID, labelID uuid.UUID) (bool, error) {
+	query := `
+		INSERT INTO task_labels (task_id, label_id)
+		VALUES ($1, $2)
+		ON CONFLICT (task_id, label_id) DO NOTHING
+	`
+
+	result, err := tx.ExecContext(ctx, query, taskID, labelID)
+	if err != nil {
+		return false, errors.Internal(err, "Не удалось добавить метку к задаче")
+	}
+
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+func (r *labelRepository) RemoveFromTaskWithTx(ctx context.Context, tx *sql.Tx, taskID, labelID uuid.UUID) (bool, error) {
+	query := `DELETE FROM task_labels WHERE task_id = $1 AND label_id = $2`
+
+	result, err := tx.ExecContext(ctx, query, taskID, labelID)
+	if err != nil {
+		return false, errors.Internal(err, "Не удалось снять метку с задачи")
+	}
+
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
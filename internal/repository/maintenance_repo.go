@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type maintenanceRepository struct {
+	db *sql.DB
+}
+
+func NewMaintenanceRepository(db *sql.DB) MaintenanceRepository {
+	return &maintenanceRepository{db: db}
+}
+
+// PurgeDeletedWithTx удаляет строки в порядке зависимостей. Задача удаляется, когда у неё не
+// осталось подзадач, проект - когда в нём нет задач, сотрудник - когда на него не ссылаются
+// задачи, проекты и записи времени. Вложения удалённых сообщений и задач удаляются явно,
+// чтобы вернуть их файлы для очистки хранилища.
+func (r *maintenanceRepository) PurgeDeletedWithTx(ctx context.Context, tx *sql.Tx, before time.Time) ([]domain.PurgeCount, []*domain.Attachment, error) {
+	var attachments []*domain.Attachment
+	var attachmentRows int64
+
+	// Вложения удалённых сообщений и мягко удалённые вложения
+	removed, err := r.deleteAttachments(ctx, tx, `
+		DELETE FROM attachments a
+		WHERE a.deleted_at < $1
+			OR a.message_id IN (SELECT id FROM task_messages WHERE deleted_at < $1)
+		RETURNING a.id, a.task_id, a.checksum, a.storage_key, a.deleted_at
+	`, before)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachmentRows += int64(len(removed))
+	attachments = append(attachments, liveAttachments(removed)...)
+
+	messages, err := r.exec(ctx, tx, `DELETE FROM task_messages WHERE deleted_at < $1`, before)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	timeEntries, err := r.exec(ctx, tx, `DELETE FROM time_entries WHERE deleted_at < $1`, before)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Подзадачи удаляются раньше родителей, поэтому задачи удаляются уровнями
+	var tasks int64
+	for {
+		var ids []uuid.UUID
+		rows, err := tx.QueryContext(ctx, `
+			SELECT t.id FROM tasks t
+			WHERE t.deleted_at < $1 AND NOT EXISTS (SELECT 1 FROM tasks c WHERE c.parent_id = t.id)
+		`, before)
+		if err != nil {
+			return nil, nil, errors.Internal(err, "Не удалось найти удалённые задачи")
+		}
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, nil, errors.Internal(err, "Не удалось найти удалённые задачи")
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if len(ids) == 0 {
+			break
+		}
+
+		removed, err := r.deleteAttachments(ctx, tx, `
+			DELETE FROM attachments a WHERE a.task_id = ANY($1)
+			RETURNING a.id, a.task_id, a.checksum, a.storage_key, a.deleted_at
+		`, pq.Array(ids))
+		if err != nil {
+			return nil, nil, err
+		}
+		attachmentRows += int64(len(removed))
+		attachments = append(attachments, liveAttachments(removed)...)
+
+		count, err := r.exec(ctx, tx, `DELETE FROM tasks WHERE id = ANY($1)`, pq.Array(ids))
+		if err != nil {
+			return nil, nil, err
+		}
+		tasks += count
+	}
+
+	projects, err := r.exec(ctx, tx, `
+		DELETE FROM projects p
+		WHERE p.deleted_at < $1 AND NOT EXISTS (SELECT 1 FROM tasks t WHERE t.project_id = p.id)
+	`, before)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	employees, err := r.exec(ctx, tx, `
+		DELETE FROM employees e
+		WHERE e.deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM tasks t WHERE t.created_by = e.id)
+			AND NOT EXISTS (SELECT 1 FROM projects p WHERE p.owner_id = e.id)
+			AND NOT EXISTS (SELECT 1 FROM time_entries te WHERE te.employee_id = e.id)
+	`, before)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	counts := []domain.PurgeCount{
+		{Table: "attachments", Rows: attachmentRows},
+		{Table: "task_messages", Rows: messages},
+		{Table: "time_entries", Rows: timeEntries},
+		{Table: "tasks", Rows: tasks},
+		{Table: "projects", Rows: projects},
+		{Table: "employees", Rows: employees},
+	}
+
+	return counts, attachments, nil
+}
+
+func (r *maintenanceRepository) exec(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (int64, error) {
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, errors.Internal(err, "Не удалось удалить устаревшие данные")
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows, nil
+}
+
+func (r *maintenanceRepository) deleteAttachments(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]*domain.Attachment, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось удалить вложения")
+	}
+	defer rows.Close()
+
+	attachments := []*domain.Attachment{}
+	for rows.Next() {
+		a := &domain.Attachment{}
+		if err := rows.Scan(&a.ID, &a.TaskID, &a.Checksum, &a.StorageKey, &a.DeletedAt); err != nil {
+			return nil, errors.Internal(err, "Не удалось удалить вложения")
+		}
+		attachments = append(attachments, a)
+	}
+
+	return attachments, rows.Err()
+}
+
+// liveAttachments отбирает вложения, которые не были мягко удалены: файлы мягко удалённых
+// вложений уже обработаны при их удалении
+func liveAttachments(attachments []*domain.Attachment) []*domain.Attachment {
+	live := []*domain.Attachment{}
+	for _, a := range attachments {
+		if a.DeletedAt == nil {
+			live = append(live, a)
+		}
+	}
+	return live
+}
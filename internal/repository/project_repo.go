@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type projectRepository struct {
+	db *sql.DB
+}
+
+func NewProjectRepository(db *sql.DB) ProjectRepository {
+	return &projectRepository{db: db}
+}
+
+func (r *projectRepository) Create(ctx context.Context, p *domain.Project) error {
+	// Проект и членство владельца создаются одним запросом, чтобы владелец
+	// не мог остаться вне собственного проекта
+	query := `
+		WITH project AS (
+			INSERT INTO projects (id, key, name, description, owner_id, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, owner_id, created_at
+		)
+		INSERT INTO project_members (project_id, employee_id, created_at)
+		SELECT id, owner_id, created_at FROM project
+	`
+
+	_, err := r.db.ExecContext(ctx, query, p.ID, p.Key, p.Name, p.Description, p.OwnerID, p.CreatedAt, p.UpdatedAt)
+	if err != nil {
+		return errors.Internal(err, "Не удалось создать проект")
+	}
+
+	return nil
+}
+
+func (r *projectRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Project, error) {
+	query := `
+		SELECT id, key, name, COALESCE(description, ''), owner_id, created_at, updated_at
+		FROM projects
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	p := &domain.Project{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&p.ID, &p.Key, &p.Name, &p.Description, &p.OwnerID, &p.CreatedAt, &p.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("Проект не найден")
+	}
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить проект")
+	}
+
+	return p, nil
+}
+
+// KeyExists учитывает и удалённые проекты: их ключи остаются зарезервированными
+func (r *projectRepository) KeyExists(ctx context.Context, key string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM projects WHERE key = $1)`, key).Scan(&exists)
+	if err != nil {
+		return false, errors.Internal(err, "Не удалось проверить ключ проекта")
+	}
+
+	return exists, nil
+}
+
+func (r *projectRepository) GetForEmployee(ctx context.Context, employeeID uuid.UUID) ([]*domain.Project, error) {
+	query := `
+		SELECT p.id, p.key, p.name, COALESCE(p.description, ''), p.owner_id, p.created_at, p.updated_at
+		FROM projects p
+		INNER JOIN project_members pm ON pm.project_id = p.id
+		WHERE pm.employee_id = $1 AND p.deleted_at IS NULL
+		ORDER BY p.key ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, employeeID)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить список проектов")
+	}
+	defer rows.Close()
+
+	projects := []*domain.Project{}
+	for rows.Next() {
+		p := &domain.Project{}
+		err := rows.Scan(&p.ID, &p.Key, &p.Name, &p.Description, &p.OwnerID, &p.CreatedAt, &p.UpdatedAt)
+		if err != nil {
+			return nil, errors.Internal(err, "Не удалось обработать данные проекта")
+		}
+		projects = append(projects, p)
+	}
+
+	return projects, nil
+}
+
+func (r *projectRepository) Update(ctx context.Context, p *domain.Project) error {
+	query := `
+		UPDATE projects
+		SET name = $2, description = $3
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, p.ID, p.Name, p.Description).Scan(&p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return errors.NotFound("Проект не найден")
+	}
+	if err != nil {
+		return errors.Internal(err, "Не удалось обновить проект")
+	}
+
+	return nil
+}
+
+func (r *projectRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE projects SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return errors.Internal(err, "Не удалось удалить проект")
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NotFound("Проект не найден")
+	}
+
+	return nil
+}
+
+func (r *projectRepository) LockWithTx(ctx context.Context, tx *sql.Tx, projectID uuid.UUID) error {
+	var id uuid.UUID
+	err := tx.QueryRowContext(ctx, `SELECT id FROM projects WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, projectID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return errors.NotFound("Проект не найден")
+	}
+	if err != nil {
+		return errors.Internal(err, "Не удалось заблокировать проект")
+	}
+
+	return nil
+}
+
+func (r *projectRepository) NextTaskNumberWithTx(ctx context.Context, tx *sql.Tx, projectID uuid.UUID) (int, error) {
+	query := `
+		UPDATE projects
+		SET task_counter = task_counter + 1
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING task_counter
+	`
+
+	var number int
+	err := tx.QueryRowContext(ctx, query, projectID).Scan(&number)
+	if err == sql.ErrNoRows {
+		return 0, errors.NotFound("Проект не найден")
+	}
+	if err != nil {
+		return 0, errors.Internal(err, "Не удалось получить номер задачи проекта")
+	}
+
+	return number, nil
+}
+
+func (r *projectRepository) AddMember(ctx context.Context, m *domain.ProjectMember) error {
+	return r.AddMemberWithTx(ctx, nil, m)
+}
+
+func (r *projectRepository) AddMemberWithTx(ctx context.Context, tx *sql.Tx, m *domain.ProjectMember) error {
+	query := `
+		INSERT INTO project_members (project_id, employee_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (project_id, employee_id) DO NOTHING
+	`
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, m.ProjectID, m.EmployeeID, m.CreatedAt)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, m.ProjectID, m.EmployeeID, m.CreatedAt)
+	}
+	if err != nil {
+		return errors.Internal(err, "Не удалось добавить участника проекта")
+	}
+
+	return nil
+}
+
+func (r *projectRepository) RemoveMember(ctx context.Context, projectID, employeeID uuid.UUID) error {
+	query := `DELETE FROM project_members WHERE project_id = $1 AND employee_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, projectID, employeeID)
+	if err != nil {
+		return errors.Internal(err, "Не удалось удалить участника проекта")
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NotFound("Участник проекта не найден")
+	}
+
+	return nil
+}
+
+func (r *projectRepository) GetMembers(ctx context.Context, projectID uuid.UUID) ([]*domain.ProjectMember, error) {
+	query := `
+		SELECT project_id, employee_id, created_at
+		FROM project_members
+		WHERE project_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить участников проекта")
+	}
+	defer rows.Close()
+
+	members := []*domain.ProjectMember{}
+	for rows.Next() {
+		m := &domain.ProjectMember{}
+		if err := rows.Scan(&m.ProjectID, &m.EmployeeID, &m.CreatedAt); err != nil {
+			return nil, errors.Internal(err, "Не удалось обработать данные участника проекта")
+		}
+		members = append(members, m)
+	}
+
+	return members, nil
+}
+
+func (r *projectRepository) IsMember(ctx context.Context, projectID, employeeID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM project_members WHERE project_id = $1 AND employee_id = $2)`
+
+	var member bool
+	if err := r.db.QueryRowContext(ctx, query, projectID, employeeID).Scan(&member); err != nil {
+		return false, errors.Internal(err, "Не удалось проверить участие в проекте")
+	}
+
+	return member, nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+)
+
+const recurrenceColumns = `id, template_task_id, rule, starts_at, timezone, next_run_at, occurrences,
+	failures, retry_at, created_by, created_at, updated_at`
+
+type recurrenceRepository struct {
+	db *sql.DB
+}
+
+func NewRecurrenceRepository(db *sql.DB) RecurrenceRepository {
+	return &recurrenceRepository{db: db}
+}
+
+// Upsert заменяет правило существующего расписания и начинает заново отсчёт повторений
+// и попытки после ошибок; уже созданные повторения остаются отмеченными и повторно не создаются
+func (r *recurrenceRepository) Upsert(ctx context.Context, rec *domain.TaskRecurrence) error {
+	query := `
+		INSERT INTO task_recurrences (id, template_task_id, rule, starts_at, timezone, next_run_at, occurrences,
+			created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (template_task_id) DO UPDATE SET
+			rule = EXCLUDED.rule,
+			starts_at = EXCLUDED.starts_at,
+			timezone = EXCLUDED.timezone,
+			next_run_at = EXCLUDED.next_run_at,
+			occurrences = EXCLUDED.occurrences,
+			failures = 0,
+			retry_at = NULL,
+			created_by = EXCLUDED.created_by
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, rec.ID, rec.TemplateTaskID, rec.Rule, rec.StartsAt, rec.Timezone,
+		rec.NextRunAt, rec.Occurrences, rec.CreatedBy, rec.CreatedAt, rec.UpdatedAt,
+	).Scan(&rec.ID, &rec.CreatedAt, &rec.UpdatedAt)
+	if err != nil {
+		return errors.Internal(err, "Не удалось сохранить расписание повторения")
+	}
+
+	return nil
+}
+
+func (r *recurrenceRepository) GetByTemplate(ctx context.Context, templateTaskID uuid.UUID) (*domain.TaskRecurrence, error) {
+	query := `SELECT ` + recurrenceColumns + ` FROM task_recurrences WHERE template_task_id = $1`
+
+	rec := &domain.TaskRecurrence{}
+	err := r.db.QueryRowContext(ctx, query, templateTaskID).Scan(recurrenceScanDest(rec)...)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("Расписание повторения не найдено")
+	}
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить расписание повторения")
+	}
+
+	return rec, nil
+}
+
+func (r *recurrenceRepository) DeleteByTemplate(ctx context.Context, templateTaskID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM task_recurrences WHERE template_task_id = $1`, templateTaskID)
+	if err != nil {
+		return errors.Internal(err, "Не удалось удалить расписание повторения")
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NotFound("Расписание повторения не найдено")
+	}
+
+	return nil
+}
+
+func (r *recurrenceRepository) ClaimDueWithTx(ctx context.Context, tx *sql.Tx, now time.Time) (*domain.TaskRecurrence, error) {
+	query := `
+		SELECT ` + recurrenceColumns + `
+		FROM task_recurrences
+		WHERE next_run_at IS NOT NULL AND next_run_at <= $1
+			AND (retry_at IS NULL OR retry_at <= $1)
+		ORDER BY next_run_at ASC
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rec := &domain.TaskRecurrence{}
+	err := tx.QueryRowContext(ctx, query, now).Scan(recurrenceScanDest(rec)...)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить наступившие повторения")
+	}
+
+	return rec, nil
+}
+
+func (r *recurrenceRepository) UpdateScheduleWithTx(ctx context.Context, tx *sql.Tx, rec *domain.TaskRecurrence) error {
+	query := `UPDATE task_recurrences SET next_run_at = $2, occurrences = $3, failures = 0, retry_at = NULL WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, query, rec.ID, rec.NextRunAt, rec.Occurrences); err != nil {
+		return errors.Internal(err, "Не удалось обновить расписание повторения")
+	}
+
+	rec.Failures = 0
+	rec.RetryAt = nil
+	return nil
+}
+
+func (r *recurrenceRepository) UpdateRetry(ctx context.Context, rec *domain.TaskRecurrence) error {
+	query := `UPDATE task_recurrences SET failures = $2, retry_at = $3 WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, rec.ID, rec.Failures, rec.RetryAt); err != nil {
+		return errors.Internal(err, "Не удалось отложить расписание повторения")
+	}
+
+	return nil
+}
+
+func (r *recurrenceRepository) ClaimOccurrenceWithTx(ctx context.Context, tx *sql.Tx, recurrenceID uuid.UUID, occurrenceAt time.Time) (bool, error) {
+	query := `
+		INSERT INTO task_recurrence_occurrences (recurrence_id, occurrence_at)
+		VALUES ($1, $2)
+		ON CONFLICT (recurrence_id, occurrence_at) DO NOTHING
+	`
+
+	result, err := tx.ExecContext(ctx, query, recurrenceID, occurrenceAt)
+	if err != nil {
+		return false, errors.Internal(err, "Не удалось отметить повторение")
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+func (r *recurrenceRepository) SetOccurrenceTaskWithTx(ctx context.Context, tx *sql.Tx, recurrenceID uuid.UUID, occurrenceAt time.Time, taskID uuid.UUID) error {
+	query := `UPDATE task_recurrence_occurrences SET task_id = $3 WHERE recurrence_id = $1 AND occurrence_at = $2`
+	if _, err := tx.ExecContext(ctx, query, recurrenceID, occurrenceAt, taskID); err != nil {
+		return errors.Internal(err, "Не удалось отметить повторение")
+	}
+
+	return nil
+}
+
+func recurrenceScanDest(rec *domain.TaskRecurrence) []interface{} {
+	return []interface{}{
+		&rec.ID, &rec.TemplateTaskID, &rec.Rule, &rec.StartsAt, &rec.Timezone, &rec.NextRunAt, &rec.Occurrences,
+		&rec.Failures, &rec.RetryAt, &rec.CreatedBy, &rec.CreatedAt, &rec.UpdatedAt,
+	}
+}
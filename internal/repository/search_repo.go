@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/pkg/errors"
+)
+
+// Маркеры подсветки, которые ts_headline вставляет вокруг совпадений.
+// Управляющие символы не встречаются в обычном тексте, поэтому после
+// экранирования HTML их можно безопасно заменить на <mark>.
+const (
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
+type searchRepository struct {
+	db *sql.DB
+}
+
+func NewSearchRepository(db *sql.DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+func (r *searchRepository) Search(ctx context.Context, filter SearchFilter) ([]*domain.SearchResult, int, error) {
+	// Для каждой задачи берётся лучшее совпадение: по названию и описанию задачи
+	// или по одному из её сообщений. Вес A (название) > B (описание) > C (сообщения).
+	query := fmt.Sprintf(`
+		WITH q AS (
+			SELECT websearch_to_tsquery('russian', $1) || websearch_to_tsquery('english', $1) AS query
+		),
+		hits AS (
+			SELECT t.id AS task_id, NULL::uuid AS message_id, ts_rank(t.search_vector, q.query) AS rank
+			FROM tasks t, q
+			WHERE t.deleted_at IS NULL AND t.search_vector @@ q.query
+			UNION ALL
+			SELECT m.task_id, m.id, ts_rank(m.search_vector, q.query)
+			FROM task_messages m, q
+			WHERE m.deleted_at IS NULL AND m.search_vector @@ q.query
+		),
+		best AS (
+			SELECT DISTINCT ON (task_id) task_id, message_id, rank
+			FROM hits
+			ORDER BY task_id, rank DESC
+		)
+		SELECT %s,
+			b.message_id, b.rank, %s, %s, COUNT(*) OVER() AS total
+		FROM best b
+		INNER JOIN tasks t ON t.id = b.task_id
+		LEFT JOIN task_messages m ON m.id = b.message_id
+		CROSS JOIN q
+		WHERE t.deleted_at IS NULL
+	`, taskColumns, headlineExpr("t.title", "$3"), headlineExpr("COALESCE(m.content, t.description, '')", "$2"))
+
+	snippetOptions := fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "`,
+		highlightStart, highlightStop)
+	titleOptions := fmt.Sprintf(`StartSel="%s", StopSel="%s", HighlightAll=true`, highlightStart, highlightStop)
+
+	args := []interface{}{filter.Query, snippetOptions, titleOptions}
+
+	conditions, filterArgs, argPos := buildTaskConditions(filter.TaskFilter, 4)
+	query += conditions
+	args = append(args, filterArgs...)
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = 20
+	}
+
+	offset := (filter.Page - 1) * filter.PageSize
+	query += fmt.Sprintf(" ORDER BY b.rank DESC, t.created_at DESC LIMIT $%d OFFSET $%d", argPos, argPos+1)
+	args = append(args, filter.PageSize, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.Internal(err, "Не удалось выполнить поиск")
+	}
+	defer rows.Close()
+
+	total := 0
+	results := []*domain.SearchResult{}
+	for rows.Next() {
+		task := &domain.Task{}
+		result := &domain.SearchResult{Task: task}
+		dest := append(taskScanDest(task), &result.MessageID, &result.Rank, &result.Title, &result.Snippet, &total)
+		err := rows.Scan(dest...)
+		if err != nil {
+			return nil, 0, errors.Internal(err, "Не удалось обработать результат поиска")
+		}
+		result.Title = highlightToHTML(result.Title)
+		result.Snippet = highlightToHTML(result.Snippet)
+		results = append(results, result)
+	}
+
+	return results, total, nil
+}
+
+// headlineExpr строит выделение совпадений: сначала русским словарём,
+// а если он ничего не подсветил - английским
+func headlineExpr(text, options string) string {
+	ru := fmt.Sprintf("ts_headline('russian', %s, q.query, %s)", text, options)
+	en := fmt.Sprintf("ts_headline('english', %s, q.query, %s)", text, options)
+	return fmt.Sprintf("CASE WHEN strpos(%s, '%s') > 0 THEN %s ELSE %s END", ru, highlightStart, ru, en)
+}
+
+// highlightToHTML экранирует текст и заменяет маркеры подсветки на <mark>
+func highlightToHTML(s string) string {
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, highlightStart, "<mark>")
+	return strings.ReplaceAll(s, highlightStop, "</mark>")
+}
@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+)
+
+const sprintColumns = `id, project_id, name, COALESCE(goal, ''), start_date, end_date, state, started_at, closed_at,
+	created_by, created_at, updated_at`
+
+type sprintRepository struct {
+	db *sql.DB
+}
+
+func NewSprintRepository(db *sql.DB) SprintRepository {
+	return &sprintRepository{db: db}
+}
+
+func (r *sprintRepository) Create(ctx context.Context, s *domain.Sprint) error {
+	query := `
+		INSERT INTO sprints (id, project_id, name, goal, start_date, end_date, state, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, s.ID, s.ProjectID, s.Name, s.Goal, s.StartDate, s.EndDate, s.State,
+		s.CreatedBy, s.CreatedAt, s.UpdatedAt)
+	if err != nil {
+		return errors.Internal(err, "Не удалось создать спринт")
+	}
+
+	return nil
+}
+
+func (r *sprintRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Sprint, error) {
+	query := `SELECT ` + sprintColumns + ` FROM sprints WHERE id = $1`
+
+	s := &domain.Sprint{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(sprintScanDest(s)...)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("Спринт не найден")
+	}
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить спринт")
+	}
+
+	return s, nil
+}
+
+func (r *sprintRepository) GetByProject(ctx context.Context, projectID uuid.UUID, state *domain.SprintState) ([]*domain.Sprint, error) {
+	query := `
+		SELECT ` + sprintColumns + `
+		FROM sprints
+		WHERE project_id = $1 AND ($2::sprint_state IS NULL OR state = $2)
+		ORDER BY start_date ASC, created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, projectID, state)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить список спринтов")
+	}
+	defer rows.Close()
+
+	sprints := []*domain.Sprint{}
+	for rows.Next() {
+		s := &domain.Sprint{}
+		if err := rows.Scan(sprintScanDest(s)...); err != nil {
+			return nil, errors.Internal(err, "Не удалось обработать данные спринта")
+		}
+		sprints = append(sprints, s)
+	}
+
+	return sprints, nil
+}
+
+func (r *sprintRepository) Update(ctx context.Context, s *domain.Sprint) error {
+	query := `
+		UPDATE sprints
+		SET name = $2, goal = $3, start_date = $4, end_date = $5
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, s.ID, s.Name, s.Goal, s.StartDate, s.EndDate).Scan(&s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return errors.NotFound("Спринт не найден")
+	}
+	if err != nil {
+		return errors.Internal(err, "Не удалось обновить спринт")
+	}
+
+	return nil
+}
+
+// Delete удаляет спринт; его задачи возвращаются в бэклог
+func (r *sprintRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM sprints WHERE id = $1`, id)
+	if err != nil {
+		return errors.Internal(err, "Не удалось удалить спринт")
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NotFound("Спринт не найден")
+	}
+
+	return nil
+}
+
+func (r *sprintRepository) LockProjectWithTx(ctx context.Context, tx *sql.Tx, projectID uuid.UUID) (*uuid.UUID, error) {
+	query := `SELECT id, state FROM sprints WHERE project_id = $1 ORDER BY id FOR UPDATE`
+
+	rows, err := tx.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось заблокировать спринты проекта")
+	}
+	defer rows.Close()
+
+	var active *uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		var state domain.SprintState
+		if err := rows.Scan(&id, &state); err != nil {
+			return nil, errors.Internal(err, "Не удалось заблокировать спринты проекта")
+		}
+		if state == domain.SprintStateActive {
+			active = &id
+		}
+	}
+
+	return active, nil
+}
+
+func (r *sprintRepository) StartWithTx(ctx context.Context, tx *sql.Tx, id uuid.UUID, startedAt time.Time) error {
+	// Задачи, убранные при планировании, в истории спринта не нужны
+	if _, err := tx.ExecContext(ctx, `DELETE FROM sprint_tasks WHERE sprint_id = $1 AND removed_at IS NOT NULL`, id); err != nil {
+		return errors.Internal(err, "Не удалось начать спринт")
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE sprint_tasks SET committed = true WHERE sprint_id = $1`, id); err != nil {
+		return errors.Internal(err, "Не удалось начать спринт")
+	}
+
+	query := `UPDATE sprints SET state = 'active', started_at = $2 WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, query, id, startedAt); err != nil {
+		return errors.Internal(err, "Не удалось начать спринт")
+	}
+
+	return nil
+}
+
+func (r *sprintRepository) CloseWithTx(ctx context.Context, tx *sql.Tx, id uuid.UUID, closedAt time.Time) error {
+	query := `UPDATE sprints SET state = 'closed', closed_at = $2 WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, query, id, closedAt); err != nil {
+		return errors.Internal(err, "Не удалось закрыть спринт")
+	}
+
+	return nil
+}
+
+func (r *sprintRepository) UnfinishedTaskIDsWithTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT id FROM tasks
+		WHERE sprint_id = $1 AND status <> 'closed' AND deleted_at IS NULL
+		ORDER BY created_at ASC
+		FOR UPDATE
+	`
+
+	rows, err := tx.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить задачи спринта")
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var taskID uuid.UUID
+		if err := rows.Scan(&taskID); err != nil {
+			return nil, errors.Internal(err, "Не удалось получить задачи спринта")
+		}
+		ids = append(ids, taskID)
+	}
+
+	return ids, nil
+}
+
+func (r *sprintRepository) AddTaskWithTx(ctx context.Context, tx *sql.Tx, sprintID, taskID uuid.UUID) error {
+	if _, err := tx.ExecContext(ctx, `UPDATE tasks SET sprint_id = $1 WHERE id = $2`, sprintID, taskID); err != nil {
+		return errors.Internal(err, "Не удалось добавить задачу в спринт")
+	}
+
+	// Вернувшаяся в спринт задача снова считается его частью
+	query := `
+		INSERT INTO sprint_tasks (sprint_id, task_id, added_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP)
+		ON CONFLICT (sprint_id, task_id) DO UPDATE SET removed_at = NULL
+	`
+	if _, err := tx.ExecContext(ctx, query, sprintID, taskID); err != nil {
+		return errors.Internal(err, "Не удалось добавить задачу в спринт")
+	}
+
+	return nil
+}
+
+func (r *sprintRepository) RemoveTaskWithTx(ctx context.Context, tx *sql.Tx, sprintID, taskID uuid.UUID, removedAt time.Time) error {
+	query := `UPDATE tasks SET sprint_id = NULL WHERE id = $1 AND sprint_id = $2`
+	if _, err := tx.ExecContext(ctx, query, taskID, sprintID); err != nil {
+		return errors.Internal(err, "Не удалось убрать задачу из спринта")
+	}
+
+	// Состав закрытого спринта не меняется: задача, переоткрытая после закрытия, уходит
+	// из него без отметки, чтобы не исказить отчёт
+	query = `
+		UPDATE sprint_tasks SET removed_at = $3
+		WHERE sprint_id = $1 AND task_id = $2 AND removed_at IS NULL
+			AND EXISTS (SELECT 1 FROM sprints s WHERE s.id = $1 AND s.state <> 'closed')
+	`
+	if _, err := tx.ExecContext(ctx, query, sprintID, taskID, removedAt); err != nil {
+		return errors.Internal(err, "Не удалось убрать задачу из спринта")
+	}
+
+	return nil
+}
+
+// GetReport считает итоги спринта по истории его состава (см. domain.SprintReport).
+// Задача считается перенесённой, если покинула спринт в момент его закрытия, и выполненной,
+// если по истории статусов была закрыта на момент закрытия спринта (для незакрытого - сейчас),
+// поэтому переоткрытие задачи после закрытия спринта не меняет его отчёт.
+func (r *sprintRepository) GetReport(ctx context.Context, id uuid.UUID) (*domain.SprintReport, error) {
+	query := `
+		WITH scope AS (
+			SELECT
+				st.committed OR s.state = 'planned' AS committed,
+				st.removed_at IS NULL AND (
+					SELECT tr.to_status
+					FROM task_status_transitions tr
+					WHERE tr.task_id = st.task_id AND tr.changed_at <= COALESCE(s.closed_at, CURRENT_TIMESTAMP)
+					ORDER BY tr.changed_at DESC, tr.id DESC
+					LIMIT 1
+				) IS NOT DISTINCT FROM 'closed' AS completed,
+				st.removed_at IS NOT NULL AND st.removed_at = s.closed_at AS carried_over,
+				st.removed_at IS NOT NULL AND st.removed_at IS DISTINCT FROM s.closed_at AS removed,
+				(
+					SELECT COALESCE(SUM(te.hours), 0)
+					FROM time_entries te
+					WHERE te.task_id = st.task_id AND te.deleted_at IS NULL
+						AND te.entry_date BETWEEN s.start_date AND COALESCE(s.closed_at::date, s.end_date)
+				) AS hours
+			FROM sprint_tasks st
+			INNER JOIN sprints s ON s.id = st.sprint_id
+			INNER JOIN tasks t ON t.id = st.task_id AND t.deleted_at IS NULL
+			WHERE st.sprint_id = $1 AND NOT (s.state = 'planned' AND st.removed_at IS NOT NULL)
+		)
+		SELECT
+			COUNT(*) FILTER (WHERE committed),
+			COUNT(*) FILTER (WHERE NOT committed),
+			COUNT(*) FILTER (WHERE removed),
+			COUNT(*) FILTER (WHERE carried_over),
+			COUNT(*) FILTER (WHERE completed),
+			COUNT(*) FILTER (WHERE completed AND committed),
+			COALESCE(SUM(hours) FILTER (WHERE committed), 0),
+			COALESCE(SUM(hours) FILTER (WHERE completed), 0),
+			COALESCE(SUM(hours), 0)
+		FROM scope
+	`
+
+	report := &domain.SprintReport{SprintID: id}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&report.CommittedCount, &report.AddedCount, &report.RemovedCount, &report.CarriedOverCount,
+		&report.CompletedCount, &report.CompletedCommitted,
+		&report.CommittedHours, &report.CompletedHours, &report.TotalHours,
+	)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось построить отчёт по спринту")
+	}
+
+	return report, nil
+}
+
+func sprintScanDest(s *domain.Sprint) []interface{} {
+	return []interface{}{
+		&s.ID, &s.ProjectID, &s.Name, &s.Goal, &s.StartDate, &s.EndDate, &s.State, &s.StartedAt, &s.ClosedAt,
+		&s.CreatedBy, &s.CreatedAt, &s.UpdatedAt,
+	}
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type statusTransitionRepository struct {
+	db *sql.DB
+}
+
+func NewStatusTransitionRepository(db *sql.DB) StatusTransitionRepository {
+	return &statusTransitionRepository{db: db}
+}
+
+func (r *statusTransitionRepository) CreateWithTx(ctx context.Context, tx *sql.Tx, t *domain.StatusTransition) error {
+	query := `
+		INSERT INTO task_status_transitions (task_id, from_status, to_status, changed_by, changed_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	err := tx.QueryRowContext(ctx, query, t.TaskID, t.FromStatus, t.ToStatus, t.ChangedBy, t.ChangedAt).Scan(&t.ID)
+	if err != nil {
+		return errors.Internal(err, "Не удалось сохранить смену статуса")
+	}
+
+	return nil
+}
+
+func (r *statusTransitionRepository) GetByTask(ctx context.Context, taskID uuid.UUID) ([]*domain.StatusTransition, error) {
+	query := `
+		SELECT id, task_id, from_status, to_status, changed_by, changed_at
+		FROM task_status_transitions
+		WHERE task_id = $1
+		ORDER BY changed_at ASC, id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, taskID)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить историю статусов")
+	}
+	defer rows.Close()
+
+	transitions := []*domain.StatusTransition{}
+	for rows.Next() {
+		t := &domain.StatusTransition{}
+		if err := rows.Scan(&t.ID, &t.TaskID, &t.FromStatus, &t.ToStatus, &t.ChangedBy, &t.ChangedAt); err != nil {
+			return nil, errors.Internal(err, "Не удалось обработать историю статусов")
+		}
+		transitions = append(transitions, t)
+	}
+
+	return transitions, nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// taskBoardLockKey - ключ advisory-блокировки, сериализующей перемещения по доске,
+// чтобы одновременные запросы не нарушили WIP-лимиты и не получили одинаковый ранг
+const taskBoardLockKey int64 = 0x7461736b62726400
+
+// boardOrder - порядок задач внутри колонки доски
+const boardOrder = ` ORDER BY t.board_rank ASC NULLS LAST, t.created_at ASC, t.id ASC`
+
+// GetBoard возвращает задачи указанных колонок в порядке ранга, не больше perColumn на колонку
+func (r *taskRepository) GetBoard(ctx context.Context, statuses []domain.TaskStatus, filter TaskFilter, perColumn int) ([]*domain.Task, error) {
+	names := make([]string, len(statuses))
+	for i, status := range statuses {
+		names[i] = string(status)
+	}
+
+	conditions, args, argPos := buildTaskConditions(filter, 2)
+	query := `
+		SELECT b.* FROM unnest($1::text[]) AS s(status)
+		CROSS JOIN LATERAL (
+			SELECT ` + taskColumns + `
+			FROM tasks t
+			WHERE t.deleted_at IS NULL AND t.status::text = s.status` + conditions + boardOrder +
+		fmt.Sprintf(` LIMIT $%d`, argPos) + `
+		) b
+	`
+	args = append([]interface{}{pq.Array(names)}, args...)
+	args = append(args, perColumn)
+
+	return r.queryTasks(ctx, query, args...)
+}
+
+// CountByStatus возвращает число задач, подходящих под фильтр, по статусам
+func (r *taskRepository) CountByStatus(ctx context.Context, filter TaskFilter) (map[domain.TaskStatus]int, error) {
+	conditions, args, _ := buildTaskConditions(filter, 1)
+	query := `SELECT t.status, COUNT(*) FROM tasks t WHERE t.deleted_at IS NULL` + conditions + ` GROUP BY t.status`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось подсчитать задачи")
+	}
+	defer rows.Close()
+
+	counts := map[domain.TaskStatus]int{}
+	for rows.Next() {
+		var status domain.TaskStatus
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, errors.Internal(err, "Не удалось подсчитать задачи")
+		}
+		counts[status] = count
+	}
+
+	return counts, nil
+}
+
+func (r *taskRepository) LockBoardWithTx(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, taskBoardLockKey); err != nil {
+		return errors.Internal(err, "Не удалось заблокировать доску")
+	}
+	return nil
+}
+
+// GetForMoveWithTx перечитывает задачу с блокировкой строки до конца транзакции
+func (r *taskRepository) GetForMoveWithTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*domain.Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks t
+		WHERE t.id = $1 AND t.deleted_at IS NULL
+		FOR UPDATE OF t
+	`
+
+	task := &domain.Task{}
+	err := tx.QueryRowContext(ctx, query, id).Scan(taskScanDest(task)...)
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("Задача не найдена")
+	}
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить задачу")
+	}
+
+	return task, nil
+}
+
+// CountInColumnWithTx считает неархивные задачи колонки в том же проекте (или вне проектов),
+// не считая задачу excludeID
+func (r *taskRepository) CountInColumnWithTx(ctx context.Context, tx *sql.Tx, status domain.TaskStatus, projectID *uuid.UUID, excludeID uuid.UUID) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM tasks
+		WHERE status = $1 AND project_id IS NOT DISTINCT FROM $2 AND id <> $3
+			AND archived = false AND deleted_at IS NULL
+	`
+
+	var count int
+	if err := tx.QueryRowContext(ctx, query, status, projectID, excludeID).Scan(&count); err != nil {
+		return 0, errors.Internal(err, "Не удалось подсчитать задачи колонки")
+	}
+
+	return count, nil
+}
+
+// GetBoardPositionWithTx возвращает статус и ранг задачи (пустой, если ранг не задан)
+func (r *taskRepository) GetBoardPositionWithTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) (domain.TaskStatus, string, error) {
+	query := `SELECT status, COALESCE(board_rank, '') FROM tasks WHERE id = $1 AND deleted_at IS NULL`
+
+	var status domain.TaskStatus
+	var rank string
+	err := tx.QueryRowContext(ctx, query, id).Scan(&status, &rank)
+	if err == sql.ErrNoRows {
+		return "", "", errors.NotFound("Задача не найдена")
+	}
+	if err != nil {
+		return "", "", errors.Internal(err, "Не удалось получить позицию задачи")
+	}
+
+	return status, rank, nil
+}
+
+// LastRankWithTx возвращает наибольший ранг в колонке (пустой для пустой колонки).
+// tx может быть nil.
+func (r *taskRepository) LastRankWithTx(ctx context.Context, tx *sql.Tx, status domain.TaskStatus) (string, error) {
+	query := `SELECT COALESCE(MAX(board_rank), '') FROM tasks WHERE status = $1 AND deleted_at IS NULL`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, status)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, status)
+	}
+
+	var rank string
+	if err := row.Scan(&rank); err != nil {
+		return "", errors.Internal(err, "Не удалось получить ранг колонки")
+	}
+
+	return rank, nil
+}
+
+// AdjacentRankWithTx возвращает ранг соседа задачи с рангом rank в колонке: ближайший
+// меньший при before = true, иначе ближайший больший (пустой, если соседа нет).
+// Задача excludeID не учитывается.
+func (r *taskRepository) AdjacentRankWithTx(ctx context.Context, tx *sql.Tx, status domain.TaskStatus, rank string, excludeID uuid.UUID, before bool) (string, error) {
+	query := `SELECT COALESCE(MIN(board_rank), '') FROM tasks WHERE status = $1 AND board_rank > $2 AND id <> $3 AND deleted_at IS NULL`
+	if before {
+		query = `SELECT COALESCE(MAX(board_rank), '') FROM tasks WHERE status = $1 AND board_rank < $2 AND id <> $3 AND deleted_at IS NULL`
+	}
+
+	var adjacent string
+	if err := tx.QueryRowContext(ctx, query, status, rank, excludeID).Scan(&adjacent); err != nil {
+		return "", errors.Internal(err, "Не удалось получить ранг соседней задачи")
+	}
+
+	return adjacent, nil
+}
+
+// MoveWithTx задаёт задаче статус и ранг и возвращает прежний статус
+func (r *taskRepository) MoveWithTx(ctx context.Context, tx *sql.Tx, id uuid.UUID, status domain.TaskStatus, rank string) (domain.TaskStatus, error) {
+	var oldStatus domain.TaskStatus
+
+	querySelect := `SELECT status FROM tasks WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	err := tx.QueryRowContext(ctx, querySelect, id).Scan(&oldStatus)
+	if err == sql.ErrNoRows {
+		return "", errors.NotFound("Задача не найдена")
+	}
+	if err != nil {
+		return "", errors.Internal(err, "Не удалось получить статус задачи")
+	}
+
+	queryUpdate := `UPDATE tasks SET status = $1, board_rank = $2 WHERE id = $3`
+	if _, err := tx.ExecContext(ctx, queryUpdate, status, rank, id); err != nil {
+		return "", errors.Internal(err, "Не удалось переместить задачу")
+	}
+
+	return oldStatus, nil
+}
@@ -0,0 +1,332 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/pkg/errors"
+)
+
+// taskSortColumn - колонка, по которой разрешено сортировать задачи
+type taskSortColumn struct {
+	expr  string
+	kind  columnKind
+	value func(t *domain.Task) interface{}
+}
+
+// taskSortColumns - разрешённые поля сортировки задач и соответствующие им колонки.
+// В SQL попадают только значения из этой таблицы, а не ввод пользователя.
+var taskSortColumns = map[string]taskSortColumn{
+	"priority":   {"t.priority", kindInt, func(t *domain.Task) interface{} { return t.Priority }},
+	"status":     {"t.status", kindString, func(t *domain.Task) interface{} { return string(t.Status) }},
+	"title":      {"t.title", kindString, func(t *domain.Task) interface{} { return t.Title }},
+	"due_date":   {"t.due_date", kindTime, func(t *domain.Task) interface{} { return timeOrNil(t.DueDate) }},
+	"created_at": {"t.created_at", kindTime, func(t *domain.Task) interface{} { return t.CreatedAt }},
+	"updated_at": {"t.updated_at", kindTime, func(t *domain.Task) interface{} { return t.UpdatedAt }},
+}
+
+// defaultTaskSort - порядок по умолчанию: сначала новые задачи
+var defaultTaskSort = []SortField{{Field: "created_at", Desc: true}}
+
+// IsTaskSortField сообщает, можно ли сортировать задачи по указанному полю
+func IsTaskSortField(field string) bool {
+	_, ok := taskSortColumns[field]
+	return ok
+}
+
+// buildTaskConditions формирует условия WHERE для фильтра задач (таблица tasks под алиасом t).
+// Значения передаются только через плейсхолдеры, нумерация которых начинается с argPos.
+func buildTaskConditions(filter TaskFilter, argPos int) (string, []interface{}, int) {
+	var sb strings.Builder
+	args := []interface{}{}
+
+	add := func(condition string, value interface{}) {
+		sb.WriteString(" AND ")
+		sb.WriteString(fmt.Sprintf(condition, argPos))
+		args = append(args, value)
+		argPos++
+	}
+
+	if len(filter.Status) > 0 {
+		placeholders := []string{}
+		for _, status := range filter.Status {
+			placeholders = append(placeholders, fmt.Sprintf("$%d", argPos))
+			args = append(args, status)
+			argPos++
+		}
+		sb.WriteString(" AND t.status IN (" + strings.Join(placeholders, ",") + ")")
+	}
+
+	if filter.Priority != nil {
+		add("t.priority = $%d", *filter.Priority)
+	}
+	if filter.PriorityMin != nil {
+		add("t.priority >= $%d", *filter.PriorityMin)
+	}
+	if filter.PriorityMax != nil {
+		add("t.priority <= $%d", *filter.PriorityMax)
+	}
+
+	if filter.Archived != nil {
+		add("t.archived = $%d", *filter.Archived)
+	}
+
+	if filter.CreatedBy != nil {
+		add("t.created_by = $%d", *filter.CreatedBy)
+	}
+
+	if filter.ParticipantID != nil {
+		condition := fmt.Sprintf("EXISTS (SELECT 1 FROM task_participants tp WHERE tp.task_id = t.id AND tp.employee_id = $%d", argPos)
+		args = append(args, *filter.ParticipantID)
+		argPos++
+		if filter.ParticipantRole != nil {
+			condition += fmt.Sprintf(" AND tp.role = $%d", argPos)
+			args = append(args, *filter.ParticipantRole)
+			argPos++
+		}
+		sb.WriteString(" AND " + condition + ")")
+	} else if filter.ParticipantRole != nil {
+		add("EXISTS (SELECT 1 FROM task_participants tp WHERE tp.task_id = t.id AND tp.role = $%d)", *filter.ParticipantRole)
+	}
+
+	if filter.DueFrom != nil {
+		add("t.due_date >= $%d", *filter.DueFrom)
+	}
+	if filter.DueTo != nil {
+		add("t.due_date <= $%d", *filter.DueTo)
+	}
+	if filter.CreatedFrom != nil {
+		add("t.created_at >= $%d", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		add("t.created_at <= $%d", *filter.CreatedTo)
+	}
+	if filter.UpdatedFrom != nil {
+		add("t.updated_at >= $%d", *filter.UpdatedFrom)
+	}
+	if filter.UpdatedTo != nil {
+		add("t.updated_at <= $%d", *filter.UpdatedTo)
+	}
+
+	if filter.ProjectID != nil {
+		add("t.project_id = $%d", *filter.ProjectID)
+	}
+
+	if filter.ParentID != nil {
+		add("t.parent_id = $%d", *filter.ParentID)
+	}
+	if filter.TopLevel {
+		sb.WriteString(" AND t.parent_id IS NULL")
+	}
+
+	if filter.SprintID != nil {
+		add("t.sprint_id = $%d", *filter.SprintID)
+	}
+	if filter.Backlog {
+		sb.WriteString(" AND t.sprint_id IS NULL")
+	}
+
+	if len(filter.LabelsAny) > 0 {
+		placeholders := labelPlaceholders(filter.LabelsAny, &args, &argPos)
+		sb.WriteString(" AND EXISTS (SELECT 1 FROM task_labels tl INNER JOIN labels lb ON lb.id = tl.label_id" +
+			" WHERE tl.task_id = t.id AND LOWER(lb.name) IN (" + placeholders + "))")
+	}
+	if len(filter.LabelsAll) > 0 {
+		placeholders := labelPlaceholders(filter.LabelsAll, &args, &argPos)
+		sb.WriteString(fmt.Sprintf(" AND (SELECT COUNT(DISTINCT LOWER(lb.name)) FROM task_labels tl INNER JOIN labels lb ON lb.id = tl.label_id"+
+			" WHERE tl.task_id = t.id AND LOWER(lb.name) IN (%s)) = %d", placeholders, countDistinct(filter.LabelsAll)))
+	}
+
+	for _, condition := range filter.CustomFields {
+		field := filter.CustomFieldDefs[condition.Key]
+		if field == nil {
+			continue
+		}
+
+		expr := customFieldValueExpr(field)
+		parts := []string{fmt.Sprintf("cv.field_id = $%d", argPos)}
+		args = append(args, field.ID)
+		argPos++
+
+		if len(condition.Values) > 0 {
+			alternatives := make([]string, 0, len(condition.Values))
+			for _, value := range condition.Values {
+				if field.Type == domain.CustomFieldMultiSelect {
+					alternatives = append(alternatives, fmt.Sprintf("cv.value @> jsonb_build_array($%d::text)", argPos))
+				} else {
+					alternatives = append(alternatives, fmt.Sprintf("%s = %s", expr, customFieldPlaceholder(field, argPos)))
+				}
+				args = append(args, value)
+				argPos++
+			}
+			parts = append(parts, "("+strings.Join(alternatives, " OR ")+")")
+		}
+		if condition.Min != "" {
+			parts = append(parts, fmt.Sprintf("%s >= %s", expr, customFieldPlaceholder(field, argPos)))
+			args = append(args, condition.Min)
+			argPos++
+		}
+		if condition.Max != "" {
+			parts = append(parts, fmt.Sprintf("%s <= %s", expr, customFieldPlaceholder(field, argPos)))
+			args = append(args, condition.Max)
+			argPos++
+		}
+
+		sb.WriteString(" AND EXISTS (SELECT 1 FROM task_custom_values cv WHERE cv.task_id = t.id AND " +
+			strings.Join(parts, " AND ") + ")")
+	}
+
+	if filter.VisibleTo != nil {
+		add("(t.project_id IS NULL OR EXISTS (SELECT 1 FROM project_members pm WHERE pm.project_id = t.project_id AND pm.employee_id = $%d))", *filter.VisibleTo)
+	}
+
+	if filter.Text != "" {
+		sb.WriteString(fmt.Sprintf(" AND (t.title ILIKE $%d ESCAPE '\\' OR t.description ILIKE $%d ESCAPE '\\')", argPos, argPos))
+		args = append(args, "%"+escapeLike(filter.Text)+"%")
+		argPos++
+	}
+
+	return sb.String(), args, argPos
+}
+
+// customFieldValueExpr - выражение значения поля из task_custom_values (алиас cv)
+// с приведением к типу, в котором его сравнивают и сортируют
+func customFieldValueExpr(field *domain.CustomField) string {
+	switch field.Type {
+	case domain.CustomFieldNumber:
+		return "(cv.value #>> '{}')::numeric"
+	case domain.CustomFieldDate:
+		return "(cv.value #>> '{}')::date"
+	}
+	return "(cv.value #>> '{}')"
+}
+
+func customFieldPlaceholder(field *domain.CustomField, argPos int) string {
+	switch field.Type {
+	case domain.CustomFieldNumber:
+		return fmt.Sprintf("$%d::numeric", argPos)
+	case domain.CustomFieldDate:
+		return fmt.Sprintf("$%d::date", argPos)
+	}
+	return fmt.Sprintf("$%d", argPos)
+}
+
+// labelPlaceholders добавляет имена меток (в нижнем регистре) в аргументы запроса
+// и возвращает список плейсхолдеров для IN
+func labelPlaceholders(names []string, args *[]interface{}, argPos *int) string {
+	placeholders := make([]string, 0, len(names))
+	for _, name := range names {
+		placeholders = append(placeholders, fmt.Sprintf("$%d", *argPos))
+		*args = append(*args, strings.ToLower(name))
+		*argPos++
+	}
+	return strings.Join(placeholders, ",")
+}
+
+// countDistinct считает различные имена меток без учёта регистра
+func countDistinct(names []string) int {
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		seen[strings.ToLower(name)] = struct{}{}
+	}
+	return len(seen)
+}
+
+// buildTaskOrder формирует ORDER BY для задач. Последним ключом всегда добавляется id,
+// чтобы порядок был однозначным и стабильным между страницами.
+// Поля вида cf.<ключ> берутся из определений fields.
+func buildTaskOrder(sort []SortField, fields map[string]*domain.CustomField) (string, error) {
+	if len(sort) == 0 {
+		sort = defaultTaskSort
+	}
+
+	parts := make([]string, 0, len(sort)+1)
+	for _, field := range sort {
+		expr, err := taskSortExpr(field.Field, fields)
+		if err != nil {
+			return "", err
+		}
+		if field.Desc {
+			parts = append(parts, expr+" DESC NULLS LAST")
+		} else {
+			parts = append(parts, expr+" ASC NULLS LAST")
+		}
+	}
+	parts = append(parts, "t.id DESC")
+
+	return " ORDER BY " + strings.Join(parts, ", "), nil
+}
+
+func taskSortExpr(name string, fields map[string]*domain.CustomField) (string, error) {
+	if !strings.HasPrefix(name, CustomFieldPrefix) {
+		column, ok := taskSortColumns[name]
+		if !ok {
+			return "", errors.BadRequest("Недопустимое поле сортировки: " + name)
+		}
+		return column.expr, nil
+	}
+
+	field := fields[strings.TrimPrefix(name, CustomFieldPrefix)]
+	if field == nil || !field.Type.IsSortable() {
+		return "", errors.BadRequest("Недопустимое поле сортировки: " + name)
+	}
+	// ID поля берётся из определения, а не из запроса, поэтому подставляется в текст
+	return fmt.Sprintf("(SELECT %s FROM task_custom_values cv WHERE cv.task_id = t.id AND cv.field_id = '%s')",
+		customFieldValueExpr(field), field.ID), nil
+}
+
+// taskKeysetKeys возвращает ключи сортировки для keyset-пагинации и подпись сортировки,
+// по которой проверяется, что курсор выдан для того же порядка
+func taskKeysetKeys(sort []SortField) ([]keysetKey, string, error) {
+	if len(sort) == 0 {
+		sort = defaultTaskSort
+	}
+
+	keys := make([]keysetKey, 0, len(sort)+1)
+	signature := make([]string, 0, len(sort))
+	for _, field := range sort {
+		if strings.HasPrefix(field.Field, CustomFieldPrefix) {
+			return nil, "", errors.BadRequest("Сортировка по пользовательским полям недоступна при навигации курсором")
+		}
+		column, ok := taskSortColumns[field.Field]
+		if !ok {
+			return nil, "", errors.BadRequest("Недопустимое поле сортировки: " + field.Field)
+		}
+		keys = append(keys, keysetKey{expr: column.expr, desc: field.Desc, kind: column.kind})
+		if field.Desc {
+			signature = append(signature, "-"+field.Field)
+		} else {
+			signature = append(signature, field.Field)
+		}
+	}
+	keys = append(keys, keysetKey{expr: "t.id", desc: true, kind: kindUUID})
+
+	return keys, strings.Join(signature, ","), nil
+}
+
+// taskCursorValues извлекает из задачи значения ключей сортировки для курсора
+func taskCursorValues(t *domain.Task, sort []SortField) []interface{} {
+	if len(sort) == 0 {
+		sort = defaultTaskSort
+	}
+
+	values := make([]interface{}, 0, len(sort)+1)
+	for _, field := range sort {
+		values = append(values, taskSortColumns[field.Field].value(t))
+	}
+	return append(values, t.ID)
+}
+
+func timeOrNil(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// taskHierarchyLockKey - ключ advisory-блокировки, сериализующей изменения иерархии задач,
+// чтобы два одновременных перемещения не образовали цикл
+const taskHierarchyLockKey int64 = 0x7461736b686965
+
+// maxHierarchyWalk ограничивает рекурсивный обход на случай повреждённых данных
+const maxHierarchyWalk = 100
+
+func (r *taskRepository) GetSubtasks(ctx context.Context, parentID uuid.UUID) ([]*domain.Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks t
+		WHERE t.parent_id = $1 AND t.deleted_at IS NULL
+		ORDER BY t.created_at ASC, t.id ASC
+	`
+
+	return r.queryTasks(ctx, query, parentID)
+}
+
+// taskDepthQuery возвращает уровень задачи $1 в иерархии: 1 для задачи без родителя
+const taskDepthQuery = `
+	WITH RECURSIVE ancestors AS (
+		SELECT id, parent_id, 1 AS depth FROM tasks WHERE id = $1
+		UNION ALL
+		SELECT t.id, t.parent_id, a.depth + 1
+		FROM tasks t
+		INNER JOIN ancestors a ON t.id = a.parent_id
+		WHERE a.depth < $2
+	)
+	SELECT COALESCE(MAX(depth), 0) FROM ancestors
+`
+
+// taskSubtreeHeightQuery возвращает число уровней в поддереве задачи $1: 1, если подзадач нет
+const taskSubtreeHeightQuery = `
+	WITH RECURSIVE subtree AS (
+		SELECT id, 1 AS level FROM tasks WHERE id = $1
+		UNION ALL
+		SELECT t.id, s.level + 1
+		FROM tasks t
+		INNER JOIN subtree s ON t.parent_id = s.id
+		WHERE t.deleted_at IS NULL AND s.level < $2
+	)
+	SELECT COALESCE(MAX(level), 0) FROM subtree
+`
+
+func (r *taskRepository) GetRollup(ctx context.Context, id uuid.UUID) (*domain.TaskRollup, error) {
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id, status, 0 AS level FROM tasks WHERE id = $1 AND deleted_at IS NULL
+			UNION ALL
+			SELECT t.id, t.status, s.level + 1
+			FROM tasks t
+			INNER JOIN subtree s ON t.parent_id = s.id
+			WHERE t.deleted_at IS NULL AND s.level < $2
+		)
+		SELECT
+			COUNT(*) FILTER (WHERE level > 0),
+			COUNT(*) FILTER (WHERE level > 0 AND status = 'closed'),
+			COALESCE((
+				SELECT SUM(te.hours) FROM time_entries te
+				WHERE te.task_id IN (SELECT id FROM subtree) AND te.deleted_at IS NULL
+			), 0)
+		FROM subtree
+	`
+
+	rollup := &domain.TaskRollup{TaskID: id}
+	err := r.db.QueryRowContext(ctx, query, id, maxHierarchyWalk).Scan(
+		&rollup.TotalSubtasks, &rollup.ClosedSubtasks, &rollup.TotalHours,
+	)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить сводку по подзадачам")
+	}
+
+	return rollup, nil
+}
+
+// SetParent переносит задачу под parentID (nil - сделать задачу корневой).
+// Проверки цикла и глубины и обновление выполняются под общей блокировкой иерархии.
+func (r *taskRepository) SetParent(ctx context.Context, id uuid.UUID, parentID *uuid.UUID, maxDepth int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Internal(err, "Не удалось начать транзакцию")
+	}
+	defer tx.Rollback()
+
+	if err := lockHierarchyWithTx(ctx, tx); err != nil {
+		return err
+	}
+
+	if parentID != nil {
+		query := `
+			WITH RECURSIVE ancestors AS (
+				SELECT id, parent_id, 1 AS depth FROM tasks WHERE id = $1
+				UNION ALL
+				SELECT t.id, t.parent_id, a.depth + 1
+				FROM tasks t
+				INNER JOIN ancestors a ON t.id = a.parent_id
+				WHERE a.depth < $3
+			)
+			SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)
+		`
+
+		var cycle bool
+		if err := tx.QueryRowContext(ctx, query, *parentID, id, maxHierarchyWalk).Scan(&cycle); err != nil {
+			return errors.Internal(err, "Не удалось проверить иерархию задач")
+		}
+		if cycle {
+			return errors.Conflict("Задачу нельзя сделать подзадачей её собственной подзадачи")
+		}
+
+		var height int
+		if err := tx.QueryRowContext(ctx, taskSubtreeHeightQuery, id, maxHierarchyWalk).Scan(&height); err != nil {
+			return errors.Internal(err, "Не удалось определить глубину подзадач")
+		}
+		if err := checkParentDepthWithTx(ctx, tx, *parentID, height, maxDepth); err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `UPDATE tasks SET parent_id = $2 WHERE id = $1 AND deleted_at IS NULL`, id, parentID)
+	if err != nil {
+		return errors.Internal(err, "Не удалось изменить родительскую задачу")
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NotFound("Задача не найдена")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Internal(err, "Не удалось зафиксировать транзакцию")
+	}
+
+	return nil
+}
+
+func (r *taskRepository) CheckParentDepthWithTx(ctx context.Context, tx *sql.Tx, parentID uuid.UUID, height, maxDepth int) error {
+	if err := lockHierarchyWithTx(ctx, tx); err != nil {
+		return err
+	}
+
+	return checkParentDepthWithTx(ctx, tx, parentID, height, maxDepth)
+}
+
+func lockHierarchyWithTx(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, taskHierarchyLockKey); err != nil {
+		return errors.Internal(err, "Не удалось заблокировать иерархию задач")
+	}
+
+	return nil
+}
+
+// checkParentDepthWithTx проверяет, что поддерево высотой height поместится под parentID,
+// не превысив maxDepth уровней
+func checkParentDepthWithTx(ctx context.Context, tx *sql.Tx, parentID uuid.UUID, height, maxDepth int) error {
+	var depth int
+	if err := tx.QueryRowContext(ctx, taskDepthQuery, parentID, maxHierarchyWalk).Scan(&depth); err != nil {
+		return errors.Internal(err, "Не удалось определить уровень задачи")
+	}
+
+	if depth+height > maxDepth {
+		return errors.BadRequest(fmt.Sprintf("Превышена максимальная глубина вложенности задач (%d)", maxDepth))
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// taskLinksLockKey - ключ advisory-блокировки, сериализующей создание блокирующих связей,
+// чтобы два одновременных запроса не замкнули цикл
+const taskLinksLockKey int64 = 0x7461736b6c6e6b
+
+type taskLinkRepository struct {
+	db *sql.DB
+}
+
+func NewTaskLinkRepository(db *sql.DB) TaskLinkRepository {
+	return &taskLinkRepository{db: db}
+}
+
+// Create сохраняет связь. Для связи blocks проверяется, что целевая задача
+// (напрямую или через цепочку) уже не блокирует исходную.
+func (r *taskLinkRepository) Create(ctx context.Context, link *domain.TaskLink) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Internal(err, "Не удалось начать транзакцию")
+	}
+	defer tx.Rollback()
+
+	if link.Type == domain.TaskLinkBlocks {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, taskLinksLockKey); err != nil {
+			return errors.Internal(err, "Не удалось заблокировать связи задач")
+		}
+
+		query := `
+			WITH RECURSIVE blocked AS (
+				SELECT target_task_id AS id, 1 AS depth FROM task_links
+				WHERE source_task_id = $1 AND type = 'blocks'
+				UNION
+				SELECT l.target_task_id, b.depth + 1
+				FROM task_links l
+				INNER JOIN blocked b ON l.source_task_id = b.id
+				WHERE l.type = 'blocks' AND b.depth < $3
+			)
+			SELECT EXISTS (SELECT 1 FROM blocked WHERE id = $2)
+		`
+
+		var cycle bool
+		if err := tx.QueryRowContext(ctx, query, link.TargetTaskID, link.SourceTaskID, maxHierarchyWalk).Scan(&cycle); err != nil {
+			return errors.Internal(err, "Не удалось проверить связи задач")
+		}
+		if cycle {
+			return errors.Conflict("Связь образует цикл блокировок: целевая задача уже блокирует исходную")
+		}
+	}
+
+	query := `
+		INSERT INTO task_links (id, source_task_id, target_task_id, type, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (source_task_id, target_task_id, type) DO NOTHING
+	`
+
+	result, err := tx.ExecContext(ctx, query, link.ID, link.SourceTaskID, link.TargetTaskID, link.Type, link.CreatedBy, link.CreatedAt)
+	if err != nil {
+		return errors.Internal(err, "Не удалось создать связь задач")
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.Conflict("Такая связь уже существует")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Internal(err, "Не удалось зафиксировать транзакцию")
+	}
+
+	return nil
+}
+
+func (r *taskLinkRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.TaskLink, error) {
+	query := `
+		SELECT id, source_task_id, target_task_id, type, created_by, created_at
+		FROM task_links
+		WHERE id = $1
+	`
+
+	l := &domain.TaskLink{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&l.ID, &l.SourceTaskID, &l.TargetTaskID, &l.Type, &l.CreatedBy, &l.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("Связь не найдена")
+	}
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить связь")
+	}
+
+	return l, nil
+}
+
+// GetByTask возвращает связи задачи в обоих направлениях, кроме связей с задачами,
+// которые не видны сотруднику viewerID (удалённые или из чужих проектов)
+func (r *taskLinkRepository) GetByTask(ctx context.Context, taskID, viewerID uuid.UUID) ([]*domain.TaskLink, error) {
+	query := `
+		SELECT l.id, l.source_task_id, l.target_task_id, l.type, l.created_by, l.created_at
+		FROM task_links l
+		INNER JOIN tasks o ON o.id = CASE WHEN l.source_task_id = $1 THEN l.target_task_id ELSE l.source_task_id END
+		WHERE (l.source_task_id = $1 OR l.target_task_id = $1)
+			AND o.deleted_at IS NULL
+			AND (o.project_id IS NULL OR EXISTS (
+				SELECT 1 FROM project_members pm WHERE pm.project_id = o.project_id AND pm.employee_id = $2
+			))
+		ORDER BY l.created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, taskID, viewerID)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить связи задачи")
+	}
+	defer rows.Close()
+
+	links := []*domain.TaskLink{}
+	for rows.Next() {
+		l := &domain.TaskLink{}
+		if err := rows.Scan(&l.ID, &l.SourceTaskID, &l.TargetTaskID, &l.Type, &l.CreatedBy, &l.CreatedAt); err != nil {
+			return nil, errors.Internal(err, "Не удалось обработать данные связи")
+		}
+		links = append(links, l)
+	}
+
+	return links, nil
+}
+
+func (r *taskLinkRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM task_links WHERE id = $1`, id)
+	if err != nil {
+		return errors.Internal(err, "Не удалось удалить связь")
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NotFound("Связь не найдена")
+	}
+
+	return nil
+}
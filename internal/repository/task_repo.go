@@ -3,14 +3,63 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
-	"strings"
 
 	"github.com/dmitry/taskmanager/internal/domain"
 	"github.com/dmitry/taskmanager/pkg/errors"
 	"github.com/google/uuid"
 )
 
+// taskColumns - колонки задачи (таблица tasks под алиасом t) в порядке taskScanDest.
+// blocked вычисляется: задачу блокирует незакрытая задача по связи типа blocks;
+// labels - JSON-массив меток задачи, custom_fields - объект значений пользовательских полей.
+const taskColumns = `t.id, t.project_id, t.key, t.parent_id, t.title, t.description, t.status, t.priority,
+	t.created_by, t.archived, t.due_date, t.created_at, t.updated_at, t.board_rank, t.sprint_id,
+	EXISTS (
+		SELECT 1 FROM task_links l
+		INNER JOIN tasks blocker ON blocker.id = l.source_task_id
+		WHERE l.target_task_id = t.id AND l.type = 'blocks' AND blocker.status <> 'closed' AND blocker.deleted_at IS NULL
+	) AS blocked,
+	COALESCE((
+		SELECT json_agg(json_build_object('id', lb.id, 'name', lb.name, 'color', lb.color) ORDER BY lb.name)
+		FROM task_labels tl
+		INNER JOIN labels lb ON lb.id = tl.label_id
+		WHERE tl.task_id = t.id
+	), '[]') AS labels,
+	COALESCE((
+		SELECT json_object_agg(cf.key, cv.value)
+		FROM task_custom_values cv
+		INNER JOIN custom_fields cf ON cf.id = cv.field_id
+		WHERE cv.task_id = t.id
+	), '{}') AS custom_fields`
+
+// taskScanDest возвращает приёмники Scan для колонок taskColumns
+func taskScanDest(task *domain.Task) []interface{} {
+	return []interface{}{
+		&task.ID, &task.ProjectID, &task.Key, &task.ParentID, &task.Title, &task.Description, &task.Status, &task.Priority,
+		&task.CreatedBy, &task.Archived, &task.DueDate, &task.CreatedAt, &task.UpdatedAt, &task.BoardRank, &task.SprintID, &task.Blocked,
+		jsonColumn{&task.Labels}, jsonColumn{&task.CustomFields},
+	}
+}
+
+// jsonColumn разбирает JSON-колонку (массив меток, значения полей) в dest
+type jsonColumn struct {
+	dest interface{}
+}
+
+func (c jsonColumn) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, c.dest)
+	case string:
+		return json.Unmarshal([]byte(v), c.dest)
+	case nil:
+		return nil
+	}
+	return fmt.Errorf("неожиданный тип JSON-колонки: %T", src)
+}
+
 type taskRepository struct {
 	db *sql.DB
 }
@@ -25,17 +74,17 @@ func (r *taskRepository) Create(ctx context.Context, task *domain.Task) error {
 
 func (r *taskRepository) CreateWithTx(ctx context.Context, tx *sql.Tx, task *domain.Task) error {
 	query := `
-		INSERT INTO tasks (id, title, description, status, priority, created_by, archived, due_date, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO tasks (id, project_id, key, parent_id, title, description, status, priority, created_by, archived, due_date, created_at, updated_at, board_rank)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
 	var err error
 	if tx != nil {
-		_, err = tx.ExecContext(ctx, query, task.ID, task.Title, task.Description, task.Status,
-			task.Priority, task.CreatedBy, task.Archived, task.DueDate, task.CreatedAt, task.UpdatedAt)
+		_, err = tx.ExecContext(ctx, query, task.ID, task.ProjectID, task.Key, task.ParentID, task.Title, task.Description, task.Status,
+			task.Priority, task.CreatedBy, task.Archived, task.DueDate, task.CreatedAt, task.UpdatedAt, task.BoardRank)
 	} else {
-		_, err = r.db.ExecContext(ctx, query, task.ID, task.Title, task.Description, task.Status,
-			task.Priority, task.CreatedBy, task.Archived, task.DueDate, task.CreatedAt, task.UpdatedAt)
+		_, err = r.db.ExecContext(ctx, query, task.ID, task.ProjectID, task.Key, task.ParentID, task.Title, task.Description, task.Status,
+			task.Priority, task.CreatedBy, task.Archived, task.DueDate, task.CreatedAt, task.UpdatedAt, task.BoardRank)
 	}
 
 	if err != nil {
@@ -47,16 +96,28 @@ func (r *taskRepository) CreateWithTx(ctx context.Context, tx *sql.Tx, task *dom
 
 func (r *taskRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Task, error) {
 	query := `
-		SELECT id, title, description, status, priority, created_by, archived, due_date, created_at, updated_at
-		FROM tasks
-		WHERE id = $1 AND deleted_at IS NULL
+		SELECT ` + taskColumns + `
+		FROM tasks t
+		WHERE t.id = $1 AND t.deleted_at IS NULL
+	`
+
+	return r.getOne(ctx, query, id)
+}
+
+// GetByKey возвращает задачу проекта по её ключу, например CORE-123
+func (r *taskRepository) GetByKey(ctx context.Context, key string) (*domain.Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks t
+		WHERE t.key = $1 AND t.deleted_at IS NULL
 	`
 
+	return r.getOne(ctx, query, key)
+}
+
+func (r *taskRepository) getOne(ctx context.Context, query string, args ...interface{}) (*domain.Task, error) {
 	task := &domain.Task{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&task.ID, &task.Title, &task.Description, &task.Status, &task.Priority,
-		&task.CreatedBy, &task.Archived, &task.DueDate, &task.CreatedAt, &task.UpdatedAt,
-	)
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(taskScanDest(task)...)
 
 	if err == sql.ErrNoRows {
 		return nil, errors.NotFound("Задача не найдена")
@@ -69,42 +130,19 @@ func (r *taskRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Tas
 }
 
 func (r *taskRepository) GetAll(ctx context.Context, filter TaskFilter) ([]*domain.Task, int, error) {
-	query := `SELECT id, title, description, status, priority, created_by, archived, due_date, created_at, updated_at FROM tasks WHERE deleted_at IS NULL`
-	countQuery := `SELECT COUNT(*) FROM tasks WHERE deleted_at IS NULL`
-
-	args := []interface{}{}
-	argPos := 1
-
-	if len(filter.Status) > 0 {
-		placeholders := []string{}
-		for _, status := range filter.Status {
-			placeholders = append(placeholders, fmt.Sprintf("$%d", argPos))
-			args = append(args, status)
-			argPos++
-		}
-		statusFilter := " AND status IN (" + strings.Join(placeholders, ",") + ")"
-		query += statusFilter
-		countQuery += statusFilter
-	}
+	query := `SELECT ` + taskColumns + ` FROM tasks t WHERE t.deleted_at IS NULL`
 
-	if filter.Priority != nil {
-		query += fmt.Sprintf(" AND priority = $%d", argPos)
-		countQuery += fmt.Sprintf(" AND priority = $%d", argPos)
-		args = append(args, *filter.Priority)
-		argPos++
-	}
+	conditions, args, argPos := buildTaskConditions(filter, 1)
+	query += conditions
 
-	if filter.Archived != nil {
-		query += fmt.Sprintf(" AND archived = $%d", argPos)
-		countQuery += fmt.Sprintf(" AND archived = $%d", argPos)
-		args = append(args, *filter.Archived)
-		argPos++
+	orderBy, err := buildTaskOrder(filter.Sort, filter.CustomFieldDefs)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	var total int
-	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
+	total, err := r.Count(ctx, filter)
 	if err != nil {
-		return nil, 0, errors.Internal(err, "Не удалось подсчитать задачи")
+		return nil, 0, err
 	}
 
 	if filter.Page < 1 {
@@ -115,27 +153,106 @@ func (r *taskRepository) GetAll(ctx context.Context, filter TaskFilter) ([]*doma
 	}
 
 	offset := (filter.Page - 1) * filter.PageSize
-	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argPos, argPos+1)
+	query += orderBy + fmt.Sprintf(" LIMIT $%d OFFSET $%d", argPos, argPos+1)
 	args = append(args, filter.PageSize, offset)
 
+	tasks, err := r.queryTasks(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tasks, total, nil
+}
+
+// GetAllByCursor возвращает страницу задач после filter.Cursor в порядке filter.Sort
+// и курсор следующей страницы (пустой, если страница последняя)
+func (r *taskRepository) GetAllByCursor(ctx context.Context, filter TaskFilter) ([]*domain.Task, string, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks t WHERE t.deleted_at IS NULL`
+
+	conditions, args, argPos := buildTaskConditions(filter, 1)
+	query += conditions
+
+	orderBy, err := buildTaskOrder(filter.Sort, filter.CustomFieldDefs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	keys, signature, err := taskKeysetKeys(filter.Sort)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if filter.Cursor != "" {
+		if err := decodeCursor(filter.Cursor, signature, keys); err != nil {
+			return nil, "", err
+		}
+		keyset, keysetArgs, nextPos := buildKeysetCondition(keys, argPos)
+		query += keyset
+		args = append(args, keysetArgs...)
+		argPos = nextPos
+	}
+
+	// Запрашиваем на одну запись больше, чтобы узнать, есть ли следующая страница
+	limit := NormalizeCursorLimit(filter.Limit)
+	query += orderBy + fmt.Sprintf(" LIMIT $%d", argPos)
+	args = append(args, limit+1)
+
+	tasks, err := r.queryTasks(ctx, query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(tasks) <= limit {
+		return tasks, "", nil
+	}
+
+	tasks = tasks[:limit]
+	next, err := encodeCursor(signature, taskCursorValues(tasks[limit-1], filter.Sort))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return tasks, next, nil
+}
+
+// Count возвращает количество задач, подходящих под фильтр
+func (r *taskRepository) Count(ctx context.Context, filter TaskFilter) (int, error) {
+	query := `SELECT COUNT(*) FROM tasks t WHERE t.deleted_at IS NULL`
+
+	conditions, args, _ := buildTaskConditions(filter, 1)
+	query += conditions
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return 0, errors.Internal(err, "Не удалось подсчитать задачи")
+	}
+
+	return total, nil
+}
+
+func (r *taskRepository) queryTasks(ctx context.Context, query string, args ...interface{}) ([]*domain.Task, error) {
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, 0, errors.Internal(err, "Не удалось получить список задач")
+		return nil, errors.Internal(err, "Не удалось получить список задач")
 	}
 	defer rows.Close()
 
 	tasks := []*domain.Task{}
 	for rows.Next() {
 		task := &domain.Task{}
-		err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.Priority,
-			&task.CreatedBy, &task.Archived, &task.DueDate, &task.CreatedAt, &task.UpdatedAt)
+		err := rows.Scan(taskScanDest(task)...)
 		if err != nil {
-			return nil, 0, errors.Internal(err, "Не удалось обработать данные задачи")
+			return nil, errors.Internal(err, "Не удалось обработать данные задачи")
 		}
 		tasks = append(tasks, task)
 	}
 
-	return tasks, total, nil
+	// Обрыв соединения посреди чтения не должен давать молча урезанную страницу
+	if err := rows.Err(); err != nil {
+		return nil, errors.Internal(err, "Не удалось получить список задач")
+	}
+
+	return tasks, nil
 }
 
 func (r *taskRepository) Update(ctx context.Context, task *domain.Task) error {
@@ -225,75 +342,27 @@ func (r *taskRepository) Archive(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// GetTasksForEmployee возвращает задачи, где сотрудник является участником.
+// Остальные условия фильтра применяются так же, как в GetAll; роль участника
+// (ParticipantRole) в этом случае относится к указанному сотруднику.
 func (r *taskRepository) GetTasksForEmployee(ctx context.Context, employeeID uuid.UUID, filter TaskFilter) ([]*domain.Task, int, error) {
-	query := `
-		SELECT DISTINCT t.id, t.title, t.description, t.status, t.priority, t.created_by, t.archived, t.due_date, t.created_at, t.updated_at
-		FROM tasks t
-		INNER JOIN task_participants tp ON t.id = tp.task_id
-		WHERE t.deleted_at IS NULL AND tp.employee_id = $1
-	`
-	countQuery := `
-		SELECT COUNT(DISTINCT t.id)
-		FROM tasks t
-		INNER JOIN task_participants tp ON t.id = tp.task_id
-		WHERE t.deleted_at IS NULL AND tp.employee_id = $1
-	`
-
-	args := []interface{}{employeeID}
-	argPos := 2
-
-	if len(filter.Status) > 0 {
-		placeholders := []string{}
-		for _, status := range filter.Status {
-			placeholders = append(placeholders, fmt.Sprintf("$%d", argPos))
-			args = append(args, status)
-			argPos++
-		}
-		statusFilter := " AND t.status IN (" + strings.Join(placeholders, ",") + ")"
-		query += statusFilter
-		countQuery += statusFilter
-	}
-
-	if filter.Archived != nil {
-		query += fmt.Sprintf(" AND t.archived = $%d", argPos)
-		countQuery += fmt.Sprintf(" AND t.archived = $%d", argPos)
-		args = append(args, *filter.Archived)
-		argPos++
-	}
-
-	var total int
-	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
-	if err != nil {
-		return nil, 0, errors.Internal(err, "Не удалось подсчитать задачи")
-	}
-
-	if filter.Page < 1 {
-		filter.Page = 1
-	}
-	if filter.PageSize < 1 {
-		filter.PageSize = 20
-	}
-
-	offset := (filter.Page - 1) * filter.PageSize
-	query += fmt.Sprintf(" ORDER BY t.created_at DESC LIMIT $%d OFFSET $%d", argPos, argPos+1)
-	args = append(args, filter.PageSize, offset)
+	filter.ParticipantID = &employeeID
+	return r.GetAll(ctx, filter)
+}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
-	if err != nil {
-		return nil, 0, errors.Internal(err, "Не удалось получить список задач")
-	}
-	defer rows.Close()
+func (r *taskRepository) GetTasksForEmployeeByCursor(ctx context.Context, employeeID uuid.UUID, filter TaskFilter) ([]*domain.Task, string, error) {
+	filter.ParticipantID = &employeeID
+	return r.GetAllByCursor(ctx, filter)
+}
 
-	tasks := []*domain.Task{}
-	for rows.Next() {
-		task := &domain.Task{}
-		err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.Priority,
-			&task.CreatedBy, &task.Archived, &task.DueDate, &task.CreatedAt, &task.UpdatedAt)
-		if err != nil {
-			return nil, 0, errors.Internal(err, "Не удалось обработать данные задачи")
-		}
-		tasks = append(tasks, task)
-	}
+func (r *taskRepository) GetOpenBlockers(ctx context.Context, id uuid.UUID) ([]*domain.Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM task_links l
+		INNER JOIN tasks t ON t.id = l.source_task_id
+		WHERE l.target_task_id = $1 AND l.type = 'blocks' AND t.status <> 'closed' AND t.deleted_at IS NULL
+		ORDER BY t.created_at ASC
+	`
 
-	return tasks, total, nil
+	return r.queryTasks(ctx, query, id)
 }
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type taskViewRepository struct {
+	db *sql.DB
+}
+
+func NewTaskViewRepository(db *sql.DB) TaskViewRepository {
+	return &taskViewRepository{db: db}
+}
+
+func (r *taskViewRepository) Create(ctx context.Context, v *domain.TaskView) error {
+	query := `
+		INSERT INTO task_views (id, owner_id, name, query, shared_department, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, v.ID, v.OwnerID, v.Name, v.Query, v.SharedDepartment, v.CreatedAt, v.UpdatedAt)
+	if err != nil {
+		return errors.Internal(err, "Не удалось создать представление")
+	}
+
+	return nil
+}
+
+func (r *taskViewRepository) GetByID(ctx context.Context, id, viewerID uuid.UUID) (*domain.TaskView, error) {
+	query := `
+		SELECT v.id, v.owner_id, v.name, v.query, v.shared_department, d.view_id IS NOT NULL, v.created_at, v.updated_at
+		FROM task_views v
+		LEFT JOIN task_view_defaults d ON d.view_id = v.id AND d.employee_id = $2
+		WHERE v.id = $1
+	`
+
+	v := &domain.TaskView{}
+	err := r.db.QueryRowContext(ctx, query, id, viewerID).Scan(
+		&v.ID, &v.OwnerID, &v.Name, &v.Query, &v.SharedDepartment, &v.IsDefault, &v.CreatedAt, &v.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("Представление не найдено")
+	}
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить представление")
+	}
+
+	return v, nil
+}
+
+func (r *taskViewRepository) GetVisible(ctx context.Context, employeeID uuid.UUID, department string) ([]*domain.TaskView, error) {
+	query := `
+		SELECT v.id, v.owner_id, v.name, v.query, v.shared_department, d.view_id IS NOT NULL, v.created_at, v.updated_at
+		FROM task_views v
+		LEFT JOIN task_view_defaults d ON d.view_id = v.id AND d.employee_id = $1
+		WHERE v.owner_id = $1 OR v.shared_department = $2
+		ORDER BY v.owner_id = $1 DESC, v.name ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, employeeID, department)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить список представлений")
+	}
+	defer rows.Close()
+
+	views := []*domain.TaskView{}
+	for rows.Next() {
+		v := &domain.TaskView{}
+		err := rows.Scan(&v.ID, &v.OwnerID, &v.Name, &v.Query, &v.SharedDepartment, &v.IsDefault, &v.CreatedAt, &v.UpdatedAt)
+		if err != nil {
+			return nil, errors.Internal(err, "Не удалось обработать данные представления")
+		}
+		views = append(views, v)
+	}
+
+	return views, nil
+}
+
+func (r *taskViewRepository) GetDefault(ctx context.Context, employeeID uuid.UUID) (*domain.TaskView, error) {
+	query := `
+		SELECT v.id, v.owner_id, v.name, v.query, v.shared_department, TRUE, v.created_at, v.updated_at
+		FROM task_view_defaults d
+		JOIN task_views v ON v.id = d.view_id
+		WHERE d.employee_id = $1
+	`
+
+	v := &domain.TaskView{}
+	err := r.db.QueryRowContext(ctx, query, employeeID).Scan(
+		&v.ID, &v.OwnerID, &v.Name, &v.Query, &v.SharedDepartment, &v.IsDefault, &v.CreatedAt, &v.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("Представление по умолчанию не выбрано")
+	}
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось получить представление по умолчанию")
+	}
+
+	return v, nil
+}
+
+func (r *taskViewRepository) Update(ctx context.Context, v *domain.TaskView) error {
+	query := `
+		UPDATE task_views
+		SET name = $2, query = $3, shared_department = $4
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, v.ID, v.Name, v.Query, v.SharedDepartment).Scan(&v.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return errors.NotFound("Представление не найдено")
+	}
+	if err != nil {
+		return errors.Internal(err, "Не удалось обновить представление")
+	}
+
+	return nil
+}
+
+func (r *taskViewRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM task_views WHERE id = $1`, id)
+	if err != nil {
+		return errors.Internal(err, "Не удалось удалить представление")
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NotFound("Представление не найдено")
+	}
+
+	return nil
+}
+
+func (r *taskViewRepository) SetDefault(ctx context.Context, employeeID, viewID uuid.UUID) error {
+	query := `
+		INSERT INTO task_view_defaults (employee_id, view_id)
+		VALUES ($1, $2)
+		ON CONFLICT (employee_id) DO UPDATE SET view_id = EXCLUDED.view_id
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, employeeID, viewID); err != nil {
+		return errors.Internal(err, "Не удалось закрепить представление")
+	}
+
+	return nil
+}
+
+func (r *taskViewRepository) ClearDefault(ctx context.Context, employeeID uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM task_view_defaults WHERE employee_id = $1`, employeeID); err != nil {
+		return errors.Internal(err, "Не удалось открепить представление")
+	}
+
+	return nil
+}
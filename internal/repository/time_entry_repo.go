@@ -19,13 +19,23 @@ func NewTimeEntryRepository(db *sql.DB) TimeEntryRepository {
 }
 
 func (r *timeEntryRepository) Create(ctx context.Context, entry *domain.TimeEntry) error {
+	return r.CreateWithTx(ctx, nil, entry)
+}
+
+func (r *timeEntryRepository) CreateWithTx(ctx context.Context, tx *sql.Tx, entry *domain.TimeEntry) error {
 	query := `
 		INSERT INTO time_entries (id, task_id, employee_id, hours, description, entry_date, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
-	_, err := r.db.ExecContext(ctx, query, entry.ID, entry.TaskID, entry.EmployeeID,
-		entry.Hours, entry.Description, entry.EntryDate, entry.CreatedAt, entry.UpdatedAt)
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, entry.ID, entry.TaskID, entry.EmployeeID,
+			entry.Hours, entry.Description, entry.EntryDate, entry.CreatedAt, entry.UpdatedAt)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, entry.ID, entry.TaskID, entry.EmployeeID,
+			entry.Hours, entry.Description, entry.EntryDate, entry.CreatedAt, entry.UpdatedAt)
+	}
 
 	if err != nil {
 		return errors.Internal(err, "Не удалось создать запись времени")
@@ -191,3 +201,57 @@ func (r *timeEntryRepository) GetTaskTimeSummary(ctx context.Context, taskID uui
 
 	return summary, nil
 }
+
+func (r *timeEntryRepository) StreamTimesheet(ctx context.Context, filter TimesheetFilter, fn func(*domain.TimesheetRow) error) error {
+	query := `
+		SELECT e.id, e.name, e.department, t.id, t.key, t.title, te.entry_date, SUM(te.hours)
+		FROM time_entries te
+		INNER JOIN employees e ON e.id = te.employee_id
+		INNER JOIN tasks t ON t.id = te.task_id
+		WHERE te.deleted_at IS NULL AND te.entry_date >= $1 AND te.entry_date <= $2
+	`
+
+	args := []interface{}{filter.From, filter.To}
+	argPos := 3
+
+	if filter.EmployeeID != nil {
+		query += fmt.Sprintf(" AND te.employee_id = $%d", argPos)
+		args = append(args, *filter.EmployeeID)
+		argPos++
+	}
+
+	if filter.Department != nil {
+		query += fmt.Sprintf(" AND e.department = $%d", argPos)
+		args = append(args, *filter.Department)
+		argPos++
+	}
+
+	query += `
+		GROUP BY e.id, e.name, e.department, t.id, t.key, t.title, te.entry_date
+		ORDER BY e.department, e.name, e.id, t.key NULLS LAST, t.title, t.id, te.entry_date
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return errors.Internal(err, "Не удалось получить табель")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row := &domain.TimesheetRow{}
+		err := rows.Scan(&row.EmployeeID, &row.EmployeeName, &row.Department, &row.TaskID,
+			&row.TaskKey, &row.TaskTitle, &row.Date, &row.Hours)
+		if err != nil {
+			return errors.Internal(err, "Не удалось обработать строку табеля")
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.Internal(err, "Не удалось получить табель")
+	}
+
+	return nil
+}
@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type workspaceRepository struct {
+	db *sql.DB
+}
+
+func NewWorkspaceRepository(db *sql.DB) WorkspaceRepository {
+	return &workspaceRepository{db: db}
+}
+
+// workspaceTables - таблицы записей архива для проверки занятых идентификаторов
+var workspaceTables = map[domain.ArchiveRecordType]string{
+	domain.RecordEmployee:    "employees",
+	domain.RecordProject:     "projects",
+	domain.RecordTask:        "tasks",
+	domain.RecordParticipant: "task_participants",
+	domain.RecordMessage:     "task_messages",
+	domain.RecordTimeEntry:   "time_entries",
+}
+
+// stream выполняет запрос и передаёт строки в scan по одной
+func (r *workspaceRepository) stream(ctx context.Context, tx *sql.Tx, message, query string, scan func(rows *sql.Rows) error) error {
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return errors.Internal(err, message)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errors.Internal(err, message)
+	}
+	return nil
+}
+
+func (r *workspaceRepository) StreamEmployeesWithTx(ctx context.Context, tx *sql.Tx, withPasswordHashes bool, fn func(*domain.ArchivedEmployee) error) error {
+	const message = "Не удалось выгрузить сотрудников"
+	query := `
+		SELECT id, name, department, position, email, password_hash, created_at, updated_at, deleted_at
+		FROM employees
+		ORDER BY created_at, id
+	`
+
+	return r.stream(ctx, tx, message, query, func(rows *sql.Rows) error {
+		e := &domain.ArchivedEmployee{}
+		var passwordHash sql.NullString
+		if err := rows.Scan(&e.ID, &e.Name, &e.Department, &e.Position, &e.Email, &passwordHash,
+			&e.CreatedAt, &e.UpdatedAt, &e.DeletedAt); err != nil {
+			return errors.Internal(err, message)
+		}
+		if withPasswordHashes && passwordHash.Valid {
+			e.PasswordHash = &passwordHash.String
+		}
+		return fn(e)
+	})
+}
+
+func (r *workspaceRepository) StreamProjectsWithTx(ctx context.Context, tx *sql.Tx, fn func(*domain.ArchivedProject) error) error {
+	const message = "Не удалось выгрузить проекты"
+	query := `
+		SELECT p.id, p.key, p.name, COALESCE(p.description, ''), p.owner_id, p.task_counter,
+			ARRAY(SELECT m.employee_id FROM project_members m WHERE m.project_id = p.id ORDER BY m.created_at),
+			p.created_at, p.updated_at, p.deleted_at
+		FROM projects p
+		ORDER BY p.created_at, p.id
+	`
+
+	return r.stream(ctx, tx, message, query, func(rows *sql.Rows) error {
+		p := &domain.ArchivedProject{}
+		var members []string
+		if err := rows.Scan(&p.ID, &p.Key, &p.Name, &p.Description, &p.OwnerID, &p.TaskCounter,
+			pq.Array(&members), &p.CreatedAt, &p.UpdatedAt, &p.DeletedAt); err != nil {
+			return errors.Internal(err, message)
+		}
+		p.Members = make([]uuid.UUID, 0, len(members))
+		for _, member := range members {
+			id, err := uuid.Parse(member)
+			if err != nil {
+				return errors.Internal(err, message)
+			}
+			p.Members = append(p.Members, id)
+		}
+		return fn(p)
+	})
+}
+
+func (r *workspaceRepository) StreamTasksWithTx(ctx context.Context, tx *sql.Tx, fn func(*domain.ArchivedTask) error) error {
+	const message = "Не удалось выгрузить задачи"
+	query := `
+		WITH RECURSIVE tree AS (
+			SELECT id, 0 AS depth FROM tasks WHERE parent_id IS NULL
+			UNION ALL
+			SELECT t.id, tree.depth + 1 FROM tasks t JOIN tree ON t.parent_id = tree.id
+		)
+		SELECT t.id, t.project_id, t.key, t.parent_id, t.title, COALESCE(t.description, ''), t.status,
+			COALESCE(t.priority, 0), t.created_by, COALESCE(t.archived, FALSE), t.due_date, t.board_rank,
+			t.created_at, t.updated_at, t.deleted_at
+		FROM tasks t
+		JOIN tree ON tree.id = t.id
+		ORDER BY tree.depth, t.created_at, t.id
+	`
+
+	return r.stream(ctx, tx, message, query, func(rows *sql.Rows) error {
+		t := &domain.ArchivedTask{}
+		if err := rows.Scan(&t.ID, &t.ProjectID, &t.Key, &t.ParentID, &t.Title, &t.Description, &t.Status,
+			&t.Priority, &t.CreatedBy, &t.Archived, &t.DueDate, &t.BoardRank,
+			&t.CreatedAt, &t.UpdatedAt, &t.DeletedAt); err != nil {
+			return errors.Internal(err, message)
+		}
+		return fn(t)
+	})
+}
+
+func (r *workspaceRepository) StreamParticipantsWithTx(ctx context.Context, tx *sql.Tx, fn func(*domain.TaskParticipant) error) error {
+	const message = "Не удалось выгрузить участников задач"
+	query := `
+		SELECT id, task_id, employee_id, role, created_at
+		FROM task_participants
+		ORDER BY created_at, id
+	`
+
+	return r.stream(ctx, tx, message, query, func(rows *sql.Rows) error {
+		p := &domain.TaskParticipant{}
+		if err := rows.Scan(&p.ID, &p.TaskID, &p.EmployeeID, &p.Role, &p.CreatedAt); err != nil {
+			return errors.Internal(err, message)
+		}
+		return fn(p)
+	})
+}
+
+func (r *workspaceRepository) StreamMessagesWithTx(ctx context.Context, tx *sql.Tx, fn func(*domain.TaskMessage) error) error {
+	const message = "Не удалось выгрузить сообщения"
+	query := `
+		SELECT id, task_id, author_id, content, COALESCE(is_system_message, FALSE), created_at, updated_at, deleted_at
+		FROM task_messages
+		ORDER BY created_at, id
+	`
+
+	return r.stream(ctx, tx, message, query, func(rows *sql.Rows) error {
+		m := &domain.TaskMessage{}
+		if err := rows.Scan(&m.ID, &m.TaskID, &m.AuthorID, &m.Content, &m.IsSystemMessage,
+			&m.CreatedAt, &m.UpdatedAt, &m.DeletedAt); err != nil {
+			return errors.Internal(err, message)
+		}
+		return fn(m)
+	})
+}
+
+func (r *workspaceRepository) StreamTimeEntriesWithTx(ctx context.Context, tx *sql.Tx, fn func(*domain.TimeEntry) error) error {
+	const message = "Не удалось выгрузить записи времени"
+	query := `
+		SELECT id, task_id, employee_id, hours, COALESCE(description, ''), entry_date, created_at, updated_at, deleted_at
+		FROM time_entries
+		ORDER BY created_at, id
+	`
+
+	return r.stream(ctx, tx, message, query, func(rows *sql.Rows) error {
+		e := &domain.TimeEntry{}
+		if err := rows.Scan(&e.ID, &e.TaskID, &e.EmployeeID, &e.Hours, &e.Description, &e.EntryDate,
+			&e.CreatedAt, &e.UpdatedAt, &e.DeletedAt); err != nil {
+			return errors.Internal(err, message)
+		}
+		return fn(e)
+	})
+}
+
+func (r *workspaceRepository) ExistingIDsWithTx(ctx context.Context, tx *sql.Tx, recordType domain.ArchiveRecordType, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	existing := map[uuid.UUID]bool{}
+	table, ok := workspaceTables[recordType]
+	if !ok || len(ids) == 0 {
+		return existing, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM `+table+` WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось проверить существующие записи")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.Internal(err, "Не удалось проверить существующие записи")
+		}
+		existing[id] = true
+	}
+	return existing, rows.Err()
+}
+
+func (r *workspaceRepository) EmployeeIDsByEmailWithTx(ctx context.Context, tx *sql.Tx, emails []string) (map[string]uuid.UUID, error) {
+	existing := map[string]uuid.UUID{}
+	if len(emails) == 0 {
+		return existing, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT email, id FROM employees WHERE email = ANY($1)`, pq.Array(emails))
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось проверить адреса сотрудников")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var email string
+		var id uuid.UUID
+		if err := rows.Scan(&email, &id); err != nil {
+			return nil, errors.Internal(err, "Не удалось проверить адреса сотрудников")
+		}
+		existing[email] = id
+	}
+	return existing, rows.Err()
+}
+
+func (r *workspaceRepository) ExistingProjectKeysWithTx(ctx context.Context, tx *sql.Tx, keys []string) (map[string]bool, error) {
+	return r.existingKeys(ctx, tx, `SELECT key FROM projects WHERE key = ANY($1)`, keys)
+}
+
+func (r *workspaceRepository) ExistingTaskKeysWithTx(ctx context.Context, tx *sql.Tx, keys []string) (map[string]bool, error) {
+	return r.existingKeys(ctx, tx, `SELECT key FROM tasks WHERE key = ANY($1)`, keys)
+}
+
+func (r *workspaceRepository) existingKeys(ctx context.Context, tx *sql.Tx, query string, keys []string) (map[string]bool, error) {
+	existing := map[string]bool{}
+	if len(keys) == 0 {
+		return existing, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, query, pq.Array(keys))
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось проверить ключи")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, errors.Internal(err, "Не удалось проверить ключи")
+		}
+		existing[key] = true
+	}
+	return existing, rows.Err()
+}
+
+func (r *workspaceRepository) InsertEmployeeWithTx(ctx context.Context, tx *sql.Tx, e *domain.ArchivedEmployee) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO employees (id, name, department, position, email, password_hash, created_at, updated_at, deleted_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, e.ID, e.Name, e.Department, e.Position, e.Email, e.PasswordHash, e.CreatedAt, e.UpdatedAt, e.DeletedAt)
+	if err != nil {
+		return errors.Internal(err, "Не удалось загрузить сотрудника")
+	}
+	return nil
+}
+
+func (r *workspaceRepository) InsertProjectWithTx(ctx context.Context, tx *sql.Tx, p *domain.ArchivedProject) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO projects (id, key, name, description, owner_id, task_counter, created_at, updated_at, deleted_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, p.ID, p.Key, p.Name, p.Description, p.OwnerID, p.TaskCounter, p.CreatedAt, p.UpdatedAt, p.DeletedAt)
+	if err != nil {
+		return errors.Internal(err, "Не удалось загрузить проект")
+	}
+
+	if len(p.Members) > 0 {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO project_members (project_id, employee_id, created_at)
+			SELECT $1, member, $2 FROM unnest($3::uuid[]) AS member
+			ON CONFLICT DO NOTHING
+		`, p.ID, p.CreatedAt, pq.Array(p.Members))
+		if err != nil {
+			return errors.Internal(err, "Не удалось загрузить участников проекта")
+		}
+	}
+	return nil
+}
+
+func (r *workspaceRepository) InsertTaskWithTx(ctx context.Context, tx *sql.Tx, t *domain.ArchivedTask) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO tasks (id, project_id, key, parent_id, title, description, status, priority, created_by,
+			archived, due_date, board_rank, created_at, updated_at, deleted_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`, t.ID, t.ProjectID, t.Key, t.ParentID, t.Title, t.Description, t.Status, t.Priority, t.CreatedBy,
+		t.Archived, t.DueDate, t.BoardRank, t.CreatedAt, t.UpdatedAt, t.DeletedAt)
+	if err != nil {
+		return errors.Internal(err, "Не удалось загрузить задачу")
+	}
+	return nil
+}
+
+func (r *workspaceRepository) InsertParticipantWithTx(ctx context.Context, tx *sql.Tx, p *domain.TaskParticipant) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO task_participants (id, task_id, employee_id, role, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (task_id, employee_id, role) DO NOTHING
+	`, p.ID, p.TaskID, p.EmployeeID, p.Role, p.CreatedAt)
+	if err != nil {
+		return errors.Internal(err, "Не удалось загрузить участника задачи")
+	}
+	return nil
+}
+
+func (r *workspaceRepository) InsertMessageWithTx(ctx context.Context, tx *sql.Tx, m *domain.TaskMessage) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO task_messages (id, task_id, author_id, content, is_system_message, created_at, updated_at, deleted_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, m.ID, m.TaskID, m.AuthorID, m.Content, m.IsSystemMessage, m.CreatedAt, m.UpdatedAt, m.DeletedAt)
+	if err != nil {
+		return errors.Internal(err, "Не удалось загрузить сообщение")
+	}
+	return nil
+}
+
+func (r *workspaceRepository) InsertTimeEntryWithTx(ctx context.Context, tx *sql.Tx, e *domain.TimeEntry) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO time_entries (id, task_id, employee_id, hours, description, entry_date, created_at, updated_at, deleted_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, e.ID, e.TaskID, e.EmployeeID, e.Hours, e.Description, e.EntryDate, e.CreatedAt, e.UpdatedAt, e.DeletedAt)
+	if err != nil {
+		return errors.Internal(err, "Не удалось загрузить запись времени")
+	}
+	return nil
+}
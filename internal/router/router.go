@@ -4,34 +4,53 @@ import (
 	"net/http"
 
 	"github.com/dmitry/taskmanager/internal/handler"
+	"github.com/dmitry/taskmanager/internal/metrics"
 	"github.com/dmitry/taskmanager/internal/middleware"
 	"github.com/dmitry/taskmanager/internal/service"
+	"github.com/dmitry/taskmanager/internal/tracing"
 	"github.com/dmitry/taskmanager/pkg/logger"
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 )
 
 func NewRouter(
 	authHandler *handler.AuthHandler,
 	employeeHandler *handler.EmployeeHandler,
 	taskHandler *handler.TaskHandler,
+	messageHandler *handler.MessageHandler,
+	attachmentHandler *handler.AttachmentHandler,
+	searchHandler *handler.SearchHandler,
+	taskViewHandler *handler.TaskViewHandler,
+	projectHandler *handler.ProjectHandler,
+	taskLinkHandler *handler.TaskLinkHandler,
+	labelHandler *handler.LabelHandler,
+	customFieldHandler *handler.CustomFieldHandler,
+	sprintHandler *handler.SprintHandler,
+	recurrenceHandler *handler.RecurrenceHandler,
+	analyticsHandler *handler.AnalyticsHandler,
+	reportHandler *handler.ReportHandler,
+	healthHandler *handler.HealthHandler,
 	jwtService *service.JWTService,
 	frontendURL string,
+	logSampling *middleware.LogSampling,
 	logger *logger.Logger,
 ) http.Handler {
 	r := mux.NewRouter()
 
 	r.Use(middleware.RecoveryMiddleware(logger))
-	r.Use(middleware.LoggingMiddleware(logger))
+	r.Use(otelmux.Middleware(tracing.ServiceName))
+	r.Use(middleware.LoggingMiddleware(logger, logSampling))
 	r.Use(middleware.CORSMiddleware(frontendURL))
+	r.Use(middleware.MetricsMiddleware)
+
+	// Проверки работоспособности для оркестратора и балансировщика
+	r.HandleFunc("/healthz", healthHandler.Liveness).Methods("GET")
+	r.HandleFunc("/readyz", healthHandler.Readiness).Methods("GET")
 
 	api := r.PathPrefix("/api/v1").Subrouter()
 
 	// Публичные маршруты (аутентификация не требуется)
-	api.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"ok","service":"taskmanager"}`))
-	}).Methods("GET")
+	api.HandleFunc("/health", healthHandler.Liveness).Methods("GET")
 
 	// Маршруты аутентификации (аутентификация не требуется)
 	auth := api.PathPrefix("/auth").Subrouter()
@@ -40,6 +59,9 @@ func NewRouter(
 	auth.HandleFunc("/refresh", authHandler.RefreshToken).Methods("POST")
 	auth.HandleFunc("/logout", authHandler.Logout).Methods("POST")
 
+	// Скачивание вложений по подписанной ссылке (подпись проверяется в handler)
+	api.HandleFunc("/attachments/{id}/download", attachmentHandler.DownloadAttachment).Methods("GET")
+
 	// Защищенные маршруты (требуется JWT аутентификация)
 	protected := api.PathPrefix("").Subrouter()
 	protected.Use(middleware.AuthMiddleware(jwtService))
@@ -55,11 +77,108 @@ func NewRouter(
 	// Эндпоинты для работы с задачами
 	protected.HandleFunc("/tasks", taskHandler.CreateTask).Methods("POST")
 	protected.HandleFunc("/tasks", taskHandler.GetAllTasks).Methods("GET")
+	protected.HandleFunc("/board", taskHandler.GetBoard).Methods("GET")
+	protected.HandleFunc("/tasks/by-key/{key}", taskHandler.GetTaskByKey).Methods("GET")
 	protected.HandleFunc("/tasks/{id}", taskHandler.GetTask).Methods("GET")
 	protected.HandleFunc("/tasks/{id}/status", taskHandler.UpdateTaskStatus).Methods("PATCH")
+	protected.HandleFunc("/tasks/{id}/move", taskHandler.MoveTask).Methods("POST")
+	protected.HandleFunc("/tasks/{id}/transitions", taskHandler.GetStatusTransitions).Methods("GET")
 	protected.HandleFunc("/tasks/{id}/archive", taskHandler.ArchiveTask).Methods("PATCH")
+	protected.HandleFunc("/tasks/{id}/subtasks", taskHandler.GetSubtasks).Methods("GET")
+	protected.HandleFunc("/tasks/{id}/parent", taskHandler.SetParent).Methods("PUT")
 	protected.HandleFunc("/tasks/{id}/participants", taskHandler.GetTaskParticipants).Methods("GET")
 	protected.HandleFunc("/tasks/{id}/participants", taskHandler.AddParticipant).Methods("POST")
 
+	// Связи между задачами
+	protected.HandleFunc("/tasks/{id}/links", taskLinkHandler.GetTaskLinks).Methods("GET")
+	protected.HandleFunc("/tasks/{id}/links", taskLinkHandler.CreateLink).Methods("POST")
+	protected.HandleFunc("/links/{id}", taskLinkHandler.DeleteLink).Methods("DELETE")
+
+	// Метки задач
+	protected.HandleFunc("/labels", labelHandler.GetLabels).Methods("GET")
+	protected.HandleFunc("/labels", labelHandler.CreateLabel).Methods("POST")
+	protected.HandleFunc("/labels/{id}", labelHandler.UpdateLabel).Methods("PUT")
+	protected.HandleFunc("/labels/{id}", labelHandler.DeleteLabel).Methods("DELETE")
+	protected.HandleFunc("/tasks/{id}/labels", labelHandler.AddTaskLabel).Methods("POST")
+	protected.HandleFunc("/tasks/{id}/labels/{labelId}", labelHandler.RemoveTaskLabel).Methods("DELETE")
+
+	// Пользовательские поля задач
+	protected.HandleFunc("/fields", customFieldHandler.GetFields).Methods("GET")
+	protected.HandleFunc("/fields", customFieldHandler.CreateField).Methods("POST")
+	protected.HandleFunc("/fields/{id}", customFieldHandler.UpdateField).Methods("PUT")
+	protected.HandleFunc("/fields/{id}", customFieldHandler.DeleteField).Methods("DELETE")
+	protected.HandleFunc("/tasks/{id}/fields", customFieldHandler.SetTaskFields).Methods("PATCH")
+
+	// Эндпоинты для работы с проектами
+	protected.HandleFunc("/projects", projectHandler.CreateProject).Methods("POST")
+	protected.HandleFunc("/projects", projectHandler.GetProjects).Methods("GET")
+	protected.HandleFunc("/projects/{id}", projectHandler.GetProject).Methods("GET")
+	protected.HandleFunc("/projects/{id}", projectHandler.UpdateProject).Methods("PUT")
+	protected.HandleFunc("/projects/{id}", projectHandler.DeleteProject).Methods("DELETE")
+	protected.HandleFunc("/projects/{id}/tasks", projectHandler.GetProjectTasks).Methods("GET")
+	protected.HandleFunc("/projects/{id}/members", projectHandler.GetMembers).Methods("GET")
+	protected.HandleFunc("/projects/{id}/members", projectHandler.AddMember).Methods("POST")
+	protected.HandleFunc("/projects/{id}/members/{employeeId}", projectHandler.RemoveMember).Methods("DELETE")
+
+	// Спринты проектов
+	protected.HandleFunc("/projects/{id}/sprints", sprintHandler.GetSprints).Methods("GET")
+	protected.HandleFunc("/projects/{id}/sprints", sprintHandler.CreateSprint).Methods("POST")
+	protected.HandleFunc("/sprints/{id}", sprintHandler.GetSprint).Methods("GET")
+	protected.HandleFunc("/sprints/{id}", sprintHandler.UpdateSprint).Methods("PUT")
+	protected.HandleFunc("/sprints/{id}", sprintHandler.DeleteSprint).Methods("DELETE")
+	protected.HandleFunc("/sprints/{id}/start", sprintHandler.StartSprint).Methods("POST")
+	protected.HandleFunc("/sprints/{id}/close", sprintHandler.CloseSprint).Methods("POST")
+	protected.HandleFunc("/sprints/{id}/report", sprintHandler.GetReport).Methods("GET")
+	protected.HandleFunc("/sprints/{id}/tasks", sprintHandler.GetSprintTasks).Methods("GET")
+	protected.HandleFunc("/tasks/{id}/sprint", sprintHandler.SetTaskSprint).Methods("PUT")
+
+	// Повторяющиеся задачи
+	protected.HandleFunc("/tasks/{id}/recurrence", recurrenceHandler.GetRecurrence).Methods("GET")
+	protected.HandleFunc("/tasks/{id}/recurrence", recurrenceHandler.SetRecurrence).Methods("PUT")
+	protected.HandleFunc("/tasks/{id}/recurrence", recurrenceHandler.DeleteRecurrence).Methods("DELETE")
+
+	// Аналитика потока задач
+	protected.HandleFunc("/analytics/burndown", analyticsHandler.Burndown).Methods("GET")
+	protected.HandleFunc("/analytics/cfd", analyticsHandler.CumulativeFlow).Methods("GET")
+	protected.HandleFunc("/analytics/flow-times", analyticsHandler.FlowTimes).Methods("GET")
+
+	// Отчёты
+	protected.HandleFunc("/reports/timesheet", reportHandler.Timesheet).Methods("GET")
+
+	// Эндпоинты для работы с сообщениями задач
+	protected.HandleFunc("/tasks/{id}/messages", messageHandler.GetTaskMessages).Methods("GET")
+	protected.HandleFunc("/tasks/{id}/messages", messageHandler.CreateMessage).Methods("POST")
+	protected.HandleFunc("/messages/{id}", messageHandler.UpdateMessage).Methods("PUT")
+	protected.HandleFunc("/messages/{id}", messageHandler.DeleteMessage).Methods("DELETE")
+
+	// Эндпоинты для работы с вложениями
+	protected.HandleFunc("/tasks/{id}/attachments", attachmentHandler.GetTaskAttachments).Methods("GET")
+	protected.HandleFunc("/tasks/{id}/attachments", attachmentHandler.UploadAttachment).Methods("POST")
+	protected.HandleFunc("/attachments/{id}/link", attachmentHandler.GetDownloadLink).Methods("GET")
+	protected.HandleFunc("/attachments/{id}", attachmentHandler.DeleteAttachment).Methods("DELETE")
+
+	// Сохранённые представления списка задач (маршруты default регистрируются до {id})
+	protected.HandleFunc("/views", taskViewHandler.GetViews).Methods("GET")
+	protected.HandleFunc("/views", taskViewHandler.CreateView).Methods("POST")
+	protected.HandleFunc("/views/default", taskViewHandler.GetDefaultView).Methods("GET")
+	protected.HandleFunc("/views/default", taskViewHandler.ClearDefaultView).Methods("DELETE")
+	protected.HandleFunc("/views/default/tasks", taskViewHandler.GetDefaultViewTasks).Methods("GET")
+	protected.HandleFunc("/views/{id}", taskViewHandler.GetView).Methods("GET")
+	protected.HandleFunc("/views/{id}", taskViewHandler.UpdateView).Methods("PUT")
+	protected.HandleFunc("/views/{id}", taskViewHandler.DeleteView).Methods("DELETE")
+	protected.HandleFunc("/views/{id}/default", taskViewHandler.SetDefaultView).Methods("PUT")
+	protected.HandleFunc("/views/{id}/tasks", taskViewHandler.GetViewTasks).Methods("GET")
+
+	// Полнотекстовый поиск по задачам и сообщениям
+	protected.HandleFunc("/search", searchHandler.Search).Methods("GET")
+
+	return r
+}
+
+// NewMetricsRouter отдаёт метрики Prometheus. Он обслуживается отдельным сервером на
+// METRICS_ADDRESS, недоступном снаружи, и не проходит через middleware основного API.
+func NewMetricsRouter() http.Handler {
+	r := mux.NewRouter()
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
 	return r
 }
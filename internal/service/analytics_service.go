@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/pkg/errors"
+)
+
+const (
+	// maxAnalyticsDays - наибольшая длина периода отчёта в днях
+	maxAnalyticsDays = 366
+	// defaultAnalyticsDays - длина периода, если его начало не задано
+	defaultAnalyticsDays = 30
+)
+
+type AnalyticsService struct {
+	repo        repository.AnalyticsRepository
+	sprintRepo  repository.SprintRepository
+	projectRepo repository.ProjectRepository
+	fieldRepo   repository.CustomFieldRepository
+}
+
+func NewAnalyticsService(
+	repo repository.AnalyticsRepository,
+	sprintRepo repository.SprintRepository,
+	projectRepo repository.ProjectRepository,
+	fieldRepo repository.CustomFieldRepository,
+) *AnalyticsService {
+	return &AnalyticsService{
+		repo:        repo,
+		sprintRepo:  sprintRepo,
+		projectRepo: projectRepo,
+		fieldRepo:   fieldRepo,
+	}
+}
+
+// AnalyticsPeriod - период отчёта по датам включительно; nil - значение по умолчанию
+type AnalyticsPeriod struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// Burndown строит диаграмму сгорания задач, подходящих под фильтр. Если в фильтре задан
+// спринт, объём каждого дня - состав спринта на этот день (включая позже перенесённые задачи),
+// а период по умолчанию совпадает с датами спринта.
+func (s *AnalyticsService) Burndown(ctx context.Context, filter repository.TaskFilter, period AnalyticsPeriod) ([]*domain.BurndownPoint, error) {
+	if err := resolveCustomFields(ctx, s.fieldRepo, &filter); err != nil {
+		return nil, err
+	}
+
+	sprintID := filter.SprintID
+	var defaultFrom, defaultTo *time.Time
+	if sprintID != nil {
+		sprint, err := s.sprintRepo.GetByID(ctx, *sprintID)
+		if err != nil {
+			return nil, err
+		}
+		if filter.VisibleTo != nil {
+			member, err := s.projectRepo.IsMember(ctx, sprint.ProjectID, *filter.VisibleTo)
+			if err != nil {
+				return nil, err
+			}
+			if !member {
+				return nil, errors.NotFound("Спринт не найден")
+			}
+		}
+		defaultFrom, defaultTo = &sprint.StartDate, &sprint.EndDate
+		filter.SprintID = nil
+	}
+
+	from, to, err := resolvePeriod(period, defaultFrom, defaultTo)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := s.repo.Burndown(ctx, filter, sprintID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Идеальная линия - равномерное закрытие остатка первого дня к последнему дню
+	if len(points) > 0 {
+		start := float64(points[0].Remaining)
+		last := len(points) - 1
+		for i, p := range points {
+			if last == 0 {
+				p.Ideal = start
+				continue
+			}
+			p.Ideal = start * float64(last-i) / float64(last)
+		}
+	}
+
+	return points, nil
+}
+
+// CumulativeFlow возвращает число задач в каждом статусе по дням периода
+func (s *AnalyticsService) CumulativeFlow(ctx context.Context, filter repository.TaskFilter, period AnalyticsPeriod) ([]*domain.FlowPoint, error) {
+	if err := resolveCustomFields(ctx, s.fieldRepo, &filter); err != nil {
+		return nil, err
+	}
+
+	from, to, err := resolvePeriod(period, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.CumulativeFlow(ctx, filter, from, to)
+}
+
+// FlowTimes возвращает перцентили lead time и cycle time по командам для задач,
+// закрытых в периоде. Команда - отдел исполнителя задачи.
+func (s *AnalyticsService) FlowTimes(ctx context.Context, filter repository.TaskFilter, period AnalyticsPeriod) ([]*domain.FlowTimeStats, error) {
+	if err := resolveCustomFields(ctx, s.fieldRepo, &filter); err != nil {
+		return nil, err
+	}
+
+	from, to, err := resolvePeriod(period, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.FlowTimes(ctx, filter, from, to)
+}
+
+// resolvePeriod подставляет границы по умолчанию (defaultFrom/defaultTo или последние
+// defaultAnalyticsDays дней по сегодня) и проверяет длину периода
+func resolvePeriod(period AnalyticsPeriod, defaultFrom, defaultTo *time.Time) (time.Time, time.Time, error) {
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if defaultTo != nil {
+		to = *defaultTo
+	}
+	if period.To != nil {
+		to = *period.To
+	}
+
+	from := to.AddDate(0, 0, -(defaultAnalyticsDays - 1))
+	if defaultFrom != nil {
+		from = *defaultFrom
+	}
+	if period.From != nil {
+		from = *period.From
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, errors.BadRequest("Начало периода позже его окончания")
+	}
+	if to.Sub(from) >= maxAnalyticsDays*24*time.Hour {
+		return time.Time{}, time.Time{}, errors.BadRequest(fmt.Sprintf("Период отчёта не может быть длиннее %d дней", maxAnalyticsDays))
+	}
+
+	return from, to, nil
+}
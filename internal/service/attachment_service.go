@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/internal/storage"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/logger"
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/google/uuid"
+)
+
+// AttachmentOptions задает ограничения на загружаемые файлы и параметры ссылок на скачивание
+type AttachmentOptions struct {
+	MaxSizeBytes int64
+	AllowedTypes []string
+	LinkSecret   string
+	LinkTTL      time.Duration
+}
+
+type AttachmentService struct {
+	repo        repository.AttachmentRepository
+	access      *TaskAccess
+	messageRepo repository.MessageRepository
+	storage     storage.BlobStorage
+	opts        AttachmentOptions
+	db          *sql.DB
+}
+
+func NewAttachmentService(
+	repo repository.AttachmentRepository,
+	access *TaskAccess,
+	messageRepo repository.MessageRepository,
+	storage storage.BlobStorage,
+	opts AttachmentOptions,
+	db *sql.DB,
+) *AttachmentService {
+	return &AttachmentService{
+		repo:        repo,
+		access:      access,
+		messageRepo: messageRepo,
+		storage:     storage,
+		opts:        opts,
+		db:          db,
+	}
+}
+
+type UploadAttachmentRequest struct {
+	TaskID     uuid.UUID
+	MessageID  *uuid.UUID
+	UploadedBy uuid.UUID
+	FileName   string
+	Content    io.Reader
+}
+
+// MaxSizeBytes возвращает максимально допустимый размер вложения
+func (s *AttachmentService) MaxSizeBytes() int64 {
+	return s.opts.MaxSizeBytes
+}
+
+// Upload сохраняет вложение. Содержимое буферизуется во временный файл, чтобы
+// посчитать контрольную сумму и определить MIME-тип по сигнатуре, а не по имени файла.
+// Если объект с такой же контрольной суммой уже есть в хранилище, повторно он не загружается.
+func (s *AttachmentService) Upload(ctx context.Context, req UploadAttachmentRequest) (*domain.Attachment, error) {
+	if _, err := s.access.GetTask(ctx, req.TaskID, req.UploadedBy); err != nil {
+		return nil, err
+	}
+
+	if req.MessageID != nil {
+		message, err := s.messageRepo.GetByID(ctx, *req.MessageID)
+		if err != nil {
+			return nil, err
+		}
+		if message.TaskID != req.TaskID {
+			return nil, errors.BadRequest("Сообщение не относится к этой задаче")
+		}
+	}
+
+	fileName := sanitizeFileName(req.FileName)
+	if fileName == "" {
+		return nil, errors.BadRequest("Не указано имя файла")
+	}
+
+	tmp, err := os.CreateTemp("", "attachment-*")
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось создать временный файл")
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(req.Content, s.opts.MaxSizeBytes+1))
+	if err != nil {
+		return nil, errors.BadRequest("Не удалось прочитать загружаемый файл")
+	}
+	if size > s.opts.MaxSizeBytes {
+		return nil, errors.BadRequest(fmt.Sprintf("Размер файла превышает %d МБ", s.opts.MaxSizeBytes>>20))
+	}
+	if size == 0 {
+		return nil, errors.BadRequest("Файл пуст")
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.Internal(err, "Не удалось прочитать временный файл")
+	}
+	mtype, err := mimetype.DetectReader(tmp)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось определить тип файла")
+	}
+	if !mimetype.EqualsAny(mtype.String(), s.opts.AllowedTypes...) {
+		return nil, errors.BadRequest(fmt.Sprintf("Тип файла %s не разрешён", mtype.String()))
+	}
+
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	attachment := domain.NewAttachment(req.TaskID, req.MessageID, &req.UploadedBy, fileName, mtype.String(), size, checksum)
+
+	// Под блокировкой файла его не удалит одновременное удаление последнего вложения
+	// с тем же содержимым (releaseBlob), пока новое вложение не сохранено
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось начать транзакцию")
+	}
+	defer tx.Rollback()
+
+	if err := s.repo.LockBlobWithTx(ctx, tx, checksum); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.storage.Exists(ctx, attachment.StorageKey)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось проверить хранилище")
+	}
+	if !exists {
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return nil, errors.Internal(err, "Не удалось прочитать временный файл")
+		}
+		if err := s.storage.Put(ctx, attachment.StorageKey, tmp, size, attachment.ContentType); err != nil {
+			return nil, errors.Internal(err, "Не удалось сохранить файл")
+		}
+	}
+
+	if err := s.repo.CreateWithTx(ctx, tx, attachment); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Internal(err, "Не удалось зафиксировать транзакцию")
+	}
+
+	logger.FromContext(ctx).Info("Вложение загружено", "attachment_id", attachment.ID, "task_id", req.TaskID,
+		"size", size, "deduplicated", exists)
+
+	return attachment, nil
+}
+
+func (s *AttachmentService) GetTaskAttachments(ctx context.Context, taskID, employeeID uuid.UUID) ([]*domain.Attachment, error) {
+	if _, err := s.access.GetTask(ctx, taskID, employeeID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByTask(ctx, taskID)
+}
+
+// GetAttachment возвращает вложение, если сотруднику доступна его задача
+func (s *AttachmentService) GetAttachment(ctx context.Context, id, employeeID uuid.UUID) (*domain.Attachment, error) {
+	attachment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.access.GetTask(ctx, attachment.TaskID, employeeID); err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// Open возвращает метаданные вложения и поток его содержимого; поток нужно закрыть
+func (s *AttachmentService) Open(ctx context.Context, id uuid.UUID) (*domain.Attachment, io.ReadCloser, error) {
+	attachment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.storage.Get(ctx, attachment.StorageKey)
+	if err == storage.ErrNotFound {
+		return nil, nil, errors.NotFound("Файл вложения отсутствует в хранилище")
+	}
+	if err != nil {
+		return nil, nil, errors.Internal(err, "Не удалось прочитать файл вложения")
+	}
+
+	return attachment, content, nil
+}
+
+// DeleteAttachment удаляет вложение; удалить может загрузивший его сотрудник или автор задачи.
+// Объект в хранилище удаляется, когда на него больше не ссылается ни одно вложение.
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, id, employeeID uuid.UUID) error {
+	attachment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	task, err := s.access.GetTask(ctx, attachment.TaskID, employeeID)
+	if err != nil {
+		return err
+	}
+
+	isUploader := attachment.UploadedBy != nil && *attachment.UploadedBy == employeeID
+	if !isUploader && task.CreatedBy != employeeID {
+		return errors.Forbidden("Удалить вложение может только загрузивший его сотрудник или автор задачи")
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	if _, err := releaseBlob(ctx, s.db, s.repo, s.storage, attachment); err != nil {
+		logger.FromContext(ctx).Error("Не удалось удалить файл вложения из хранилища", "attachment_id", id, "error", err)
+	}
+
+	logger.FromContext(ctx).Info("Вложение удалено", "attachment_id", id, "task_id", attachment.TaskID)
+
+	return nil
+}
+
+// releaseBlob удаляет файл вложения из хранилища, если на него больше не ссылается ни одно
+// вложение, и сообщает, был ли файл удалён. Вызывается после фиксации удаления вложения:
+// проверка и удаление файла идут в отдельной транзакции под блокировкой файла, поэтому
+// одновременная загрузка того же содержимого либо уже сохранила вложение, либо дождётся
+// блокировки и загрузит файл заново.
+func releaseBlob(ctx context.Context, db *sql.DB, repo repository.AttachmentRepository, blobs storage.BlobStorage, attachment *domain.Attachment) (bool, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, errors.Internal(err, "Не удалось начать транзакцию")
+	}
+	defer tx.Rollback()
+
+	if err := repo.LockBlobWithTx(ctx, tx, attachment.Checksum); err != nil {
+		return false, err
+	}
+
+	remaining, err := repo.CountByChecksumWithTx(ctx, tx, attachment.Checksum)
+	if err != nil || remaining > 0 {
+		return false, err
+	}
+
+	if err := blobs.Delete(ctx, attachment.StorageKey); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// SignDownload создает подпись для ссылки на скачивание, действующей LinkTTL
+func (s *AttachmentService) SignDownload(id uuid.UUID) (string, time.Time) {
+	expiresAt := time.Now().Add(s.opts.LinkTTL).Truncate(time.Second)
+	return s.signature(id, expiresAt.Unix()), expiresAt
+}
+
+// VerifyDownload проверяет подпись и срок действия ссылки на скачивание
+func (s *AttachmentService) VerifyDownload(id uuid.UUID, expires, signature string) error {
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return errors.Unauthorized("Недействительная ссылка на скачивание")
+	}
+
+	expected := s.signature(id, expiresUnix)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.Unauthorized("Недействительная ссылка на скачивание")
+	}
+
+	if time.Now().Unix() > expiresUnix {
+		return errors.Unauthorized("Срок действия ссылки на скачивание истёк")
+	}
+
+	return nil
+}
+
+func (s *AttachmentService) signature(id uuid.UUID, expiresUnix int64) string {
+	mac := hmac.New(sha256.New, []byte(s.opts.LinkSecret))
+	fmt.Fprintf(mac, "attachment:%s:%d", id, expiresUnix)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sanitizeFileName оставляет только базовое имя файла без управляющих символов
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+
+	if name == "." || name == "/" {
+		return ""
+	}
+	if runes := []rune(name); len(runes) > 255 {
+		name = string(runes[len(runes)-255:])
+	}
+
+	return name
+}
@@ -5,7 +5,9 @@ import (
 	"time"
 
 	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/metrics"
 	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/internal/tracing"
 	"github.com/dmitry/taskmanager/pkg/errors"
 	"github.com/dmitry/taskmanager/pkg/logger"
 	"github.com/google/uuid"
@@ -16,20 +18,17 @@ type AuthService struct {
 	employeeRepo     repository.EmployeeRepository
 	refreshTokenRepo repository.RefreshTokenRepository
 	jwtService       *JWTService
-	logger           *logger.Logger
 }
 
 func NewAuthService(
 	employeeRepo repository.EmployeeRepository,
 	refreshTokenRepo repository.RefreshTokenRepository,
 	jwtService *JWTService,
-	logger *logger.Logger,
 ) *AuthService {
 	return &AuthService{
 		employeeRepo:     employeeRepo,
 		refreshTokenRepo: refreshTokenRepo,
 		jwtService:       jwtService,
-		logger:           logger,
 	}
 }
 
@@ -46,7 +45,7 @@ func (s *AuthService) Register(ctx context.Context, name, department, position,
 		return nil, errors.Conflict("Email уже зарегистрирован")
 	}
 
-	hashedPassword, err := s.hashPassword(password)
+	hashedPassword, err := s.hashPassword(ctx, password)
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +57,7 @@ func (s *AuthService) Register(ctx context.Context, name, department, position,
 		return nil, err
 	}
 
-	s.logger.Info("Сотрудник зарегистрирован", "employee_id", employee.ID, "email", email)
+	logger.FromContext(ctx).Info("Сотрудник зарегистрирован", "employee_id", employee.ID, "email", email)
 
 	return employee, nil
 }
@@ -67,14 +66,17 @@ func (s *AuthService) Register(ctx context.Context, name, department, position,
 func (s *AuthService) Login(ctx context.Context, email, password, userAgent, ipAddress string) (*AuthTokens, *domain.Employee, error) {
 	employee, err := s.employeeRepo.GetByEmail(ctx, email)
 	if err != nil {
+		metrics.LoginFailures.Inc()
 		return nil, nil, errors.Unauthorized("Неверный email или пароль")
 	}
 
 	if employee.PasswordHash == "" {
+		metrics.LoginFailures.Inc()
 		return nil, nil, errors.Unauthorized("Пароль не установлен для этой учётной записи")
 	}
 
-	if err := s.verifyPassword(employee.PasswordHash, password); err != nil {
+	if err := s.verifyPassword(ctx, employee.PasswordHash, password); err != nil {
+		metrics.LoginFailures.Inc()
 		return nil, nil, errors.Unauthorized("Неверный email или пароль")
 	}
 
@@ -83,7 +85,7 @@ func (s *AuthService) Login(ctx context.Context, email, password, userAgent, ipA
 		return nil, nil, err
 	}
 
-	s.logger.Info("Сотрудник вошёл в систему", "employee_id", employee.ID, "email", email)
+	logger.FromContext(ctx).Info("Сотрудник вошёл в систему", "employee_id", employee.ID, "email", email)
 
 	return tokens, employee, nil
 }
@@ -111,7 +113,7 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken, userAgent,
 	}
 
 	if err := s.refreshTokenRepo.RevokeByTokenHash(ctx, tokenHash); err != nil {
-		s.logger.Error("Не удалось отозвать старый refresh-токен", "error", err)
+		logger.FromContext(ctx).Error("Не удалось отозвать старый refresh-токен", "error", err)
 	}
 
 	tokens, err := s.generateTokens(ctx, employee, userAgent, ipAddress)
@@ -119,7 +121,7 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken, userAgent,
 		return nil, err
 	}
 
-	s.logger.Info("Токены обновлены", "employee_id", employee.ID)
+	logger.FromContext(ctx).Info("Токены обновлены", "employee_id", employee.ID)
 
 	return tokens, nil
 }
@@ -129,11 +131,11 @@ func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
 	tokenHash := s.jwtService.HashToken(refreshToken)
 
 	if err := s.refreshTokenRepo.RevokeByTokenHash(ctx, tokenHash); err != nil {
-		s.logger.Warn("Не удалось отозвать refresh-токен при выходе", "error", err)
+		logger.FromContext(ctx).Warn("Не удалось отозвать refresh-токен при выходе", "error", err)
 		return nil
 	}
 
-	s.logger.Info("Сотрудник вышел из системы")
+	logger.FromContext(ctx).Info("Сотрудник вышел из системы")
 
 	return nil
 }
@@ -144,7 +146,31 @@ func (s *AuthService) LogoutAll(ctx context.Context, employeeID uuid.UUID) error
 		return err
 	}
 
-	s.logger.Info("Все сессии отозваны", "employee_id", employeeID)
+	logger.FromContext(ctx).Info("Все сессии отозваны", "employee_id", employeeID)
+
+	return nil
+}
+
+// ResetPassword задаёт сотруднику новый пароль и отзывает все его сессии
+func (s *AuthService) ResetPassword(ctx context.Context, employeeID uuid.UUID, password string) error {
+	if len(password) < 8 || len(password) > 72 {
+		return errors.BadRequest("Пароль должен содержать от 8 до 72 символов")
+	}
+
+	hashedPassword, err := s.hashPassword(ctx, password)
+	if err != nil {
+		return err
+	}
+
+	if err := s.employeeRepo.UpdatePassword(ctx, employeeID, hashedPassword); err != nil {
+		return err
+	}
+
+	if err := s.refreshTokenRepo.RevokeAllByEmployee(ctx, employeeID); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("Пароль сотрудника сброшен", "employee_id", employeeID)
 
 	return nil
 }
@@ -175,8 +201,12 @@ func (s *AuthService) generateTokens(ctx context.Context, employee *domain.Emplo
 	}, nil
 }
 
-// hashPassword хеширует пароль используя bcrypt
-func (s *AuthService) hashPassword(password string) (string, error) {
+// hashPassword хеширует пароль используя bcrypt. Хеширование заметно по времени,
+// поэтому выделено в отдельный спан.
+func (s *AuthService) hashPassword(ctx context.Context, password string) (string, error) {
+	_, span := tracing.Start(ctx, "bcrypt.GenerateFromPassword")
+	defer span.End()
+
 	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return "", errors.Internal(err, "Не удалось хешировать пароль")
@@ -185,7 +215,10 @@ func (s *AuthService) hashPassword(password string) (string, error) {
 }
 
 // verifyPassword проверяет пароль на соответствие его хешу
-func (s *AuthService) verifyPassword(hashedPassword, password string) error {
+func (s *AuthService) verifyPassword(ctx context.Context, hashedPassword, password string) error {
+	_, span := tracing.Start(ctx, "bcrypt.CompareHashAndPassword")
+	defer span.End()
+
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 	if err != nil {
 		return errors.Unauthorized("Неверный пароль")
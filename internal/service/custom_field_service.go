@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/logger"
+	"github.com/google/uuid"
+)
+
+type CustomFieldService struct {
+	repo         repository.CustomFieldRepository
+	projectRepo  repository.ProjectRepository
+	employeeRepo repository.EmployeeRepository
+	access       *TaskAccess
+	messageRepo  repository.MessageRepository
+	db           *sql.DB
+}
+
+func NewCustomFieldService(
+	repo repository.CustomFieldRepository,
+	projectRepo repository.ProjectRepository,
+	employeeRepo repository.EmployeeRepository,
+	access *TaskAccess,
+	messageRepo repository.MessageRepository,
+	db *sql.DB,
+) *CustomFieldService {
+	return &CustomFieldService{
+		repo:         repo,
+		projectRepo:  projectRepo,
+		employeeRepo: employeeRepo,
+		access:       access,
+		messageRepo:  messageRepo,
+		db:           db,
+	}
+}
+
+type CreateCustomFieldRequest struct {
+	ProjectID *uuid.UUID
+	Key       string
+	Name      string
+	Type      domain.CustomFieldType
+	Options   []string
+	CreatedBy uuid.UUID
+}
+
+// CreateField создаёт поле. Общее поле может создать любой сотрудник,
+// поле проекта - только владелец проекта.
+func (s *CustomFieldService) CreateField(ctx context.Context, req CreateCustomFieldRequest) (*domain.CustomField, error) {
+	if !domain.IsValidCustomFieldKey(req.Key) {
+		return nil, errors.BadRequest("Ключ поля должен состоять из строчных латинских букв, цифр и подчёркиваний и начинаться с буквы")
+	}
+	if !req.Type.IsValid() {
+		return nil, errors.BadRequest("Неверный тип поля")
+	}
+
+	options, err := normalizeFieldOptions(req.Type, req.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ProjectID != nil {
+		if err := s.checkProjectOwner(ctx, *req.ProjectID, req.CreatedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	exists, err := s.repo.KeyExists(ctx, req.Key)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.Conflict("Ключ поля уже занят")
+	}
+
+	field := domain.NewCustomField(req.ProjectID, req.Key, strings.TrimSpace(req.Name), req.Type, options, req.CreatedBy)
+	if err := s.repo.Create(ctx, field); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Поле создано", "field_id", field.ID, "key", field.Key, "type", field.Type)
+
+	return field, nil
+}
+
+func (s *CustomFieldService) GetFields(ctx context.Context, employeeID uuid.UUID) ([]*domain.CustomField, error) {
+	return s.repo.GetForEmployee(ctx, employeeID)
+}
+
+// UpdateField изменяет название и варианты поля. Значения, уже сохранённые в задачах,
+// не меняются, даже если их вариант удалён.
+func (s *CustomFieldService) UpdateField(ctx context.Context, id, editorID uuid.UUID, name string, options []string) (*domain.CustomField, error) {
+	field, err := s.getEditableField(ctx, id, editorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if field.Options, err = normalizeFieldOptions(field.Type, options); err != nil {
+		return nil, err
+	}
+	field.Name = strings.TrimSpace(name)
+
+	if err := s.repo.Update(ctx, field); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Поле обновлено", "field_id", id)
+
+	return field, nil
+}
+
+// DeleteField удаляет поле вместе со значениями во всех задачах
+func (s *CustomFieldService) DeleteField(ctx context.Context, id, editorID uuid.UUID) error {
+	if _, err := s.getEditableField(ctx, id, editorID); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("Поле удалено", "field_id", id)
+
+	return nil
+}
+
+// SetTaskValues задаёт значения полей задачи по ключам; значение null удаляет поле из задачи.
+// Изменение фиксируется системным сообщением.
+func (s *CustomFieldService) SetTaskValues(ctx context.Context, taskID, employeeID uuid.UUID, values map[string]interface{}) (*domain.Task, error) {
+	task, err := s.access.GetTask(ctx, taskID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return task, nil
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fields, err := loadCustomFields(ctx, s.repo, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := make(map[string]interface{}, len(values))
+	for _, key := range keys {
+		field := fields[key]
+		if !field.AppliesTo(task) {
+			return nil, errors.BadRequest(fmt.Sprintf("Поле %s не относится к проекту задачи", key))
+		}
+		if values[key] == nil {
+			normalized[key] = nil
+			continue
+		}
+
+		value, err := field.NormalizeValue(values[key])
+		if err != nil {
+			return nil, errors.BadRequest(err.Error())
+		}
+		if items, ok := value.([]string); ok && len(items) == 0 {
+			value = nil
+		}
+		if field.Type == domain.CustomFieldEmployee {
+			if _, err := s.employeeRepo.GetByID(ctx, uuid.MustParse(value.(string))); err != nil {
+				return nil, err
+			}
+		}
+		normalized[key] = value
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось начать транзакцию")
+	}
+	defer tx.Rollback()
+
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		field := fields[key]
+		if normalized[key] == nil {
+			err = s.repo.DeleteValueWithTx(ctx, tx, taskID, field.ID)
+		} else {
+			err = s.repo.SetValueWithTx(ctx, tx, taskID, field.ID, normalized[key])
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, field.Name)
+	}
+
+	content := "Изменены поля: " + strings.Join(names, ", ")
+	if err := s.messageRepo.CreateWithTx(ctx, tx, domain.NewSystemMessage(taskID, content)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Internal(err, "Не удалось зафиксировать транзакцию")
+	}
+
+	logger.FromContext(ctx).Info("Поля задачи изменены", "task_id", taskID, "fields", keys)
+
+	return s.access.GetTask(ctx, taskID, employeeID)
+}
+
+// getEditableField возвращает поле, если сотрудник может его менять:
+// общее поле - его автор, поле проекта - владелец проекта
+func (s *CustomFieldService) getEditableField(ctx context.Context, id, editorID uuid.UUID) (*domain.CustomField, error) {
+	field, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if field.ProjectID != nil {
+		if err := s.checkProjectOwner(ctx, *field.ProjectID, editorID); err != nil {
+			return nil, err
+		}
+		return field, nil
+	}
+
+	if field.CreatedBy == nil || *field.CreatedBy != editorID {
+		return nil, errors.Forbidden("Изменить общее поле может только его автор")
+	}
+
+	return field, nil
+}
+
+func (s *CustomFieldService) checkProjectOwner(ctx context.Context, projectID, employeeID uuid.UUID) error {
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.projectRepo.IsMember(ctx, projectID, employeeID)
+	if err != nil {
+		return err
+	}
+	if !member {
+		return errors.NotFound("Проект не найден")
+	}
+
+	if project.OwnerID != employeeID {
+		return errors.Forbidden("Управлять полями проекта может только его владелец")
+	}
+
+	return nil
+}
+
+// normalizeFieldOptions проверяет варианты выбора: они обязательны для полей выбора,
+// не должны повторяться и запрещены для остальных типов
+func normalizeFieldOptions(fieldType domain.CustomFieldType, options []string) ([]string, error) {
+	if !fieldType.HasOptions() {
+		if len(options) > 0 {
+			return nil, errors.BadRequest("Варианты задаются только для полей выбора")
+		}
+		return []string{}, nil
+	}
+
+	result := []string{}
+	seen := map[string]bool{}
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return nil, errors.BadRequest("Вариант поля не может быть пустым")
+		}
+		if seen[option] {
+			return nil, errors.BadRequest("Варианты поля повторяются: " + option)
+		}
+		seen[option] = true
+		result = append(result, option)
+	}
+
+	if len(result) == 0 {
+		return nil, errors.BadRequest("Для поля выбора нужен хотя бы один вариант")
+	}
+
+	return result, nil
+}
+
+// loadCustomFields возвращает определения полей по ключам; неизвестный ключ - ошибка запроса
+func loadCustomFields(ctx context.Context, repo repository.CustomFieldRepository, keys []string) (map[string]*domain.CustomField, error) {
+	fields, err := repo.GetByKeys(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]*domain.CustomField, len(fields))
+	for _, field := range fields {
+		byKey[field.Key] = field
+	}
+
+	for _, key := range keys {
+		if byKey[key] == nil {
+			return nil, errors.BadRequest("Неизвестное поле: " + key)
+		}
+	}
+
+	return byKey, nil
+}
+
+// resolveCustomFields загружает определения полей, упомянутых в условиях и сортировке фильтра,
+// и приводит значения условий к виду, в котором они хранятся
+func resolveCustomFields(ctx context.Context, repo repository.CustomFieldRepository, filter *repository.TaskFilter) error {
+	keys := []string{}
+	for _, condition := range filter.CustomFields {
+		keys = append(keys, condition.Key)
+	}
+	for _, field := range filter.Sort {
+		if strings.HasPrefix(field.Field, repository.CustomFieldPrefix) {
+			keys = append(keys, strings.TrimPrefix(field.Field, repository.CustomFieldPrefix))
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	fields, err := loadCustomFields(ctx, repo, keys)
+	if err != nil {
+		return err
+	}
+
+	conditions := make([]repository.CustomFieldFilter, len(filter.CustomFields))
+	for i, condition := range filter.CustomFields {
+		field := fields[condition.Key]
+		if (condition.Min != "" || condition.Max != "") && !field.Type.IsOrdered() {
+			return errors.BadRequest(fmt.Sprintf("Поле %s не поддерживает фильтр по диапазону", field.Key))
+		}
+
+		resolved := repository.CustomFieldFilter{Key: condition.Key}
+		for _, value := range condition.Values {
+			normalized, err := customFilterValue(field, value)
+			if err != nil {
+				return err
+			}
+			resolved.Values = append(resolved.Values, normalized)
+		}
+		if resolved.Min, err = customFilterValue(field, condition.Min); err != nil {
+			return err
+		}
+		if resolved.Max, err = customFilterValue(field, condition.Max); err != nil {
+			return err
+		}
+		conditions[i] = resolved
+	}
+
+	filter.CustomFields = conditions
+	filter.CustomFieldDefs = fields
+
+	return nil
+}
+
+func customFilterValue(field *domain.CustomField, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	parsed, err := field.ParseValue(value)
+	if err != nil {
+		return "", errors.BadRequest(err.Error())
+	}
+
+	if number, ok := parsed.(float64); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	}
+	return parsed.(string), nil
+}
@@ -10,14 +10,12 @@ import (
 )
 
 type EmployeeService struct {
-	repo   repository.EmployeeRepository
-	logger *logger.Logger
+	repo repository.EmployeeRepository
 }
 
-func NewEmployeeService(repo repository.EmployeeRepository, logger *logger.Logger) *EmployeeService {
+func NewEmployeeService(repo repository.EmployeeRepository) *EmployeeService {
 	return &EmployeeService{
-		repo:   repo,
-		logger: logger,
+		repo: repo,
 	}
 }
 
@@ -28,7 +26,7 @@ func (s *EmployeeService) CreateEmployee(ctx context.Context, name, department,
 		return nil, err
 	}
 
-	s.logger.Info("Сотрудник создан", "employee_id", employee.ID, "email", email)
+	logger.FromContext(ctx).Info("Сотрудник создан", "employee_id", employee.ID, "email", email)
 
 	return employee, nil
 }
@@ -41,6 +39,14 @@ func (s *EmployeeService) GetAllEmployees(ctx context.Context, filter repository
 	return s.repo.GetAll(ctx, filter)
 }
 
+func (s *EmployeeService) GetAllEmployeesByCursor(ctx context.Context, filter repository.EmployeeFilter) ([]*domain.Employee, string, error) {
+	return s.repo.GetAllByCursor(ctx, filter)
+}
+
+func (s *EmployeeService) CountEmployees(ctx context.Context, filter repository.EmployeeFilter) (int, error) {
+	return s.repo.Count(ctx, filter)
+}
+
 func (s *EmployeeService) UpdateEmployee(ctx context.Context, employee *domain.Employee) error {
 	return s.repo.Update(ctx, employee)
 }
@@ -50,7 +56,7 @@ func (s *EmployeeService) DeleteEmployee(ctx context.Context, id uuid.UUID) erro
 		return err
 	}
 
-	s.logger.Info("Сотрудник удалён", "employee_id", id)
+	logger.FromContext(ctx).Info("Сотрудник удалён", "employee_id", id)
 
 	return nil
 }
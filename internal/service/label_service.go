@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// defaultLabelColor - цвет метки, если он не указан
+const defaultLabelColor = "#808080"
+
+type LabelService struct {
+	repo        repository.LabelRepository
+	access      *TaskAccess
+	messageRepo repository.MessageRepository
+	db          *sql.DB
+}
+
+func NewLabelService(
+	repo repository.LabelRepository,
+	access *TaskAccess,
+	messageRepo repository.MessageRepository,
+	db *sql.DB,
+) *LabelService {
+	return &LabelService{
+		repo:        repo,
+		access:      access,
+		messageRepo: messageRepo,
+		db:          db,
+	}
+}
+
+type SaveLabelRequest struct {
+	Name  string
+	Color string
+}
+
+func (s *LabelService) CreateLabel(ctx context.Context, req SaveLabelRequest) (*domain.Label, error) {
+	name, color, err := s.validateLabel(ctx, uuid.Nil, req)
+	if err != nil {
+		return nil, err
+	}
+
+	label := domain.NewLabel(name, color)
+	if err := s.repo.Create(ctx, label); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Метка создана", "label_id", label.ID, "name", label.Name)
+
+	return label, nil
+}
+
+func (s *LabelService) GetLabels(ctx context.Context) ([]*domain.Label, error) {
+	return s.repo.GetAll(ctx)
+}
+
+func (s *LabelService) UpdateLabel(ctx context.Context, id uuid.UUID, req SaveLabelRequest) (*domain.Label, error) {
+	label, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Color == "" {
+		req.Color = label.Color
+	}
+	name, color, err := s.validateLabel(ctx, id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	label.Name = name
+	label.Color = color
+	if err := s.repo.Update(ctx, label); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Метка обновлена", "label_id", id)
+
+	return label, nil
+}
+
+// DeleteLabel удаляет метку и оставляет системное сообщение в каждой задаче, с которой она снята
+func (s *LabelService) DeleteLabel(ctx context.Context, id uuid.UUID) error {
+	label, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Internal(err, "Не удалось начать транзакцию")
+	}
+	defer tx.Rollback()
+
+	taskIDs, err := s.repo.DeleteWithTx(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	content := fmt.Sprintf("Удалена метка '%s'", label.Name)
+	for _, taskID := range taskIDs {
+		if err := s.messageRepo.CreateWithTx(ctx, tx, domain.NewSystemMessage(taskID, content)); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Internal(err, "Не удалось зафиксировать транзакцию")
+	}
+
+	logger.FromContext(ctx).Info("Метка удалена", "label_id", id, "tasks", len(taskIDs))
+
+	return nil
+}
+
+// AddLabelToTask ставит метку на задачу; повторная установка ничего не меняет
+func (s *LabelService) AddLabelToTask(ctx context.Context, taskID, labelID, employeeID uuid.UUID) error {
+	return s.changeTaskLabel(ctx, taskID, labelID, employeeID, true)
+}
+
+// RemoveLabelFromTask снимает метку с задачи
+func (s *LabelService) RemoveLabelFromTask(ctx context.Context, taskID, labelID, employeeID uuid.UUID) error {
+	return s.changeTaskLabel(ctx, taskID, labelID, employeeID, false)
+}
+
+func (s *LabelService) changeTaskLabel(ctx context.Context, taskID, labelID, employeeID uuid.UUID, add bool) error {
+	if _, err := s.access.GetTask(ctx, taskID, employeeID); err != nil {
+		return err
+	}
+
+	label, err := s.repo.GetByID(ctx, labelID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Internal(err, "Не удалось начать транзакцию")
+	}
+	defer tx.Rollback()
+
+	var changed bool
+	var content string
+	if add {
+		changed, err = s.repo.AddToTaskWithTx(ctx, tx, taskID, labelID)
+		content = fmt.Sprintf("Добавлена метка '%s'", label.Name)
+	} else {
+		changed, err = s.repo.RemoveFromTaskWithTx(ctx, tx, taskID, labelID)
+		content = fmt.Sprintf("Удалена метка '%s'", label.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if !changed {
+		if add {
+			return nil
+		}
+		return errors.NotFound("Метка не установлена на задаче")
+	}
+
+	if err := s.messageRepo.CreateWithTx(ctx, tx, domain.NewSystemMessage(taskID, content)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Internal(err, "Не удалось зафиксировать транзакцию")
+	}
+
+	logger.FromContext(ctx).Info("Метки задачи изменены", "task_id", taskID, "label_id", labelID, "added", add)
+
+	return nil
+}
+
+// validateLabel проверяет имя и цвет метки; имя должно быть уникальным без учёта регистра
+// среди остальных меток (exceptID - изменяемая метка)
+func (s *LabelService) validateLabel(ctx context.Context, exceptID uuid.UUID, req SaveLabelRequest) (string, string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return "", "", errors.BadRequest("Не указано имя метки")
+	}
+
+	color := defaultLabelColor
+	if req.Color != "" {
+		var ok bool
+		if color, ok = domain.NormalizeLabelColor(req.Color); !ok {
+			return "", "", errors.BadRequest("Цвет метки должен быть в формате #rrggbb")
+		}
+	}
+
+	exists, err := s.repo.NameExists(ctx, name, exceptID)
+	if err != nil {
+		return "", "", err
+	}
+	if exists {
+		return "", "", errors.Conflict("Метка с таким именем уже существует")
+	}
+
+	return name, color, nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/internal/storage"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/logger"
+)
+
+// MaintenanceService выполняет административные операции над данными
+type MaintenanceService struct {
+	repo           repository.MaintenanceRepository
+	attachmentRepo repository.AttachmentRepository
+	storage        storage.BlobStorage
+	db             *sql.DB
+}
+
+func NewMaintenanceService(
+	repo repository.MaintenanceRepository,
+	attachmentRepo repository.AttachmentRepository,
+	storage storage.BlobStorage,
+	db *sql.DB,
+) *MaintenanceService {
+	return &MaintenanceService{
+		repo:           repo,
+		attachmentRepo: attachmentRepo,
+		storage:        storage,
+		db:             db,
+	}
+}
+
+// PurgeDeleted безвозвратно удаляет данные, мягко удалённые раньше before. При dryRun удаление
+// выполняется в транзакции, которая откатывается, и возвращается только число строк.
+// Файлы вложений удаляются из хранилища после фиксации, если на них больше не ссылается
+// ни одно вложение (см. releaseBlob); возвращается число удалённых файлов.
+func (s *MaintenanceService) PurgeDeleted(ctx context.Context, before time.Time, dryRun bool) ([]domain.PurgeCount, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, errors.Internal(err, "Не удалось начать транзакцию")
+	}
+	defer tx.Rollback()
+
+	counts, attachments, err := s.repo.PurgeDeletedWithTx(ctx, tx, before)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if dryRun {
+		return counts, 0, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, errors.Internal(err, "Не удалось зафиксировать транзакцию")
+	}
+
+	logger.FromContext(ctx).Info("Удалённые данные очищены", "before", before, "counts", counts)
+
+	files := 0
+	checked := map[string]bool{}
+	for _, a := range attachments {
+		if checked[a.Checksum] {
+			continue
+		}
+		checked[a.Checksum] = true
+
+		deleted, err := releaseBlob(ctx, s.db, s.attachmentRepo, s.storage, a)
+		if err != nil {
+			logger.FromContext(ctx).Error("Не удалось удалить файл вложения из хранилища", "attachment_id", a.ID, "error", err)
+			continue
+		}
+		if !deleted {
+			continue
+		}
+		files++
+	}
+
+	return counts, files, nil
+}
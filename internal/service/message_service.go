@@ -5,49 +5,89 @@ import (
 
 	"github.com/dmitry/taskmanager/internal/domain"
 	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/pkg/errors"
 	"github.com/dmitry/taskmanager/pkg/logger"
 	"github.com/google/uuid"
 )
 
 type MessageService struct {
 	repo   repository.MessageRepository
-	logger *logger.Logger
+	access *TaskAccess
 }
 
-func NewMessageService(repo repository.MessageRepository, logger *logger.Logger) *MessageService {
+func NewMessageService(repo repository.MessageRepository, access *TaskAccess) *MessageService {
 	return &MessageService{
 		repo:   repo,
-		logger: logger,
+		access: access,
 	}
 }
 
 func (s *MessageService) CreateMessage(ctx context.Context, taskID, authorID uuid.UUID, content string) (*domain.TaskMessage, error) {
+	if _, err := s.access.GetTask(ctx, taskID, authorID); err != nil {
+		return nil, err
+	}
+
 	message := domain.NewTaskMessage(taskID, &authorID, content, false)
 
 	if err := s.repo.Create(ctx, message); err != nil {
 		return nil, err
 	}
 
-	s.logger.Info("Сообщение создано", "message_id", message.ID, "task_id", taskID)
+	logger.FromContext(ctx).Info("Сообщение создано", "message_id", message.ID, "task_id", taskID)
 
 	return message, nil
 }
 
-func (s *MessageService) GetTaskMessages(ctx context.Context, taskID uuid.UUID) ([]*domain.TaskMessage, error) {
+func (s *MessageService) GetTaskMessages(ctx context.Context, taskID, employeeID uuid.UUID) ([]*domain.TaskMessage, error) {
+	if _, err := s.access.GetTask(ctx, taskID, employeeID); err != nil {
+		return nil, err
+	}
+
 	return s.repo.GetByTask(ctx, taskID)
 }
 
-func (s *MessageService) UpdateMessage(ctx context.Context, messageID uuid.UUID, content string) error {
+func (s *MessageService) GetMessage(ctx context.Context, id uuid.UUID) (*domain.TaskMessage, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// UpdateMessage изменяет текст сообщения; редактировать может только автор
+func (s *MessageService) UpdateMessage(ctx context.Context, messageID, editorID uuid.UUID, content string) (*domain.TaskMessage, error) {
 	message, err := s.repo.GetByID(ctx, messageID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if message.AuthorID == nil || *message.AuthorID != editorID {
+		return nil, errors.Forbidden("Редактировать сообщение может только его автор")
+	}
+
+	if _, err := s.access.GetTask(ctx, message.TaskID, editorID); err != nil {
+		return nil, err
 	}
 
 	message.Content = content
 
-	return s.repo.Update(ctx, message)
+	if err := s.repo.Update(ctx, message); err != nil {
+		return nil, err
+	}
+
+	return message, nil
 }
 
-func (s *MessageService) DeleteMessage(ctx context.Context, id uuid.UUID) error {
+// DeleteMessage удаляет сообщение; удалить может только автор
+func (s *MessageService) DeleteMessage(ctx context.Context, id, editorID uuid.UUID) error {
+	message, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if message.AuthorID == nil || *message.AuthorID != editorID {
+		return errors.Forbidden("Удалить сообщение может только его автор")
+	}
+
+	if _, err := s.access.GetTask(ctx, message.TaskID, editorID); err != nil {
+		return err
+	}
+
 	return s.repo.Delete(ctx, id)
 }
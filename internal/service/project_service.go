@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/logger"
+	"github.com/google/uuid"
+)
+
+type ProjectService struct {
+	repo         repository.ProjectRepository
+	taskRepo     repository.TaskRepository
+	employeeRepo repository.EmployeeRepository
+}
+
+func NewProjectService(
+	repo repository.ProjectRepository,
+	taskRepo repository.TaskRepository,
+	employeeRepo repository.EmployeeRepository,
+) *ProjectService {
+	return &ProjectService{
+		repo:         repo,
+		taskRepo:     taskRepo,
+		employeeRepo: employeeRepo,
+	}
+}
+
+func (s *ProjectService) CreateProject(ctx context.Context, key, name, description string, ownerID uuid.UUID) (*domain.Project, error) {
+	if !domain.IsValidProjectKey(key) {
+		return nil, errors.BadRequest("Ключ проекта должен состоять из 2-10 заглавных латинских букв и цифр и начинаться с буквы")
+	}
+
+	exists, err := s.repo.KeyExists(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.Conflict("Ключ проекта уже занят")
+	}
+
+	project := domain.NewProject(key, name, description, ownerID)
+
+	if err := s.repo.Create(ctx, project); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Проект создан", "project_id", project.ID, "key", key, "owner_id", ownerID)
+
+	return project, nil
+}
+
+// GetProject возвращает проект, если сотрудник состоит в нём
+func (s *ProjectService) GetProject(ctx context.Context, id, employeeID uuid.UUID) (*domain.Project, error) {
+	project, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.repo.IsMember(ctx, id, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, errors.NotFound("Проект не найден")
+	}
+
+	return project, nil
+}
+
+func (s *ProjectService) GetProjects(ctx context.Context, employeeID uuid.UUID) ([]*domain.Project, error) {
+	return s.repo.GetForEmployee(ctx, employeeID)
+}
+
+// UpdateProject изменяет название и описание; ключ проекта неизменен, так как входит в ключи задач
+func (s *ProjectService) UpdateProject(ctx context.Context, id, editorID uuid.UUID, name, description string) (*domain.Project, error) {
+	project, err := s.getOwnedProject(ctx, id, editorID)
+	if err != nil {
+		return nil, err
+	}
+
+	project.Name = name
+	project.Description = description
+
+	if err := s.repo.Update(ctx, project); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// DeleteProject удаляет пустой проект; задачи проекта нужно предварительно удалить
+func (s *ProjectService) DeleteProject(ctx context.Context, id, editorID uuid.UUID) error {
+	if _, err := s.getOwnedProject(ctx, id, editorID); err != nil {
+		return err
+	}
+
+	count, err := s.taskRepo.Count(ctx, repository.TaskFilter{ProjectID: &id})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.Conflict("Нельзя удалить проект, в котором есть задачи")
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("Проект удалён", "project_id", id)
+
+	return nil
+}
+
+func (s *ProjectService) GetMembers(ctx context.Context, id, employeeID uuid.UUID) ([]*domain.ProjectMember, error) {
+	if _, err := s.GetProject(ctx, id, employeeID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetMembers(ctx, id)
+}
+
+// AddMember добавляет сотрудника в проект; управлять участниками может только владелец
+func (s *ProjectService) AddMember(ctx context.Context, id, editorID, employeeID uuid.UUID) error {
+	if _, err := s.getOwnedProject(ctx, id, editorID); err != nil {
+		return err
+	}
+
+	if _, err := s.employeeRepo.GetByID(ctx, employeeID); err != nil {
+		return errors.BadRequest("Сотрудник не найден")
+	}
+
+	if err := s.repo.AddMember(ctx, domain.NewProjectMember(id, employeeID)); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("Участник добавлен в проект", "project_id", id, "employee_id", employeeID)
+
+	return nil
+}
+
+func (s *ProjectService) RemoveMember(ctx context.Context, id, editorID, employeeID uuid.UUID) error {
+	project, err := s.getOwnedProject(ctx, id, editorID)
+	if err != nil {
+		return err
+	}
+
+	if project.OwnerID == employeeID {
+		return errors.BadRequest("Нельзя исключить владельца из проекта")
+	}
+
+	if err := s.repo.RemoveMember(ctx, id, employeeID); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("Участник исключён из проекта", "project_id", id, "employee_id", employeeID)
+
+	return nil
+}
+
+func (s *ProjectService) getOwnedProject(ctx context.Context, id, editorID uuid.UUID) (*domain.Project, error) {
+	project, err := s.GetProject(ctx, id, editorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if project.OwnerID != editorID {
+		return nil, errors.Forbidden("Изменять проект может только его владелец")
+	}
+
+	return project, nil
+}
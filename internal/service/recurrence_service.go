@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/metrics"
+	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// RecurrenceService управляет расписаниями повторения задач и создаёт наступившие повторения
+type RecurrenceService struct {
+	repo            repository.RecurrenceRepository
+	taskRepo        repository.TaskRepository
+	participantRepo repository.TaskParticipantRepository
+	projectRepo     repository.ProjectRepository
+	transitionRepo  repository.StatusTransitionRepository
+	messageRepo     repository.MessageRepository
+	access          *TaskAccess
+	db              *sql.DB
+}
+
+func NewRecurrenceService(
+	repo repository.RecurrenceRepository,
+	taskRepo repository.TaskRepository,
+	participantRepo repository.TaskParticipantRepository,
+	projectRepo repository.ProjectRepository,
+	transitionRepo repository.StatusTransitionRepository,
+	messageRepo repository.MessageRepository,
+	access *TaskAccess,
+	db *sql.DB,
+) *RecurrenceService {
+	return &RecurrenceService{
+		repo:            repo,
+		taskRepo:        taskRepo,
+		participantRepo: participantRepo,
+		projectRepo:     projectRepo,
+		transitionRepo:  transitionRepo,
+		messageRepo:     messageRepo,
+		access:          access,
+		db:              db,
+	}
+}
+
+// SetRecurrenceRequest - правило повторения; StartsAt == nil - с текущего момента,
+// пустой Timezone - UTC
+type SetRecurrenceRequest struct {
+	Rule     string
+	StartsAt *time.Time
+	Timezone string
+}
+
+// SetRecurrence делает задачу шаблоном с указанным расписанием или заменяет расписание
+func (s *RecurrenceService) SetRecurrence(ctx context.Context, taskID, employeeID uuid.UUID, req SetRecurrenceRequest) (*domain.TaskRecurrence, error) {
+	if _, err := s.access.GetTask(ctx, taskID, employeeID); err != nil {
+		return nil, err
+	}
+
+	startsAt := time.Now()
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+
+	recurrence, err := domain.NewTaskRecurrence(taskID, employeeID, req.Rule, startsAt, timezone)
+	if err != nil {
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if err := s.repo.Upsert(ctx, recurrence); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Расписание повторения задачи сохранено",
+		"task_id", taskID, "rule", recurrence.Rule, "next_run_at", recurrence.NextRunAt)
+
+	return recurrence, nil
+}
+
+func (s *RecurrenceService) GetRecurrence(ctx context.Context, taskID, employeeID uuid.UUID) (*domain.TaskRecurrence, error) {
+	if _, err := s.access.GetTask(ctx, taskID, employeeID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByTemplate(ctx, taskID)
+}
+
+// DeleteRecurrence отменяет расписание; созданные по нему задачи остаются
+func (s *RecurrenceService) DeleteRecurrence(ctx context.Context, taskID, employeeID uuid.UUID) error {
+	if _, err := s.access.GetTask(ctx, taskID, employeeID); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteByTemplate(ctx, taskID); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("Расписание повторения задачи удалено", "task_id", taskID)
+
+	return nil
+}
+
+// RunDue создаёт задачи по всем расписаниям, наступившим к моменту now, и возвращает
+// число созданных задач. Каждое расписание обрабатывается в своей транзакции под
+// блокировкой строки, поэтому RunDue можно одновременно вызывать в нескольких репликах.
+// Ошибка одного расписания не останавливает остальные: оно откладывается (см.
+// TaskRecurrence.Fail), а RunDue переходит к следующему.
+func (s *RecurrenceService) RunDue(ctx context.Context, now time.Time) (int, error) {
+	created := 0
+	for {
+		recurrence, task, err := s.runNext(ctx, now)
+		if recurrence == nil {
+			return created, err
+		}
+		if err != nil {
+			if err := s.postpone(ctx, recurrence, now, err); err != nil {
+				return created, err
+			}
+			continue
+		}
+		if task != nil {
+			created++
+		}
+	}
+}
+
+// postpone отмечает неудачную попытку отдельной транзакцией: транзакция попытки уже
+// откачена. Отложенное расписание не попадает в ClaimDueWithTx до RetryAt, поэтому
+// в этом же вызове RunDue оно больше не обрабатывается.
+func (s *RecurrenceService) postpone(ctx context.Context, recurrence *domain.TaskRecurrence, now time.Time, cause error) error {
+	recurrence.Fail(now)
+	if err := s.repo.UpdateRetry(ctx, recurrence); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Error("Не удалось создать задачу по расписанию повторения, попытка отложена",
+		"recurrence_id", recurrence.ID, "template_task_id", recurrence.TemplateTaskID,
+		"failures", recurrence.Failures, "retry_at", recurrence.RetryAt, "error", cause)
+	return nil
+}
+
+// runNext обрабатывает одно наступившее расписание и возвращает его; nil - наступивших
+// расписаний нет. Если планировщик не работал и повторений набралось несколько,
+// создаётся только последнее из них (см. TaskRecurrence.CatchUp).
+func (s *RecurrenceService) runNext(ctx context.Context, now time.Time) (*domain.TaskRecurrence, *domain.Task, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, errors.Internal(err, "Не удалось начать транзакцию")
+	}
+	defer tx.Rollback()
+
+	claimed, err := s.repo.ClaimDueWithTx(ctx, tx, now)
+	if err != nil || claimed == nil {
+		return nil, nil, err
+	}
+	// Расписание меняется по ходу обработки; при ошибке откладывается исходное
+	recurrence := *claimed
+	log := logger.FromContext(ctx).With("recurrence_id", recurrence.ID, "template_task_id", recurrence.TemplateTaskID)
+
+	occurrence, skipped, err := recurrence.CatchUp(now)
+	if err != nil {
+		// Правило проверяется при сохранении; испорченное расписание останавливается
+		log.Error("Неверное расписание повторения остановлено", "error", err)
+		recurrence.NextRunAt = nil
+	}
+
+	task, err := s.createOccurrence(ctx, tx, &recurrence, occurrence, log)
+	if err != nil {
+		return claimed, nil, err
+	}
+
+	if err := s.repo.UpdateScheduleWithTx(ctx, tx, &recurrence); err != nil {
+		return claimed, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return claimed, nil, errors.Internal(err, "Не удалось зафиксировать транзакцию")
+	}
+
+	if skipped > 0 {
+		log.Warn("Пропущены повторения задачи", "skipped", skipped)
+	}
+	if task != nil {
+		metrics.TasksCreated.Inc()
+		log.Info("Задача создана по расписанию повторения", "task_id", task.ID, "occurrence_at", occurrence)
+	}
+
+	return &recurrence, task, nil
+}
+
+// createOccurrence создаёт задачу повторения, если её ещё не создала другая реплика.
+// Если шаблон или его проект удалены, расписание останавливается без ошибки.
+func (s *RecurrenceService) createOccurrence(ctx context.Context, tx *sql.Tx, recurrence *domain.TaskRecurrence, occurrence time.Time, log *logger.Logger) (*domain.Task, error) {
+	template, err := s.taskRepo.GetByID(ctx, recurrence.TemplateTaskID)
+	if isNotFound(err) {
+		log.Warn("Задача-шаблон не найдена, расписание повторения остановлено")
+		recurrence.NextRunAt = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var project *domain.Project
+	if template.ProjectID != nil {
+		project, err = s.projectRepo.GetByID(ctx, *template.ProjectID)
+		if isNotFound(err) {
+			log.Warn("Проект задачи-шаблона не найден, расписание повторения остановлено", "project_id", *template.ProjectID)
+			recurrence.NextRunAt = nil
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	claimed, err := s.repo.ClaimOccurrenceWithTx(ctx, tx, recurrence.ID, occurrence)
+	if err != nil || !claimed {
+		return nil, err
+	}
+
+	task, err := s.materialize(ctx, tx, template, project, occurrence)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetOccurrenceTaskWithTx(ctx, tx, recurrence.ID, occurrence, task.ID); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// materialize создаёт копию шаблона в конце колонки "new": название, описание, приоритет,
+// проект и родительская задача берутся из шаблона, участники копируются. project - проект
+// шаблона или nil.
+func (s *RecurrenceService) materialize(ctx context.Context, tx *sql.Tx, template *domain.Task, project *domain.Project, occurrence time.Time) (*domain.Task, error) {
+	task := domain.NewTask(template.Title, template.Description, template.Priority, template.CreatedBy, nil)
+	task.ParentID = template.ParentID
+
+	if project != nil {
+		number, err := s.projectRepo.NextTaskNumberWithTx(ctx, tx, project.ID)
+		if err != nil {
+			return nil, err
+		}
+		key := project.TaskKey(number)
+		task.ProjectID = &project.ID
+		task.Key = &key
+	}
+
+	if err := s.taskRepo.LockBoardWithTx(ctx, tx); err != nil {
+		return nil, err
+	}
+	last, err := s.taskRepo.LastRankWithTx(ctx, tx, task.Status)
+	if err != nil {
+		return nil, err
+	}
+	rank := domain.RankBetween(last, "")
+	task.BoardRank = &rank
+
+	if err := s.taskRepo.CreateWithTx(ctx, tx, task); err != nil {
+		return nil, err
+	}
+
+	participants, err := s.participantRepo.GetParticipants(ctx, template.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range participants {
+		participant := domain.NewTaskParticipant(task.ID, p.EmployeeID, p.Role)
+		if err := s.participantRepo.AddParticipantWithTx(ctx, tx, participant); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.transitionRepo.CreateWithTx(ctx, tx, domain.NewStatusTransition(task.ID, nil, task.Status, task.CreatedBy)); err != nil {
+		return nil, err
+	}
+
+	source := template.ID.String()
+	if template.Key != nil {
+		source = *template.Key
+	}
+	systemMsg := domain.NewSystemMessage(task.ID, fmt.Sprintf("Задача создана по расписанию повторения задачи %s (%s)",
+		source, occurrence.UTC().Format(time.RFC3339)))
+	if err := s.messageRepo.CreateWithTx(ctx, tx, systemMsg); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/pkg/errors"
+)
+
+const maxSearchQueryLength = 200
+
+type SearchService struct {
+	repo      repository.SearchRepository
+	fieldRepo repository.CustomFieldRepository
+}
+
+func NewSearchService(repo repository.SearchRepository, fieldRepo repository.CustomFieldRepository) *SearchService {
+	return &SearchService{
+		repo:      repo,
+		fieldRepo: fieldRepo,
+	}
+}
+
+// Search ищет задачи по названию, описанию и сообщениям.
+// Запрос поддерживает синтаксис websearch: "точная фраза", OR, -исключение.
+func (s *SearchService) Search(ctx context.Context, filter repository.SearchFilter) ([]*domain.SearchResult, int, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Query == "" {
+		return nil, 0, errors.BadRequest("Параметр q обязателен")
+	}
+	if utf8.RuneCountInString(filter.Query) > maxSearchQueryLength {
+		return nil, 0, errors.BadRequest("Поисковый запрос слишком длинный")
+	}
+
+	if err := resolveCustomFields(ctx, s.fieldRepo, &filter.TaskFilter); err != nil {
+		return nil, 0, err
+	}
+
+	return s.repo.Search(ctx, filter)
+}
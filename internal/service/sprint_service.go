@@ -0,0 +1,401 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/logger"
+	"github.com/google/uuid"
+)
+
+type SprintService struct {
+	repo        repository.SprintRepository
+	projectRepo repository.ProjectRepository
+	access      *TaskAccess
+	messageRepo repository.MessageRepository
+	db          *sql.DB
+}
+
+func NewSprintService(
+	repo repository.SprintRepository,
+	projectRepo repository.ProjectRepository,
+	access *TaskAccess,
+	messageRepo repository.MessageRepository,
+	db *sql.DB,
+) *SprintService {
+	return &SprintService{
+		repo:        repo,
+		projectRepo: projectRepo,
+		access:      access,
+		messageRepo: messageRepo,
+		db:          db,
+	}
+}
+
+// SaveSprintRequest - данные спринта; даты в формате ГГГГ-ММ-ДД
+type SaveSprintRequest struct {
+	Name      string
+	Goal      string
+	StartDate string
+	EndDate   string
+}
+
+// CreateSprint создаёт запланированный спринт; управляет спринтами владелец проекта
+func (s *SprintService) CreateSprint(ctx context.Context, projectID, creatorID uuid.UUID, req SaveSprintRequest) (*domain.Sprint, error) {
+	if _, err := s.getOwnedProject(ctx, projectID, creatorID); err != nil {
+		return nil, err
+	}
+
+	name, start, end, err := validateSprint(req)
+	if err != nil {
+		return nil, err
+	}
+
+	sprint := domain.NewSprint(projectID, name, strings.TrimSpace(req.Goal), start, end, creatorID)
+	if err := s.repo.Create(ctx, sprint); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Спринт создан", "sprint_id", sprint.ID, "project_id", projectID)
+
+	return sprint, nil
+}
+
+func (s *SprintService) GetSprints(ctx context.Context, projectID, employeeID uuid.UUID, state *domain.SprintState) ([]*domain.Sprint, error) {
+	if err := s.checkMember(ctx, projectID, employeeID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByProject(ctx, projectID, state)
+}
+
+// GetSprint возвращает спринт, если сотрудник состоит в его проекте
+func (s *SprintService) GetSprint(ctx context.Context, id, employeeID uuid.UUID) (*domain.Sprint, error) {
+	sprint, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.projectRepo.IsMember(ctx, sprint.ProjectID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, errors.NotFound("Спринт не найден")
+	}
+
+	return sprint, nil
+}
+
+// UpdateSprint изменяет название, цель и даты незакрытого спринта
+func (s *SprintService) UpdateSprint(ctx context.Context, id, editorID uuid.UUID, req SaveSprintRequest) (*domain.Sprint, error) {
+	sprint, err := s.getOwnedSprint(ctx, id, editorID)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.State == domain.SprintStateClosed {
+		return nil, errors.Conflict("Закрытый спринт нельзя изменить")
+	}
+
+	if sprint.Name, sprint.StartDate, sprint.EndDate, err = validateSprint(req); err != nil {
+		return nil, err
+	}
+	sprint.Goal = strings.TrimSpace(req.Goal)
+
+	if err := s.repo.Update(ctx, sprint); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Спринт обновлён", "sprint_id", id)
+
+	return sprint, nil
+}
+
+// DeleteSprint удаляет запланированный спринт; его задачи возвращаются в бэклог
+func (s *SprintService) DeleteSprint(ctx context.Context, id, editorID uuid.UUID) error {
+	sprint, err := s.getOwnedSprint(ctx, id, editorID)
+	if err != nil {
+		return err
+	}
+	if sprint.State != domain.SprintStatePlanned {
+		return errors.Conflict("Удалить можно только запланированный спринт")
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("Спринт удалён", "sprint_id", id)
+
+	return nil
+}
+
+// StartSprint начинает спринт. В проекте может идти только один спринт; задачи,
+// находящиеся в спринте в момент старта, считаются запланированными (committed).
+func (s *SprintService) StartSprint(ctx context.Context, id, editorID uuid.UUID) (*domain.Sprint, error) {
+	sprint, err := s.getOwnedSprint(ctx, id, editorID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось начать транзакцию")
+	}
+	defer tx.Rollback()
+
+	active, err := s.repo.LockProjectWithTx(ctx, tx, sprint.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if sprint, err = s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	if sprint.State != domain.SprintStatePlanned {
+		return nil, errors.Conflict("Начать можно только запланированный спринт")
+	}
+	if active != nil {
+		return nil, errors.Conflict("В проекте уже идёт другой спринт")
+	}
+
+	if err := s.repo.StartWithTx(ctx, tx, id, time.Now().Truncate(time.Microsecond)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Internal(err, "Не удалось зафиксировать транзакцию")
+	}
+
+	logger.FromContext(ctx).Info("Спринт начат", "sprint_id", id, "project_id", sprint.ProjectID)
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// CloseSprint закрывает идущий спринт. Незакрытые задачи переносятся в спринт moveTo
+// (запланированный или идущий спринт того же проекта) или, если он не указан, в бэклог.
+// Возвращает число перенесённых задач.
+func (s *SprintService) CloseSprint(ctx context.Context, id, editorID uuid.UUID, moveTo *uuid.UUID) (int, error) {
+	sprint, err := s.getOwnedSprint(ctx, id, editorID)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.Internal(err, "Не удалось начать транзакцию")
+	}
+	defer tx.Rollback()
+
+	if _, err := s.repo.LockProjectWithTx(ctx, tx, sprint.ProjectID); err != nil {
+		return 0, err
+	}
+	if sprint, err = s.repo.GetByID(ctx, id); err != nil {
+		return 0, err
+	}
+	if sprint.State != domain.SprintStateActive {
+		return 0, errors.Conflict("Закрыть можно только идущий спринт")
+	}
+
+	var target *domain.Sprint
+	if moveTo != nil {
+		if *moveTo == id {
+			return 0, errors.BadRequest("Нельзя перенести задачи в закрываемый спринт")
+		}
+		if target, err = s.repo.GetByID(ctx, *moveTo); err != nil {
+			return 0, err
+		}
+		if target.ProjectID != sprint.ProjectID || target.State == domain.SprintStateClosed {
+			return 0, errors.BadRequest("Задачи можно перенести только в незакрытый спринт того же проекта")
+		}
+	}
+
+	taskIDs, err := s.repo.UnfinishedTaskIDsWithTx(ctx, tx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	closedAt := time.Now().Truncate(time.Microsecond)
+	for _, taskID := range taskIDs {
+		if err := s.repo.RemoveTaskWithTx(ctx, tx, id, taskID, closedAt); err != nil {
+			return 0, err
+		}
+
+		content := fmt.Sprintf("Спринт '%s' закрыт, задача возвращена в бэклог", sprint.Name)
+		if target != nil {
+			if err := s.repo.AddTaskWithTx(ctx, tx, target.ID, taskID); err != nil {
+				return 0, err
+			}
+			content = fmt.Sprintf("Спринт '%s' закрыт, задача перенесена в спринт '%s'", sprint.Name, target.Name)
+		}
+		if err := s.messageRepo.CreateWithTx(ctx, tx, domain.NewSystemMessage(taskID, content)); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := s.repo.CloseWithTx(ctx, tx, id, closedAt); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.Internal(err, "Не удалось зафиксировать транзакцию")
+	}
+
+	logger.FromContext(ctx).Info("Спринт закрыт", "sprint_id", id, "moved_tasks", len(taskIDs))
+
+	return len(taskIDs), nil
+}
+
+// SetTaskSprint переводит задачу в незакрытый спринт её проекта; sprintID == nil
+// возвращает задачу в бэклог. Изменение фиксируется системным сообщением.
+func (s *SprintService) SetTaskSprint(ctx context.Context, taskID, employeeID uuid.UUID, sprintID *uuid.UUID) error {
+	task, err := s.access.GetTask(ctx, taskID, employeeID)
+	if err != nil {
+		return err
+	}
+
+	if (task.SprintID == nil && sprintID == nil) || (task.SprintID != nil && sprintID != nil && *task.SprintID == *sprintID) {
+		return nil
+	}
+
+	if task.ProjectID == nil {
+		return errors.BadRequest("Спринты доступны только задачам проектов")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Internal(err, "Не удалось начать транзакцию")
+	}
+	defer tx.Rollback()
+
+	if _, err := s.repo.LockProjectWithTx(ctx, tx, *task.ProjectID); err != nil {
+		return err
+	}
+
+	var current, target *domain.Sprint
+	if task.SprintID != nil {
+		if current, err = s.repo.GetByID(ctx, *task.SprintID); err != nil {
+			return err
+		}
+	}
+	if sprintID != nil {
+		if target, err = s.repo.GetByID(ctx, *sprintID); err != nil {
+			return err
+		}
+		if *task.ProjectID != target.ProjectID {
+			return errors.BadRequest("Спринт относится к другому проекту")
+		}
+		if target.State == domain.SprintStateClosed {
+			return errors.Conflict("Нельзя добавить задачу в закрытый спринт")
+		}
+	}
+
+	var content string
+	if current != nil {
+		if err := s.repo.RemoveTaskWithTx(ctx, tx, current.ID, taskID, time.Now().Truncate(time.Microsecond)); err != nil {
+			return err
+		}
+		content = fmt.Sprintf("Задача убрана из спринта '%s'", current.Name)
+	}
+	if target != nil {
+		if err := s.repo.AddTaskWithTx(ctx, tx, target.ID, taskID); err != nil {
+			return err
+		}
+		content = fmt.Sprintf("Задача добавлена в спринт '%s'", target.Name)
+		if current != nil {
+			content = fmt.Sprintf("Задача перенесена из спринта '%s' в спринт '%s'", current.Name, target.Name)
+		}
+	}
+
+	if err := s.messageRepo.CreateWithTx(ctx, tx, domain.NewSystemMessage(taskID, content)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Internal(err, "Не удалось зафиксировать транзакцию")
+	}
+
+	logger.FromContext(ctx).Info("Спринт задачи изменён", "task_id", taskID, "sprint_id", sprintID)
+
+	return nil
+}
+
+func (s *SprintService) GetReport(ctx context.Context, id, employeeID uuid.UUID) (*domain.SprintReport, error) {
+	if _, err := s.GetSprint(ctx, id, employeeID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetReport(ctx, id)
+}
+
+func (s *SprintService) checkMember(ctx context.Context, projectID, employeeID uuid.UUID) error {
+	if _, err := s.projectRepo.GetByID(ctx, projectID); err != nil {
+		return err
+	}
+
+	member, err := s.projectRepo.IsMember(ctx, projectID, employeeID)
+	if err != nil {
+		return err
+	}
+	if !member {
+		return errors.NotFound("Проект не найден")
+	}
+
+	return nil
+}
+
+func (s *SprintService) getOwnedProject(ctx context.Context, projectID, editorID uuid.UUID) (*domain.Project, error) {
+	if err := s.checkMember(ctx, projectID, editorID); err != nil {
+		return nil, err
+	}
+
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.OwnerID != editorID {
+		return nil, errors.Forbidden("Управлять спринтами может только владелец проекта")
+	}
+
+	return project, nil
+}
+
+func (s *SprintService) getOwnedSprint(ctx context.Context, id, editorID uuid.UUID) (*domain.Sprint, error) {
+	sprint, err := s.GetSprint(ctx, id, editorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getOwnedProject(ctx, sprint.ProjectID, editorID); err != nil {
+		return nil, err
+	}
+
+	return sprint, nil
+}
+
+// validateSprint проверяет название и даты спринта
+func validateSprint(req SaveSprintRequest) (string, time.Time, time.Time, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return "", time.Time{}, time.Time{}, errors.BadRequest("Не указано название спринта")
+	}
+
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, errors.BadRequest("Неверный формат даты начала, ожидается ГГГГ-ММ-ДД")
+	}
+	end, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, errors.BadRequest("Неверный формат даты окончания, ожидается ГГГГ-ММ-ДД")
+	}
+	if end.Before(start) {
+		return "", time.Time{}, time.Time{}, errors.BadRequest("Дата окончания спринта раньше даты начала")
+	}
+
+	return name, start, end, nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// TaskAccess проверяет, может ли сотрудник видеть и изменять задачу.
+// Задачи вне проектов доступны всем, задачи проекта - только участникам проекта.
+// Для остальных задача выглядит несуществующей, чтобы не раскрывать её наличие.
+type TaskAccess struct {
+	taskRepo    repository.TaskRepository
+	projectRepo repository.ProjectRepository
+}
+
+func NewTaskAccess(taskRepo repository.TaskRepository, projectRepo repository.ProjectRepository) *TaskAccess {
+	return &TaskAccess{
+		taskRepo:    taskRepo,
+		projectRepo: projectRepo,
+	}
+}
+
+// GetTask возвращает задачу, если она доступна сотруднику
+func (a *TaskAccess) GetTask(ctx context.Context, taskID, employeeID uuid.UUID) (*domain.Task, error) {
+	task, err := a.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.Check(ctx, task, employeeID); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+func (a *TaskAccess) Check(ctx context.Context, task *domain.Task, employeeID uuid.UUID) error {
+	if task.ProjectID == nil {
+		return nil
+	}
+
+	member, err := a.projectRepo.IsMember(ctx, *task.ProjectID, employeeID)
+	if err != nil {
+		return err
+	}
+	if !member {
+		return errors.NotFound("Задача не найдена")
+	}
+
+	return nil
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/metrics"
+	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// GetBoard возвращает доску: колонки по статусам с задачами в порядке ранга.
+// Если в фильтре заданы статусы, возвращаются только их колонки. Архивные задачи
+// на доску не попадают, пока фильтр archived не задан явно.
+func (s *TaskService) GetBoard(ctx context.Context, filter repository.TaskFilter, perColumn int) ([]*domain.BoardColumn, error) {
+	if err := resolveCustomFields(ctx, s.fieldRepo, &filter); err != nil {
+		return nil, err
+	}
+	if filter.Archived == nil {
+		archived := false
+		filter.Archived = &archived
+	}
+
+	statuses := domain.BoardStatuses
+	if len(filter.Status) > 0 {
+		statuses = []domain.TaskStatus{}
+		for _, status := range domain.BoardStatuses {
+			for _, selected := range filter.Status {
+				if status == selected {
+					statuses = append(statuses, status)
+					break
+				}
+			}
+		}
+	}
+	filter.Status = nil
+
+	tasks, err := s.taskRepo.GetBoard(ctx, statuses, filter, perColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.taskRepo.CountByStatus(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]*domain.BoardColumn, len(statuses))
+	byStatus := make(map[domain.TaskStatus]*domain.BoardColumn, len(statuses))
+	for i, status := range statuses {
+		columns[i] = &domain.BoardColumn{
+			Status:   status,
+			WIPLimit: s.opts.WIPLimits[status],
+			Total:    counts[status],
+			Tasks:    []*domain.Task{},
+		}
+		byStatus[status] = columns[i]
+	}
+	for _, task := range tasks {
+		if column := byStatus[task.Status]; column != nil {
+			column.Tasks = append(column.Tasks, task)
+		}
+	}
+
+	return columns, nil
+}
+
+// MoveTaskRequest - перемещение задачи на доске. AfterID - задача, под которую встаёт
+// перемещаемая, BeforeID - задача, над которой она встаёт; обе должны быть в колонке Status.
+// Без соседей задача остаётся на месте своей колонки или встаёт в конец новой.
+type MoveTaskRequest struct {
+	TaskID     uuid.UUID
+	EmployeeID uuid.UUID
+	Status     domain.TaskStatus
+	AfterID    *uuid.UUID
+	BeforeID   *uuid.UUID
+}
+
+// MoveTask атомарно меняет статус и позицию задачи на доске. При смене статуса
+// проверяются правила перехода (см. UpdateTaskStatus) и WIP-лимит новой колонки.
+func (s *TaskService) MoveTask(ctx context.Context, req MoveTaskRequest) ([]string, error) {
+	if !req.Status.IsValid() {
+		return nil, errors.BadRequest("Неверный статус задачи")
+	}
+	if (req.AfterID != nil && *req.AfterID == req.TaskID) || (req.BeforeID != nil && *req.BeforeID == req.TaskID) {
+		return nil, errors.BadRequest("Задачу нельзя поставить рядом с самой собой")
+	}
+
+	if _, err := s.access.GetTask(ctx, req.TaskID, req.EmployeeID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось начать транзакцию")
+	}
+	defer tx.Rollback()
+
+	if err := s.taskRepo.LockBoardWithTx(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	// Правила перехода и WIP-лимит проверяются по задаче, перечитанной под блокировкой:
+	// параллельное перемещение могло изменить её статус после проверки доступа
+	task, err := s.taskRepo.GetForMoveWithTx(ctx, tx, req.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings, err := s.checkStatusChange(ctx, task, req.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit := s.opts.WIPLimits[req.Status]; limit > 0 && task.Status != req.Status {
+		count, err := s.taskRepo.CountInColumnWithTx(ctx, tx, req.Status, task.ProjectID, task.ID)
+		if err != nil {
+			return nil, err
+		}
+		if count >= limit {
+			return nil, errors.Conflict(fmt.Sprintf("Колонка '%s' заполнена: WIP-лимит %d", req.Status, limit))
+		}
+	}
+
+	rank, err := s.boardRank(ctx, tx, task, req)
+	if err != nil {
+		return nil, err
+	}
+
+	oldStatus, err := s.taskRepo.MoveWithTx(ctx, tx, task.ID, req.Status, rank)
+	if err != nil {
+		return nil, err
+	}
+
+	if oldStatus != req.Status {
+		transition := domain.NewStatusTransition(task.ID, &oldStatus, req.Status, req.EmployeeID)
+		if err := s.transitionRepo.CreateWithTx(ctx, tx, transition); err != nil {
+			return nil, err
+		}
+
+		content := fmt.Sprintf("Статус задачи изменён с '%s' на '%s'", oldStatus, req.Status)
+		if err := s.messageRepo.CreateWithTx(ctx, tx, domain.NewSystemMessage(task.ID, content)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, warning := range warnings {
+		if err := s.messageRepo.CreateWithTx(ctx, tx, domain.NewSystemMessage(task.ID, "Задача взята в работу с предупреждением. "+warning)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Internal(err, "Не удалось зафиксировать транзакцию")
+	}
+
+	if oldStatus != req.Status {
+		metrics.StatusTransitions.WithLabelValues(string(oldStatus), string(req.Status)).Inc()
+		logger.FromContext(ctx).Info("Статус задачи обновлён", "task_id", task.ID, "old_status", oldStatus, "new_status", req.Status)
+	} else {
+		logger.FromContext(ctx).Info("Задача перемещена в колонке", "task_id", task.ID, "status", req.Status)
+	}
+
+	return warnings, nil
+}
+
+// boardRank вычисляет ранг задачи в колонке req.Status по соседям из запроса.
+// Если указан только один сосед, второй берётся из колонки.
+func (s *TaskService) boardRank(ctx context.Context, tx *sql.Tx, task *domain.Task, req MoveTaskRequest) (string, error) {
+	if req.AfterID == nil && req.BeforeID == nil {
+		if req.Status == task.Status && task.BoardRank != nil {
+			return *task.BoardRank, nil
+		}
+		last, err := s.taskRepo.LastRankWithTx(ctx, tx, req.Status)
+		if err != nil {
+			return "", err
+		}
+		return domain.RankBetween(last, ""), nil
+	}
+
+	var prev, next string
+	var err error
+	if req.AfterID != nil {
+		if prev, err = s.neighbourRank(ctx, tx, *req.AfterID, req.Status); err != nil {
+			return "", err
+		}
+	}
+	if req.BeforeID != nil {
+		if next, err = s.neighbourRank(ctx, tx, *req.BeforeID, req.Status); err != nil {
+			return "", err
+		}
+	}
+
+	switch {
+	case req.BeforeID == nil:
+		next, err = s.taskRepo.AdjacentRankWithTx(ctx, tx, req.Status, prev, task.ID, false)
+	case req.AfterID == nil:
+		prev, err = s.taskRepo.AdjacentRankWithTx(ctx, tx, req.Status, next, task.ID, true)
+	case prev >= next:
+		return "", errors.BadRequest("Задача after_id должна стоять в колонке выше задачи before_id")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return domain.RankBetween(prev, next), nil
+}
+
+func (s *TaskService) neighbourRank(ctx context.Context, tx *sql.Tx, id uuid.UUID, status domain.TaskStatus) (string, error) {
+	neighbourStatus, rank, err := s.taskRepo.GetBoardPositionWithTx(ctx, tx, id)
+	if err != nil {
+		return "", err
+	}
+	if neighbourStatus != status {
+		return "", errors.BadRequest("Соседняя задача находится в другой колонке")
+	}
+	return rank, nil
+}
@@ -0,0 +1,508 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	goerrors "errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/importer"
+	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// sourceNames - названия источников в системных сообщениях импортированных задач
+var sourceNames = map[importer.Source]string{
+	importer.SourceJira:   "Jira",
+	importer.SourceTrello: "Trello",
+	importer.SourceCSV:    "CSV",
+}
+
+// TaskImportService создаёт задачи по выгрузке внешнего трекера (см. пакет importer):
+// исполнители становятся участниками задачи, комментарии - сообщениями, списания времени -
+// записями времени
+type TaskImportService struct {
+	taskRepo        repository.TaskRepository
+	participantRepo repository.TaskParticipantRepository
+	messageRepo     repository.MessageRepository
+	timeEntryRepo   repository.TimeEntryRepository
+	employeeRepo    repository.EmployeeRepository
+	projectRepo     repository.ProjectRepository
+	transitionRepo  repository.StatusTransitionRepository
+	db              *sql.DB
+}
+
+func NewTaskImportService(
+	taskRepo repository.TaskRepository,
+	participantRepo repository.TaskParticipantRepository,
+	messageRepo repository.MessageRepository,
+	timeEntryRepo repository.TimeEntryRepository,
+	employeeRepo repository.EmployeeRepository,
+	projectRepo repository.ProjectRepository,
+	transitionRepo repository.StatusTransitionRepository,
+	db *sql.DB,
+) *TaskImportService {
+	return &TaskImportService{
+		taskRepo:        taskRepo,
+		participantRepo: participantRepo,
+		messageRepo:     messageRepo,
+		timeEntryRepo:   timeEntryRepo,
+		employeeRepo:    employeeRepo,
+		projectRepo:     projectRepo,
+		transitionRepo:  transitionRepo,
+		db:              db,
+	}
+}
+
+// TaskImportOptions - параметры импорта задач
+type TaskImportOptions struct {
+	Source  importer.Source
+	Mapping *importer.Mapping
+	// ProjectID - проект, в который попадают задачи; сопоставленные сотрудники добавляются
+	// в его участники. Без проекта задачи создаются вне проектов.
+	ProjectID *uuid.UUID
+	// MaxDepth - максимальное число уровней иерархии (см. TaskOptions.MaxDepth)
+	MaxDepth int
+	// DryRun - выполнить импорт и откатить транзакцию, чтобы получить отчёт
+	DryRun bool
+}
+
+// TaskImportReport - результат импорта и всё, что не удалось сопоставить
+type TaskImportReport struct {
+	Source       importer.Source `json:"source"`
+	DryRun       bool            `json:"dry_run"`
+	Tasks        int             `json:"tasks"`
+	SkippedTasks int             `json:"skipped_tasks"`
+	Participants int             `json:"participants"`
+	Messages     int             `json:"messages"`
+	TimeEntries  int             `json:"time_entries"`
+	// Unmapped* - значения источника без сопоставления и число их упоминаний
+	UnmappedStatuses   map[string]int `json:"unmapped_statuses"`
+	UnmappedPriorities map[string]int `json:"unmapped_priorities"`
+	UnmappedUsers      map[string]int `json:"unmapped_users"`
+	// Issues - первые maxImportIssues проблем, IssuesTotal - их общее число
+	Issues      []TaskImportIssue `json:"issues"`
+	IssuesTotal int               `json:"issues_total"`
+}
+
+// TaskImportIssue - проблема с задачей источника
+type TaskImportIssue struct {
+	Key     string `json:"key"`
+	Message string `json:"message"`
+}
+
+func (r *TaskImportReport) issue(key, message string) {
+	r.IssuesTotal++
+	if len(r.Issues) < maxImportIssues {
+		r.Issues = append(r.Issues, TaskImportIssue{Key: key, Message: message})
+	}
+}
+
+// Import создаёт задачи в одной транзакции: импорт либо выполняется целиком, либо не меняет
+// ничего. Родительские задачи создаются раньше подзадач. Задача пропускается, если её статус
+// не сопоставлен и не задан DefaultStatus или не найден ни автор, ни создатель по умолчанию.
+// Ограничения WIP при импорте не проверяются. Блокировка доски держится до конца транзакции,
+// поэтому на время импорта создание и перемещение задач в API ожидают его завершения.
+func (s *TaskImportService) Import(ctx context.Context, issues []*importer.Issue, opts TaskImportOptions) (*TaskImportReport, error) {
+	im := &taskImport{
+		s:    s,
+		opts: opts,
+		report: &TaskImportReport{
+			Source:             opts.Source,
+			DryRun:             opts.DryRun,
+			UnmappedStatuses:   map[string]int{},
+			UnmappedPriorities: map[string]int{},
+			UnmappedUsers:      map[string]int{},
+			Issues:             []TaskImportIssue{},
+		},
+		employees: map[string]*uuid.UUID{},
+		imported:  map[string]importedTask{},
+		members:   map[uuid.UUID]bool{},
+		ranks:     map[domain.TaskStatus]string{},
+	}
+
+	if opts.ProjectID != nil {
+		project, err := s.projectRepo.GetByID(ctx, *opts.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		im.project = project
+	}
+
+	if email := opts.Mapping.DefaultCreator; email != "" {
+		employee, err := s.employeeRepo.GetByEmail(ctx, email)
+		if err != nil {
+			return nil, errors.BadRequest("Создатель по умолчанию " + email + " не найден")
+		}
+		im.defaultCreator = &employee.ID
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось начать транзакцию")
+	}
+	defer tx.Rollback()
+
+	// Проект блокируется раньше доски, как при создании задачи (TaskService.CreateTask),
+	// иначе импорт и создание задачи в том же проекте взаимно блокируются
+	if im.project != nil {
+		if err := s.projectRepo.LockWithTx(ctx, tx, im.project.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.taskRepo.LockBoardWithTx(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	for _, issue := range parentsFirst(issues) {
+		if err := im.importIssue(ctx, tx, issue); err != nil {
+			return nil, err
+		}
+	}
+
+	if !opts.DryRun {
+		if err := tx.Commit(); err != nil {
+			return nil, errors.Internal(err, "Не удалось зафиксировать транзакцию")
+		}
+	}
+
+	logger.FromContext(ctx).Info("Задачи импортированы",
+		"source", opts.Source, "dry_run", opts.DryRun,
+		"tasks", im.report.Tasks, "skipped", im.report.SkippedTasks,
+		"messages", im.report.Messages, "time_entries", im.report.TimeEntries)
+
+	return im.report, nil
+}
+
+// importedTask - созданная задача и её уровень в иерархии (корневая - 1)
+type importedTask struct {
+	id    uuid.UUID
+	depth int
+}
+
+// taskImport хранит состояние одного импорта
+type taskImport struct {
+	s              *TaskImportService
+	opts           TaskImportOptions
+	report         *TaskImportReport
+	project        *domain.Project
+	defaultCreator *uuid.UUID
+
+	// employees - сотрудник по email (nil, если не найден)
+	employees map[string]*uuid.UUID
+	// imported - созданные задачи по ключу источника
+	imported map[string]importedTask
+	// members - сотрудники, уже добавленные в участники проекта
+	members map[uuid.UUID]bool
+	// ranks - последняя позиция в колонке доски по статусу
+	ranks map[domain.TaskStatus]string
+}
+
+func (im *taskImport) importIssue(ctx context.Context, tx *sql.Tx, issue *importer.Issue) error {
+	mapping := im.opts.Mapping
+
+	if _, duplicate := im.imported[issue.Key]; duplicate && issue.Key != "" {
+		im.skip(issue, "Повторяющийся ключ задачи")
+		return nil
+	}
+	if issue.Title == "" {
+		im.skip(issue, "Нет названия")
+		return nil
+	}
+
+	status, ok := mapping.Status(issue.Status)
+	if !ok {
+		im.report.UnmappedStatuses[issue.Status]++
+		if status == "" {
+			im.skip(issue, "Статус '"+issue.Status+"' не сопоставлен")
+			return nil
+		}
+	}
+
+	priority, ok := mapping.Priority(issue.Priority)
+	if !ok {
+		im.report.UnmappedPriorities[issue.Priority]++
+	}
+
+	creator, err := im.employee(ctx, issue.Reporter)
+	if err != nil {
+		return err
+	}
+	if creator == nil {
+		if im.defaultCreator == nil {
+			im.skip(issue, "Автор '"+issue.Reporter+"' не сопоставлен, создатель по умолчанию не задан")
+			return nil
+		}
+		creator = im.defaultCreator
+	}
+
+	task := domain.NewTask(truncateRunes(issue.Title, 500), issue.Description, priority, *creator, issue.DueDate)
+	task.Status = status
+	task.Archived = issue.Archived
+	if !issue.CreatedAt.IsZero() {
+		task.CreatedAt = issue.CreatedAt
+	}
+	if issue.UpdatedAt.After(task.CreatedAt) {
+		task.UpdatedAt = issue.UpdatedAt
+	} else {
+		task.UpdatedAt = task.CreatedAt
+	}
+
+	depth := 1
+	if issue.ParentKey != "" {
+		parent, ok := im.imported[issue.ParentKey]
+		switch {
+		case !ok:
+			im.report.issue(issue.Key, "Родительская задача "+issue.ParentKey+" не импортирована, задача создана корневой")
+		case parent.depth >= im.opts.MaxDepth:
+			im.report.issue(issue.Key, fmt.Sprintf("Превышена максимальная глубина вложенности (%d), задача создана корневой", im.opts.MaxDepth))
+		default:
+			task.ParentID = &parent.id
+			depth = parent.depth + 1
+		}
+	}
+
+	if im.project != nil {
+		number, err := im.s.projectRepo.NextTaskNumberWithTx(ctx, tx, im.project.ID)
+		if err != nil {
+			return err
+		}
+		key := im.project.TaskKey(number)
+		task.ProjectID = &im.project.ID
+		task.Key = &key
+		if err := im.addMember(ctx, tx, *creator); err != nil {
+			return err
+		}
+	}
+
+	rank, err := im.nextRank(ctx, tx, status)
+	if err != nil {
+		return err
+	}
+	task.BoardRank = &rank
+
+	if err := im.s.taskRepo.CreateWithTx(ctx, tx, task); err != nil {
+		return err
+	}
+	im.imported[issue.Key] = importedTask{id: task.ID, depth: depth}
+	im.report.Tasks++
+
+	transition := domain.NewStatusTransition(task.ID, nil, task.Status, *creator)
+	transition.ChangedAt = task.CreatedAt
+	if err := im.s.transitionRepo.CreateWithTx(ctx, tx, transition); err != nil {
+		return err
+	}
+
+	systemMsg := domain.NewSystemMessage(task.ID, fmt.Sprintf("Задача импортирована из %s: %s", sourceNames[im.opts.Source], issue.Key))
+	if err := im.s.messageRepo.CreateWithTx(ctx, tx, systemMsg); err != nil {
+		return err
+	}
+
+	if err := im.importAssignees(ctx, tx, issue, task.ID); err != nil {
+		return err
+	}
+	if err := im.importComments(ctx, tx, issue, task.ID); err != nil {
+		return err
+	}
+	return im.importWorklogs(ctx, tx, issue, task.ID)
+}
+
+func (im *taskImport) importAssignees(ctx context.Context, tx *sql.Tx, issue *importer.Issue, taskID uuid.UUID) error {
+	for _, assignee := range issue.Assignees {
+		employeeID, err := im.employee(ctx, assignee)
+		if err != nil {
+			return err
+		}
+		if employeeID == nil {
+			im.report.issue(issue.Key, "Исполнитель '"+assignee+"' не сопоставлен")
+			continue
+		}
+
+		if err := im.addMember(ctx, tx, *employeeID); err != nil {
+			return err
+		}
+		participant := domain.NewTaskParticipant(taskID, *employeeID, domain.ParticipantRoleExecutor)
+		if err := im.s.participantRepo.AddParticipantWithTx(ctx, tx, participant); err != nil {
+			return err
+		}
+		im.report.Participants++
+	}
+	return nil
+}
+
+// importComments переносит комментарии; комментарий несопоставленного автора сохраняется
+// без автора, а имя автора в источнике ставится в начало текста
+func (im *taskImport) importComments(ctx context.Context, tx *sql.Tx, issue *importer.Issue, taskID uuid.UUID) error {
+	for _, comment := range issue.Comments {
+		if comment.Body == "" {
+			continue
+		}
+
+		authorID, err := im.employee(ctx, comment.Author)
+		if err != nil {
+			return err
+		}
+		content := comment.Body
+		if authorID == nil && comment.Author != "" {
+			content = comment.Author + ": " + content
+		}
+
+		message := domain.NewTaskMessage(taskID, authorID, content, false)
+		if !comment.CreatedAt.IsZero() {
+			message.CreatedAt = comment.CreatedAt
+			message.UpdatedAt = comment.CreatedAt
+		}
+		if err := im.s.messageRepo.CreateWithTx(ctx, tx, message); err != nil {
+			return err
+		}
+		im.report.Messages++
+	}
+	return nil
+}
+
+// importWorklogs переносит списания времени с точностью до сотой часа; списание
+// несопоставленного сотрудника пропускается
+func (im *taskImport) importWorklogs(ctx context.Context, tx *sql.Tx, issue *importer.Issue, taskID uuid.UUID) error {
+	for _, worklog := range issue.Worklogs {
+		hours := math.Round(worklog.Hours*100) / 100
+		if hours <= 0 {
+			im.report.issue(issue.Key, "Списание времени меньше 0,01 часа пропущено")
+			continue
+		}
+
+		employeeID, err := im.employee(ctx, worklog.Author)
+		if err != nil {
+			return err
+		}
+		if employeeID == nil {
+			im.report.issue(issue.Key, fmt.Sprintf("Списание %.2f ч сотрудника '%s' пропущено: сотрудник не сопоставлен", hours, worklog.Author))
+			continue
+		}
+
+		entryDate := worklog.Started
+		if entryDate.IsZero() {
+			entryDate = time.Now()
+		}
+		entry := domain.NewTimeEntry(taskID, *employeeID, hours, worklog.Comment, entryDate)
+		if err := im.s.timeEntryRepo.CreateWithTx(ctx, tx, entry); err != nil {
+			return err
+		}
+		im.report.TimeEntries++
+	}
+	return nil
+}
+
+// employee находит сотрудника для пользователя источника; несопоставленный пользователь
+// попадает в отчёт, пустой - нет
+func (im *taskImport) employee(ctx context.Context, user string) (*uuid.UUID, error) {
+	if user == "" {
+		return nil, nil
+	}
+
+	email := im.opts.Mapping.Email(user)
+	if email == "" {
+		im.report.UnmappedUsers[user]++
+		return nil, nil
+	}
+
+	id, cached := im.employees[email]
+	if !cached {
+		employee, err := im.s.employeeRepo.GetByEmail(ctx, email)
+		if err != nil && !isNotFound(err) {
+			return nil, err
+		}
+		if employee != nil {
+			id = &employee.ID
+		}
+		im.employees[email] = id
+	}
+
+	if id == nil {
+		im.report.UnmappedUsers[user]++
+	}
+	return id, nil
+}
+
+// addMember добавляет сотрудника в участники проекта импорта
+func (im *taskImport) addMember(ctx context.Context, tx *sql.Tx, employeeID uuid.UUID) error {
+	if im.project == nil || im.members[employeeID] {
+		return nil
+	}
+	if err := im.s.projectRepo.AddMemberWithTx(ctx, tx, domain.NewProjectMember(im.project.ID, employeeID)); err != nil {
+		return err
+	}
+	im.members[employeeID] = true
+	return nil
+}
+
+// nextRank возвращает позицию в конце колонки статуса
+func (im *taskImport) nextRank(ctx context.Context, tx *sql.Tx, status domain.TaskStatus) (string, error) {
+	last, ok := im.ranks[status]
+	if !ok {
+		var err error
+		if last, err = im.s.taskRepo.LastRankWithTx(ctx, tx, status); err != nil {
+			return "", err
+		}
+	}
+
+	rank := domain.RankBetween(last, "")
+	im.ranks[status] = rank
+	return rank, nil
+}
+
+func (im *taskImport) skip(issue *importer.Issue, message string) {
+	im.report.SkippedTasks++
+	im.report.issue(issue.Key, message+", задача пропущена")
+}
+
+// parentsFirst упорядочивает задачи так, чтобы родитель шёл раньше подзадач; в остальном
+// порядок источника сохраняется. Задачи из цикла по родителям идут в исходном порядке.
+func parentsFirst(issues []*importer.Issue) []*importer.Issue {
+	byKey := make(map[string]*importer.Issue, len(issues))
+	for _, issue := range issues {
+		if _, ok := byKey[issue.Key]; !ok {
+			byKey[issue.Key] = issue
+		}
+	}
+
+	ordered := make([]*importer.Issue, 0, len(issues))
+	placed := make(map[*importer.Issue]bool, len(issues))
+	visiting := map[*importer.Issue]bool{}
+
+	var place func(issue *importer.Issue)
+	place = func(issue *importer.Issue) {
+		if placed[issue] || visiting[issue] {
+			return
+		}
+		visiting[issue] = true
+		if parent, ok := byKey[issue.ParentKey]; ok && issue.ParentKey != "" {
+			place(parent)
+		}
+		placed[issue] = true
+		ordered = append(ordered, issue)
+	}
+
+	for _, issue := range issues {
+		place(issue)
+	}
+	return ordered
+}
+
+func isNotFound(err error) bool {
+	var appErr *errors.AppError
+	return goerrors.As(err, &appErr) && appErr.Code == errors.ErrCodeNotFound
+}
+
+// truncateRunes обрезает строку до limit символов
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit])
+}
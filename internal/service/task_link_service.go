@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// LinkTypeBlockedBy - обратная форма blocks, допустимая при создании связи:
+// "A blocked_by B" сохраняется как "B blocks A"
+const LinkTypeBlockedBy = "blocked_by"
+
+type TaskLinkService struct {
+	repo   repository.TaskLinkRepository
+	access *TaskAccess
+}
+
+func NewTaskLinkService(repo repository.TaskLinkRepository, access *TaskAccess) *TaskLinkService {
+	return &TaskLinkService{
+		repo:   repo,
+		access: access,
+	}
+}
+
+type CreateTaskLinkRequest struct {
+	TaskID      uuid.UUID
+	OtherTaskID uuid.UUID
+	Type        string
+	CreatedBy   uuid.UUID
+}
+
+// CreateLink связывает две задачи; обе должны быть доступны сотруднику
+func (s *TaskLinkService) CreateLink(ctx context.Context, req CreateTaskLinkRequest) (*domain.TaskLink, error) {
+	source, target := req.TaskID, req.OtherTaskID
+	linkType := domain.TaskLinkType(req.Type)
+	if req.Type == LinkTypeBlockedBy {
+		source, target = target, source
+		linkType = domain.TaskLinkBlocks
+	}
+
+	if !linkType.IsValid() {
+		return nil, errors.BadRequest("Неверный тип связи")
+	}
+	if source == target {
+		return nil, errors.BadRequest("Задачу нельзя связать с самой собой")
+	}
+
+	if _, err := s.access.GetTask(ctx, source, req.CreatedBy); err != nil {
+		return nil, err
+	}
+	if _, err := s.access.GetTask(ctx, target, req.CreatedBy); err != nil {
+		return nil, err
+	}
+
+	link := domain.NewTaskLink(source, target, linkType, req.CreatedBy)
+	if err := s.repo.Create(ctx, link); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Связь задач создана", "link_id", link.ID, "source_task_id", source,
+		"target_task_id", target, "type", linkType)
+
+	return link, nil
+}
+
+func (s *TaskLinkService) GetTaskLinks(ctx context.Context, taskID, employeeID uuid.UUID) ([]*domain.TaskLink, error) {
+	if _, err := s.access.GetTask(ctx, taskID, employeeID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByTask(ctx, taskID, employeeID)
+}
+
+// DeleteLink удаляет связь; для этого нужен доступ к обеим задачам
+func (s *TaskLinkService) DeleteLink(ctx context.Context, id, employeeID uuid.UUID) error {
+	link, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.access.GetTask(ctx, link.SourceTaskID, employeeID); err != nil {
+		return errors.NotFound("Связь не найдена")
+	}
+	if _, err := s.access.GetTask(ctx, link.TargetTaskID, employeeID); err != nil {
+		return errors.NotFound("Связь не найдена")
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("Связь задач удалена", "link_id", id)
+
+	return nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/metrics"
 	"github.com/dmitry/taskmanager/internal/repository"
 	"github.com/dmitry/taskmanager/pkg/errors"
 	"github.com/dmitry/taskmanager/pkg/logger"
@@ -17,8 +19,12 @@ type TaskService struct {
 	participantRepo repository.TaskParticipantRepository
 	messageRepo     repository.MessageRepository
 	employeeRepo    repository.EmployeeRepository
+	projectRepo     repository.ProjectRepository
+	fieldRepo       repository.CustomFieldRepository
+	transitionRepo  repository.StatusTransitionRepository
+	access          *TaskAccess
+	opts            TaskOptions
 	db              *sql.DB
-	logger          *logger.Logger
 }
 
 func NewTaskService(
@@ -26,20 +32,48 @@ func NewTaskService(
 	participantRepo repository.TaskParticipantRepository,
 	messageRepo repository.MessageRepository,
 	employeeRepo repository.EmployeeRepository,
+	projectRepo repository.ProjectRepository,
+	fieldRepo repository.CustomFieldRepository,
+	transitionRepo repository.StatusTransitionRepository,
+	access *TaskAccess,
+	opts TaskOptions,
 	db *sql.DB,
-	logger *logger.Logger,
 ) *TaskService {
 	return &TaskService{
 		taskRepo:        taskRepo,
 		participantRepo: participantRepo,
 		messageRepo:     messageRepo,
 		employeeRepo:    employeeRepo,
+		projectRepo:     projectRepo,
+		fieldRepo:       fieldRepo,
+		transitionRepo:  transitionRepo,
+		access:          access,
+		opts:            opts,
 		db:              db,
-		logger:          logger,
 	}
 }
 
+// TaskOptions - настраиваемые правила работы с задачами
+type TaskOptions struct {
+	// MaxDepth - максимальное число уровней иерархии, включая корневую задачу
+	MaxDepth int
+	// CloseRequiresClosedSubtasks запрещает закрывать задачу с незакрытыми подзадачами
+	CloseRequiresClosedSubtasks bool
+	// BlockedTransition - реакция на перевод заблокированной задачи в работу
+	BlockedTransition string
+	// WIPLimits - максимальное число задач в колонке доски (в пределах проекта)
+	WIPLimits map[domain.TaskStatus]int
+}
+
+// Режимы TaskOptions.BlockedTransition
+const (
+	BlockedTransitionWarn = "warn"
+	BlockedTransitionFail = "fail"
+)
+
 type CreateTaskRequest struct {
+	ProjectID    *uuid.UUID
+	ParentID     *uuid.UUID
 	Title        string
 	Description  string
 	Priority     int
@@ -58,13 +92,69 @@ func (s *TaskService) CreateTask(ctx context.Context, req CreateTaskRequest) (*d
 		return nil, errors.BadRequest("Сотрудник-создатель не найден")
 	}
 
+	// Подзадача наследует проект родителя
+	if req.ParentID != nil {
+		parent, err := s.access.GetTask(ctx, *req.ParentID, req.CreatedBy)
+		if err != nil {
+			return nil, err
+		}
+		if req.ProjectID == nil {
+			req.ProjectID = parent.ProjectID
+		} else if !sameProject(req.ProjectID, parent.ProjectID) {
+			return nil, errors.BadRequest("Подзадача должна относиться к проекту родительской задачи")
+		}
+	}
+
+	var project *domain.Project
+	if req.ProjectID != nil {
+		var err error
+		if project, err = s.getMemberProject(ctx, *req.ProjectID, req.CreatedBy); err != nil {
+			return nil, err
+		}
+		for _, p := range req.Participants {
+			if err := s.ensureProjectMember(ctx, project.ID, p.EmployeeID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, errors.Internal(err, "Не удалось начать транзакцию")
 	}
 	defer tx.Rollback()
 
+	// Глубина проверяется под блокировкой иерархии, чтобы параллельный перенос родителя
+	// не вывел подзадачу за ограничение
+	if req.ParentID != nil {
+		if err := s.taskRepo.CheckParentDepthWithTx(ctx, tx, *req.ParentID, 1, s.opts.MaxDepth); err != nil {
+			return nil, err
+		}
+	}
+
 	task := domain.NewTask(req.Title, req.Description, req.Priority, req.CreatedBy, nil)
+	task.ParentID = req.ParentID
+
+	if project != nil {
+		number, err := s.projectRepo.NextTaskNumberWithTx(ctx, tx, project.ID)
+		if err != nil {
+			return nil, err
+		}
+		key := project.TaskKey(number)
+		task.ProjectID = &project.ID
+		task.Key = &key
+	}
+
+	// Новая задача встаёт в конец колонки доски
+	if err := s.taskRepo.LockBoardWithTx(ctx, tx); err != nil {
+		return nil, err
+	}
+	last, err := s.taskRepo.LastRankWithTx(ctx, tx, task.Status)
+	if err != nil {
+		return nil, err
+	}
+	rank := domain.RankBetween(last, "")
+	task.BoardRank = &rank
 
 	if err := s.taskRepo.CreateWithTx(ctx, tx, task); err != nil {
 		return nil, err
@@ -81,6 +171,10 @@ func (s *TaskService) CreateTask(ctx context.Context, req CreateTaskRequest) (*d
 		}
 	}
 
+	if err := s.transitionRepo.CreateWithTx(ctx, tx, domain.NewStatusTransition(task.ID, nil, task.Status, req.CreatedBy)); err != nil {
+		return nil, err
+	}
+
 	systemMsg := domain.NewSystemMessage(task.ID, "Задача создана")
 	if err := s.messageRepo.CreateWithTx(ctx, tx, systemMsg); err != nil {
 		return nil, err
@@ -90,80 +184,161 @@ func (s *TaskService) CreateTask(ctx context.Context, req CreateTaskRequest) (*d
 		return nil, errors.Internal(err, "Не удалось зафиксировать транзакцию")
 	}
 
-	s.logger.Info("Задача создана", "task_id", task.ID, "created_by", req.CreatedBy)
+	metrics.TasksCreated.Inc()
+	logger.FromContext(ctx).Info("Задача создана", "task_id", task.ID, "created_by", req.CreatedBy)
 
 	return task, nil
 }
 
-func (s *TaskService) GetTask(ctx context.Context, id uuid.UUID) (*domain.Task, error) {
-	return s.taskRepo.GetByID(ctx, id)
+// GetTask возвращает задачу, если она доступна сотруднику
+func (s *TaskService) GetTask(ctx context.Context, id, employeeID uuid.UUID) (*domain.Task, error) {
+	return s.access.GetTask(ctx, id, employeeID)
+}
+
+// GetStatusTransitions возвращает историю статусов задачи от создания
+func (s *TaskService) GetStatusTransitions(ctx context.Context, id, employeeID uuid.UUID) ([]*domain.StatusTransition, error) {
+	if _, err := s.access.GetTask(ctx, id, employeeID); err != nil {
+		return nil, err
+	}
+
+	return s.transitionRepo.GetByTask(ctx, id)
+}
+
+// GetTaskByKey возвращает задачу проекта по ключу вида CORE-123
+func (s *TaskService) GetTaskByKey(ctx context.Context, key string, employeeID uuid.UUID) (*domain.Task, error) {
+	task, err := s.taskRepo.GetByKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.access.Check(ctx, task, employeeID); err != nil {
+		return nil, err
+	}
+
+	return task, nil
 }
 
 func (s *TaskService) GetAllTasks(ctx context.Context, filter repository.TaskFilter) ([]*domain.Task, int, error) {
+	if err := resolveCustomFields(ctx, s.fieldRepo, &filter); err != nil {
+		return nil, 0, err
+	}
 	return s.taskRepo.GetAll(ctx, filter)
 }
 
 func (s *TaskService) GetTasksForEmployee(ctx context.Context, employeeID uuid.UUID, filter repository.TaskFilter) ([]*domain.Task, int, error) {
+	if err := resolveCustomFields(ctx, s.fieldRepo, &filter); err != nil {
+		return nil, 0, err
+	}
 	return s.taskRepo.GetTasksForEmployee(ctx, employeeID, filter)
 }
 
-func (s *TaskService) UpdateTask(ctx context.Context, task *domain.Task) error {
-	return s.taskRepo.Update(ctx, task)
+func (s *TaskService) GetAllTasksByCursor(ctx context.Context, filter repository.TaskFilter) ([]*domain.Task, string, error) {
+	if err := resolveCustomFields(ctx, s.fieldRepo, &filter); err != nil {
+		return nil, "", err
+	}
+	return s.taskRepo.GetAllByCursor(ctx, filter)
 }
 
-func (s *TaskService) DeleteTask(ctx context.Context, id uuid.UUID) error {
-	return s.taskRepo.Delete(ctx, id)
+func (s *TaskService) GetTasksForEmployeeByCursor(ctx context.Context, employeeID uuid.UUID, filter repository.TaskFilter) ([]*domain.Task, string, error) {
+	if err := resolveCustomFields(ctx, s.fieldRepo, &filter); err != nil {
+		return nil, "", err
+	}
+	return s.taskRepo.GetTasksForEmployeeByCursor(ctx, employeeID, filter)
 }
 
-func (s *TaskService) UpdateTaskStatus(ctx context.Context, taskID uuid.UUID, newStatus domain.TaskStatus) error {
-	if !newStatus.IsValid() {
-		return errors.BadRequest("Неверный статус задачи")
+func (s *TaskService) CountTasks(ctx context.Context, filter repository.TaskFilter) (int, error) {
+	if err := resolveCustomFields(ctx, s.fieldRepo, &filter); err != nil {
+		return 0, err
 	}
+	return s.taskRepo.Count(ctx, filter)
+}
 
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return errors.Internal(err, "Не удалось начать транзакцию")
+func (s *TaskService) CountTasksForEmployee(ctx context.Context, employeeID uuid.UUID, filter repository.TaskFilter) (int, error) {
+	if err := resolveCustomFields(ctx, s.fieldRepo, &filter); err != nil {
+		return 0, err
 	}
-	defer tx.Rollback()
+	filter.ParticipantID = &employeeID
+	return s.taskRepo.Count(ctx, filter)
+}
 
-	oldStatus, err := s.taskRepo.UpdateStatus(ctx, taskID, newStatus)
-	if err != nil {
-		return err
-	}
+func (s *TaskService) UpdateTask(ctx context.Context, task *domain.Task) error {
+	return s.taskRepo.Update(ctx, task)
+}
 
-	if oldStatus != newStatus {
-		content := fmt.Sprintf("Статус задачи изменён с '%s' на '%s'", oldStatus, newStatus)
-		systemMsg := domain.NewSystemMessage(taskID, content)
-		if err := s.messageRepo.CreateWithTx(ctx, tx, systemMsg); err != nil {
-			return err
+func (s *TaskService) DeleteTask(ctx context.Context, id uuid.UUID) error {
+	return s.taskRepo.Delete(ctx, id)
+}
+
+// UpdateTaskStatus меняет статус задачи и возвращает предупреждения, не помешавшие смене статуса.
+// Перевод заблокированной задачи в работу в зависимости от TaskOptions.BlockedTransition
+// либо запрещается, либо выполняется с предупреждением и системным сообщением.
+// Задача с новым статусом встаёт в конец колонки доски.
+func (s *TaskService) UpdateTaskStatus(ctx context.Context, taskID, employeeID uuid.UUID, newStatus domain.TaskStatus) ([]string, error) {
+	return s.MoveTask(ctx, MoveTaskRequest{
+		TaskID:     taskID,
+		EmployeeID: employeeID,
+		Status:     newStatus,
+	})
+}
+
+// checkStatusChange проверяет правила перехода задачи в статус newStatus и возвращает
+// предупреждения, которые переход не запрещают
+func (s *TaskService) checkStatusChange(ctx context.Context, task *domain.Task, newStatus domain.TaskStatus) ([]string, error) {
+	if newStatus == domain.TaskStatusClosed && s.opts.CloseRequiresClosedSubtasks {
+		rollup, err := s.taskRepo.GetRollup(ctx, task.ID)
+		if err != nil {
+			return nil, err
+		}
+		if open := rollup.OpenSubtasks(); open > 0 {
+			return nil, errors.Conflict(fmt.Sprintf("Нельзя закрыть задачу, пока не закрыты подзадачи (открыто: %d)", open))
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return errors.Internal(err, "Не удалось зафиксировать транзакцию")
+	var warnings []string
+	if newStatus == domain.TaskStatusInProgress && task.Status != newStatus && task.Blocked {
+		blockers, err := s.taskRepo.GetOpenBlockers(ctx, task.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(blockers) > 0 {
+			labels := make([]string, len(blockers))
+			for i, b := range blockers {
+				labels[i] = taskLabel(b)
+			}
+			message := "Задачу блокируют незакрытые задачи: " + strings.Join(labels, ", ")
+			if s.opts.BlockedTransition == BlockedTransitionFail {
+				return nil, errors.Conflict(message)
+			}
+			warnings = append(warnings, message)
+		}
 	}
 
-	s.logger.Info("Статус задачи обновлён", "task_id", taskID, "old_status", oldStatus, "new_status", newStatus)
-
-	return nil
+	return warnings, nil
 }
 
-func (s *TaskService) ArchiveTask(ctx context.Context, id uuid.UUID) error {
+func (s *TaskService) ArchiveTask(ctx context.Context, id, employeeID uuid.UUID) error {
+	if _, err := s.access.GetTask(ctx, id, employeeID); err != nil {
+		return err
+	}
+
 	if err := s.taskRepo.Archive(ctx, id); err != nil {
 		return err
 	}
 
-	s.logger.Info("Задача архивирована", "task_id", id)
+	logger.FromContext(ctx).Info("Задача архивирована", "task_id", id)
 
 	return nil
 }
 
-func (s *TaskService) AddParticipant(ctx context.Context, taskID, employeeID uuid.UUID, role domain.ParticipantRole) error {
+// AddParticipant назначает сотрудника на задачу от имени actorID.
+// Участником задачи проекта может быть только участник проекта.
+func (s *TaskService) AddParticipant(ctx context.Context, taskID, actorID, employeeID uuid.UUID, role domain.ParticipantRole) error {
 	if !role.IsValid() {
 		return errors.BadRequest("Неверная роль участника")
 	}
 
-	if _, err := s.taskRepo.GetByID(ctx, taskID); err != nil {
+	task, err := s.access.GetTask(ctx, taskID, actorID)
+	if err != nil {
 		return err
 	}
 
@@ -171,6 +346,12 @@ func (s *TaskService) AddParticipant(ctx context.Context, taskID, employeeID uui
 		return errors.BadRequest("Сотрудник не найден")
 	}
 
+	if task.ProjectID != nil {
+		if err := s.ensureProjectMember(ctx, *task.ProjectID, employeeID); err != nil {
+			return err
+		}
+	}
+
 	participant := domain.NewTaskParticipant(taskID, employeeID, role)
 	return s.participantRepo.AddParticipant(ctx, participant)
 }
@@ -179,6 +360,111 @@ func (s *TaskService) RemoveParticipant(ctx context.Context, taskID, employeeID
 	return s.participantRepo.RemoveParticipant(ctx, taskID, employeeID, role)
 }
 
-func (s *TaskService) GetParticipants(ctx context.Context, taskID uuid.UUID) ([]*domain.TaskParticipant, error) {
+func (s *TaskService) GetParticipants(ctx context.Context, taskID, employeeID uuid.UUID) ([]*domain.TaskParticipant, error) {
+	if _, err := s.access.GetTask(ctx, taskID, employeeID); err != nil {
+		return nil, err
+	}
+
 	return s.participantRepo.GetParticipants(ctx, taskID)
 }
+
+// GetSubtasks возвращает непосредственные подзадачи и сводку по всему поддереву задачи
+func (s *TaskService) GetSubtasks(ctx context.Context, taskID, employeeID uuid.UUID) ([]*domain.Task, *domain.TaskRollup, error) {
+	if _, err := s.access.GetTask(ctx, taskID, employeeID); err != nil {
+		return nil, nil, err
+	}
+
+	subtasks, err := s.taskRepo.GetSubtasks(ctx, taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rollup, err := s.taskRepo.GetRollup(ctx, taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return subtasks, rollup, nil
+}
+
+// SetParent делает задачу подзадачей parentID или, если parentID равен nil, корневой задачей.
+// Родитель должен быть из того же проекта, а поддерево задачи - уложиться в ограничение глубины.
+func (s *TaskService) SetParent(ctx context.Context, taskID uuid.UUID, parentID *uuid.UUID, employeeID uuid.UUID) error {
+	task, err := s.access.GetTask(ctx, taskID, employeeID)
+	if err != nil {
+		return err
+	}
+
+	content := "Задача стала корневой"
+	if parentID != nil {
+		if *parentID == taskID {
+			return errors.BadRequest("Задача не может быть подзадачей самой себя")
+		}
+
+		parent, err := s.access.GetTask(ctx, *parentID, employeeID)
+		if err != nil {
+			return err
+		}
+		if !sameProject(task.ProjectID, parent.ProjectID) {
+			return errors.BadRequest("Подзадача должна относиться к проекту родительской задачи")
+		}
+
+		content = fmt.Sprintf("Задача перенесена в подзадачи задачи '%s'", parent.Title)
+	}
+
+	if err := s.taskRepo.SetParent(ctx, taskID, parentID, s.opts.MaxDepth); err != nil {
+		return err
+	}
+
+	if err := s.messageRepo.Create(ctx, domain.NewSystemMessage(taskID, content)); err != nil {
+		logger.FromContext(ctx).Error("Не удалось создать системное сообщение", "task_id", taskID, "error", err)
+	}
+
+	logger.FromContext(ctx).Info("Родительская задача изменена", "task_id", taskID, "parent_id", parentID)
+
+	return nil
+}
+
+// taskLabel возвращает ключ задачи проекта или, если его нет, название
+func taskLabel(t *domain.Task) string {
+	if t.Key != nil {
+		return *t.Key
+	}
+	return "'" + t.Title + "'"
+}
+
+func sameProject(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// getMemberProject возвращает проект, если сотрудник в нём состоит
+func (s *TaskService) getMemberProject(ctx context.Context, projectID, employeeID uuid.UUID) (*domain.Project, error) {
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.projectRepo.IsMember(ctx, projectID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, errors.NotFound("Проект не найден")
+	}
+
+	return project, nil
+}
+
+func (s *TaskService) ensureProjectMember(ctx context.Context, projectID, employeeID uuid.UUID) error {
+	member, err := s.projectRepo.IsMember(ctx, projectID, employeeID)
+	if err != nil {
+		return err
+	}
+	if !member {
+		return errors.BadRequest(fmt.Sprintf("Сотрудник %s не состоит в проекте задачи", employeeID))
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// TaskViewService управляет сохранёнными представлениями списка задач.
+// Запрос представления проверяется и нормализуется на уровне обработчика.
+type TaskViewService struct {
+	repo         repository.TaskViewRepository
+	employeeRepo repository.EmployeeRepository
+}
+
+func NewTaskViewService(repo repository.TaskViewRepository, employeeRepo repository.EmployeeRepository) *TaskViewService {
+	return &TaskViewService{
+		repo:         repo,
+		employeeRepo: employeeRepo,
+	}
+}
+
+type SaveTaskViewRequest struct {
+	Name             string
+	Query            string
+	SharedDepartment *string
+}
+
+func (s *TaskViewService) CreateView(ctx context.Context, ownerID uuid.UUID, req SaveTaskViewRequest) (*domain.TaskView, error) {
+	view := domain.NewTaskView(ownerID, req.Name, req.Query, req.SharedDepartment)
+
+	if err := s.repo.Create(ctx, view); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Представление создано", "view_id", view.ID, "owner_id", ownerID)
+
+	return view, nil
+}
+
+// GetView возвращает представление, если оно принадлежит сотруднику или открыто его отделу
+func (s *TaskViewService) GetView(ctx context.Context, id, employeeID uuid.UUID) (*domain.TaskView, error) {
+	view, err := s.repo.GetByID(ctx, id, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	department, err := s.departmentOf(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !view.IsVisibleTo(employeeID, department) {
+		return nil, errors.NotFound("Представление не найдено")
+	}
+
+	return view, nil
+}
+
+// GetViews возвращает собственные представления сотрудника и открытые его отделу
+func (s *TaskViewService) GetViews(ctx context.Context, employeeID uuid.UUID) ([]*domain.TaskView, error) {
+	department, err := s.departmentOf(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetVisible(ctx, employeeID, department)
+}
+
+// GetDefaultView возвращает закреплённое представление. Если доступ к нему утрачен
+// (например, сотрудник сменил отдел), оно считается не выбранным.
+func (s *TaskViewService) GetDefaultView(ctx context.Context, employeeID uuid.UUID) (*domain.TaskView, error) {
+	view, err := s.repo.GetDefault(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	department, err := s.departmentOf(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !view.IsVisibleTo(employeeID, department) {
+		return nil, errors.NotFound("Представление по умолчанию не выбрано")
+	}
+
+	return view, nil
+}
+
+// UpdateView изменяет представление; изменять может только владелец
+func (s *TaskViewService) UpdateView(ctx context.Context, id, editorID uuid.UUID, req SaveTaskViewRequest) (*domain.TaskView, error) {
+	view, err := s.GetView(ctx, id, editorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if view.OwnerID != editorID {
+		return nil, errors.Forbidden("Изменять представление может только его владелец")
+	}
+
+	view.Name = req.Name
+	view.Query = req.Query
+	view.SharedDepartment = req.SharedDepartment
+
+	if err := s.repo.Update(ctx, view); err != nil {
+		return nil, err
+	}
+
+	return view, nil
+}
+
+// DeleteView удаляет представление; удалить может только владелец
+func (s *TaskViewService) DeleteView(ctx context.Context, id, editorID uuid.UUID) error {
+	view, err := s.GetView(ctx, id, editorID)
+	if err != nil {
+		return err
+	}
+
+	if view.OwnerID != editorID {
+		return errors.Forbidden("Удалить представление может только его владелец")
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("Представление удалено", "view_id", id)
+
+	return nil
+}
+
+// SetDefaultView закрепляет доступное сотруднику представление как представление по умолчанию
+func (s *TaskViewService) SetDefaultView(ctx context.Context, id, employeeID uuid.UUID) error {
+	if _, err := s.GetView(ctx, id, employeeID); err != nil {
+		return err
+	}
+
+	return s.repo.SetDefault(ctx, employeeID, id)
+}
+
+func (s *TaskViewService) ClearDefaultView(ctx context.Context, employeeID uuid.UUID) error {
+	return s.repo.ClearDefault(ctx, employeeID)
+}
+
+func (s *TaskViewService) departmentOf(ctx context.Context, employeeID uuid.UUID) (string, error) {
+	employee, err := s.employeeRepo.GetByID(ctx, employeeID)
+	if err != nil {
+		return "", err
+	}
+	return employee.Department, nil
+}
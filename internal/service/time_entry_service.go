@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"math"
+	"slices"
 	"time"
 
 	"github.com/dmitry/taskmanager/internal/domain"
@@ -12,16 +14,24 @@ import (
 )
 
 type TimeEntryService struct {
-	repo     repository.TimeEntryRepository
-	taskRepo repository.TaskRepository
-	logger   *logger.Logger
+	repo         repository.TimeEntryRepository
+	taskRepo     repository.TaskRepository
+	employeeRepo repository.EmployeeRepository
+	// reportDepartments - отделы, которым доступны табели всех сотрудников
+	reportDepartments []string
 }
 
-func NewTimeEntryService(repo repository.TimeEntryRepository, taskRepo repository.TaskRepository, logger *logger.Logger) *TimeEntryService {
+func NewTimeEntryService(
+	repo repository.TimeEntryRepository,
+	taskRepo repository.TaskRepository,
+	employeeRepo repository.EmployeeRepository,
+	reportDepartments []string,
+) *TimeEntryService {
 	return &TimeEntryService{
-		repo:     repo,
-		taskRepo: taskRepo,
-		logger:   logger,
+		repo:              repo,
+		taskRepo:          taskRepo,
+		employeeRepo:      employeeRepo,
+		reportDepartments: reportDepartments,
 	}
 }
 
@@ -45,7 +55,7 @@ func (s *TimeEntryService) CreateTimeEntry(ctx context.Context, taskID, employee
 		return nil, err
 	}
 
-	s.logger.Info("Запись времени создана", "entry_id", entry.ID, "task_id", taskID, "hours", hours)
+	logger.FromContext(ctx).Info("Запись времени создана", "entry_id", entry.ID, "task_id", taskID, "hours", hours)
 
 	return entry, nil
 }
@@ -77,3 +87,80 @@ func (s *TimeEntryService) UpdateTimeEntry(ctx context.Context, entry *domain.Ti
 func (s *TimeEntryService) DeleteTimeEntry(ctx context.Context, id uuid.UUID) error {
 	return s.repo.Delete(ctx, id)
 }
+
+// TimesheetRequest - параметры табеля. Без EmployeeID и Department табель строится по всем
+// сотрудникам; период по умолчанию - текущий месяц.
+type TimesheetRequest struct {
+	EmployeeID *uuid.UUID
+	Department *string
+	Period     AnalyticsPeriod
+}
+
+// TimesheetWriter получает табель построчно. Строки одного сотрудника идут подряд, после них -
+// итог сотрудника; последним вызывается Total, даже если записей нет.
+type TimesheetWriter interface {
+	Row(row *domain.TimesheetRow) error
+	EmployeeTotal(employeeName, department string, hours float64) error
+	Total(hours float64) error
+}
+
+// Timesheet строит табель часов по задачам и дням и передаёт его в w, не загружая целиком в память.
+// Свой табель доступен каждому, табели других сотрудников - только отделам из reportDepartments.
+// Ошибка доступа или параметров возвращается до первой записи в w.
+func (s *TimeEntryService) Timesheet(ctx context.Context, viewerID uuid.UUID, req TimesheetRequest, w TimesheetWriter) error {
+	if req.EmployeeID == nil || *req.EmployeeID != viewerID {
+		viewer, err := s.employeeRepo.GetByID(ctx, viewerID)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(s.reportDepartments, viewer.Department) {
+			return errors.Forbidden("Табели других сотрудников доступны только отделам, которым разрешены отчёты")
+		}
+	}
+
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+	from, to, err := resolvePeriod(req.Period, &monthStart, &monthEnd)
+	if err != nil {
+		return err
+	}
+
+	filter := repository.TimesheetFilter{
+		EmployeeID: req.EmployeeID,
+		Department: req.Department,
+		From:       from,
+		To:         to,
+	}
+
+	var current *domain.TimesheetRow
+	var employeeHours, totalHours float64
+	err = s.repo.StreamTimesheet(ctx, filter, func(row *domain.TimesheetRow) error {
+		if current != nil && current.EmployeeID != row.EmployeeID {
+			if err := w.EmployeeTotal(current.EmployeeName, current.Department, roundHours(employeeHours)); err != nil {
+				return err
+			}
+			employeeHours = 0
+		}
+		current = row
+		employeeHours += row.Hours
+		totalHours += row.Hours
+		return w.Row(row)
+	})
+	if err != nil {
+		return err
+	}
+
+	if current != nil {
+		if err := w.EmployeeTotal(current.EmployeeName, current.Department, roundHours(employeeHours)); err != nil {
+			return err
+		}
+	}
+
+	return w.Total(roundHours(totalHours))
+}
+
+// roundHours убирает погрешность суммирования: часы хранятся с точностью до сотых
+func roundHours(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
@@ -0,0 +1,742 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dmitry/taskmanager/internal/domain"
+	"github.com/dmitry/taskmanager/internal/repository"
+	"github.com/dmitry/taskmanager/pkg/errors"
+	"github.com/dmitry/taskmanager/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultImportBatchSize = 500
+	// maxImportIssues ограничивает число проблем в отчёте; остальные только подсчитываются
+	maxImportIssues = 1000
+)
+
+// WorkspaceService выгружает сотрудников, проекты, задачи, участников, сообщения и записи
+// времени в архив JSON Lines и загружает такой архив обратно
+type WorkspaceService struct {
+	repo           repository.WorkspaceRepository
+	transitionRepo repository.StatusTransitionRepository
+	db             *sql.DB
+}
+
+func NewWorkspaceService(repo repository.WorkspaceRepository, transitionRepo repository.StatusTransitionRepository, db *sql.DB) *WorkspaceService {
+	return &WorkspaceService{
+		repo:           repo,
+		transitionRepo: transitionRepo,
+		db:             db,
+	}
+}
+
+// ExportOptions - параметры выгрузки
+type ExportOptions struct {
+	// WithPasswordHashes - выгрузить хеши паролей, чтобы сотрудники могли войти после переноса
+	WithPasswordHashes bool
+}
+
+// archiveLine - строка архива: тип записи и сама запись
+type archiveLine struct {
+	Type domain.ArchiveRecordType `json:"type"`
+	Data interface{}              `json:"data"`
+}
+
+// Export пишет архив в w построчно. Все таблицы читаются из одного снимка базы данных,
+// поэтому ссылки между записями архива согласованы. Возвращает число записей по типам.
+func (s *WorkspaceService) Export(ctx context.Context, w io.Writer, opts ExportOptions) (map[domain.ArchiveRecordType]int, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось начать транзакцию")
+	}
+	defer tx.Rollback()
+
+	buf := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buf)
+	counts := map[domain.ArchiveRecordType]int{}
+
+	write := func(recordType domain.ArchiveRecordType, data interface{}) error {
+		if err := encoder.Encode(archiveLine{Type: recordType, Data: data}); err != nil {
+			return errors.Internal(err, "Не удалось записать архив")
+		}
+		return nil
+	}
+	record := func(recordType domain.ArchiveRecordType, data interface{}) error {
+		counts[recordType]++
+		return write(recordType, data)
+	}
+
+	header := domain.ArchiveHeader{
+		Format:     domain.ArchiveFormat,
+		Version:    domain.ArchiveVersion,
+		ExportedAt: time.Now().UTC(),
+	}
+	if err := write(domain.RecordHeader, header); err != nil {
+		return nil, err
+	}
+
+	err = s.repo.StreamEmployeesWithTx(ctx, tx, opts.WithPasswordHashes, func(e *domain.ArchivedEmployee) error {
+		return record(domain.RecordEmployee, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.StreamProjectsWithTx(ctx, tx, func(p *domain.ArchivedProject) error {
+		return record(domain.RecordProject, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.StreamTasksWithTx(ctx, tx, func(t *domain.ArchivedTask) error {
+		return record(domain.RecordTask, t)
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.StreamParticipantsWithTx(ctx, tx, func(p *domain.TaskParticipant) error {
+		return record(domain.RecordParticipant, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.StreamMessagesWithTx(ctx, tx, func(m *domain.TaskMessage) error {
+		return record(domain.RecordMessage, m)
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.StreamTimeEntriesWithTx(ctx, tx, func(e *domain.TimeEntry) error {
+		return record(domain.RecordTimeEntry, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := write(domain.RecordFooter, domain.ArchiveFooter{Counts: counts}); err != nil {
+		return nil, err
+	}
+	if err := buf.Flush(); err != nil {
+		return nil, errors.Internal(err, "Не удалось записать архив")
+	}
+
+	logger.FromContext(ctx).Info("Рабочее пространство выгружено", "counts", counts)
+
+	return counts, nil
+}
+
+// ImportOptions - параметры загрузки архива
+type ImportOptions struct {
+	// Remap - выдать всем записям новые идентификаторы, например для копии данных в том же
+	// окружении. Без Remap идентификаторы сохраняются, и запись с уже существующим
+	// идентификатором считается загруженной ранее.
+	Remap bool
+	// DryRun - проверить архив и ссылки, ничего не сохраняя
+	DryRun bool
+	// BatchSize - число записей в одной транзакции
+	BatchSize int
+}
+
+// Виды проблем импорта
+const (
+	// ImportIssueExists - запись с таким идентификатором уже есть, используется она
+	ImportIssueExists = "exists"
+	// ImportIssueMatched - сотрудник сопоставлен существующему по адресу почты
+	ImportIssueMatched = "matched"
+	// ImportIssueConflict - запись нарушает уникальность (ключ проекта или задачи) и пропущена
+	ImportIssueConflict = "conflict"
+	// ImportIssueInvalid - запись с недопустимым значением пропущена
+	ImportIssueInvalid = "invalid"
+	// ImportIssueMissingReference - запись ссылается на отсутствующую запись и пропущена
+	ImportIssueMissingReference = "missing_reference"
+	// ImportIssueDroppedReference - необязательная ссылка не найдена, запись загружена без неё
+	ImportIssueDroppedReference = "dropped_reference"
+)
+
+// ImportIssue - проблема с записью архива
+type ImportIssue struct {
+	Kind    string                   `json:"kind"`
+	Type    domain.ArchiveRecordType `json:"type"`
+	ID      uuid.UUID                `json:"id"`
+	Message string                   `json:"message"`
+}
+
+// ImportReport - результат загрузки архива
+type ImportReport struct {
+	DryRun bool `json:"dry_run"`
+	Remap  bool `json:"remap"`
+	// Imported - загруженные записи (при DryRun - которые были бы загружены)
+	Imported map[domain.ArchiveRecordType]int `json:"imported"`
+	// Matched - записи, сопоставленные существующим: по идентификатору или адресу почты
+	Matched map[domain.ArchiveRecordType]int `json:"matched"`
+	// Skipped - пропущенные записи; ссылки на них тоже не загружаются
+	Skipped map[domain.ArchiveRecordType]int `json:"skipped"`
+	// Issues - первые maxImportIssues проблем, IssuesTotal - их общее число
+	Issues      []ImportIssue `json:"issues"`
+	IssuesTotal int           `json:"issues_total"`
+}
+
+func (r *ImportReport) issue(kind string, recordType domain.ArchiveRecordType, id uuid.UUID, message string) {
+	r.IssuesTotal++
+	if len(r.Issues) < maxImportIssues {
+		r.Issues = append(r.Issues, ImportIssue{Kind: kind, Type: recordType, ID: id, Message: message})
+	}
+}
+
+// Import загружает архив из r. Записи загружаются транзакциями по BatchSize записей;
+// ссылки проверяются по уже загруженным записям архива и, если идентификаторы сохраняются,
+// по существующим данным. Запись с отсутствующей обязательной ссылкой пропускается
+// и попадает в отчёт. При ошибке возвращается отчёт о записях, зафиксированных до неё.
+func (s *WorkspaceService) Import(ctx context.Context, r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultImportBatchSize
+	}
+
+	im := &workspaceImport{
+		repo:           s.repo,
+		transitionRepo: s.transitionRepo,
+		db:             s.db,
+		opts:           opts,
+		report: &ImportReport{
+			DryRun:   opts.DryRun,
+			Remap:    opts.Remap,
+			Imported: map[domain.ArchiveRecordType]int{},
+			Matched:  map[domain.ArchiveRecordType]int{},
+			Skipped:  map[domain.ArchiveRecordType]int{},
+			Issues:   []ImportIssue{},
+		},
+		ids:      map[domain.ArchiveRecordType]map[uuid.UUID]uuid.UUID{},
+		existing: map[domain.ArchiveRecordType]map[uuid.UUID]bool{},
+	}
+
+	err := im.run(ctx, r)
+	if im.dryRunTx != nil {
+		im.dryRunTx.Rollback()
+	}
+	if err != nil {
+		return im.report, err
+	}
+
+	logger.FromContext(ctx).Info("Рабочее пространство загружено",
+		"dry_run", opts.DryRun, "remap", opts.Remap,
+		"imported", im.report.Imported, "matched", im.report.Matched, "skipped", im.report.Skipped)
+
+	return im.report, nil
+}
+
+// workspaceImport хранит состояние одной загрузки
+type workspaceImport struct {
+	repo           repository.WorkspaceRepository
+	transitionRepo repository.StatusTransitionRepository
+	db             *sql.DB
+	opts           ImportOptions
+	report         *ImportReport
+
+	// ids - идентификаторы в базе для записей архива: новые, сохранённые или существующих
+	// записей, с которыми запись сопоставлена. Пропущенных записей здесь нет.
+	ids map[domain.ArchiveRecordType]map[uuid.UUID]uuid.UUID
+	// existing - кэш проверки существующих записей (только при сохранении идентификаторов)
+	existing map[domain.ArchiveRecordType]map[uuid.UUID]bool
+
+	batch     []interface{}
+	batchType domain.ArchiveRecordType
+	counts    map[domain.ArchiveRecordType]int
+
+	// dryRunTx - единственная транзакция пробного запуска: записи прежних пакетов должны быть
+	// видны следующим, поэтому она откатывается только в конце загрузки
+	dryRunTx *sql.Tx
+}
+
+// archiveInputLine - строка архива при чтении
+type archiveInputLine struct {
+	Type domain.ArchiveRecordType `json:"type"`
+	Data json.RawMessage          `json:"data"`
+}
+
+func (im *workspaceImport) run(ctx context.Context, r io.Reader) error {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	im.counts = map[domain.ArchiveRecordType]int{}
+
+	var header domain.ArchiveHeader
+	if err := decodeArchiveLine(decoder, 1, domain.RecordHeader, &header); err != nil {
+		return err
+	}
+	if header.Format != domain.ArchiveFormat {
+		return errors.BadRequest("Неверный архив: ожидается формат " + domain.ArchiveFormat)
+	}
+	if header.Version < 1 || header.Version > domain.ArchiveVersion {
+		return errors.BadRequest(fmt.Sprintf("Неподдерживаемая версия архива %d, поддерживается до %d", header.Version, domain.ArchiveVersion))
+	}
+
+	position := 0
+	for lineNumber := 2; ; lineNumber++ {
+		var line archiveInputLine
+		if err := decoder.Decode(&line); err != nil {
+			if err == io.EOF {
+				return errors.BadRequest("Архив оборван: нет завершающей строки footer")
+			}
+			return errors.BadRequest(fmt.Sprintf("Неверный архив: строка %d: %v", lineNumber, err))
+		}
+
+		if line.Type == domain.RecordFooter {
+			if err := im.flush(ctx); err != nil {
+				return err
+			}
+			return im.checkFooter(decoder, line.Data, lineNumber)
+		}
+
+		index := archiveTypeIndex(line.Type)
+		if index < 0 {
+			return errors.BadRequest(fmt.Sprintf("Неверный архив: строка %d: неизвестный тип записи %q", lineNumber, line.Type))
+		}
+		if index < position {
+			return errors.BadRequest(fmt.Sprintf("Неверный архив: строка %d: запись %s после записей %s", lineNumber, line.Type, domain.ArchiveRecordOrder[position]))
+		}
+		position = index
+
+		record, err := decodeArchiveRecord(line)
+		if err != nil {
+			return errors.BadRequest(fmt.Sprintf("Неверный архив: строка %d: %v", lineNumber, err))
+		}
+		im.counts[line.Type]++
+
+		if line.Type != im.batchType || len(im.batch) >= im.opts.BatchSize {
+			if err := im.flush(ctx); err != nil {
+				return err
+			}
+			im.batchType = line.Type
+		}
+		im.batch = append(im.batch, record)
+	}
+}
+
+func decodeArchiveLine(decoder *json.Decoder, lineNumber int, expected domain.ArchiveRecordType, dest interface{}) error {
+	var line archiveInputLine
+	if err := decoder.Decode(&line); err != nil {
+		return errors.BadRequest(fmt.Sprintf("Неверный архив: строка %d: %v", lineNumber, err))
+	}
+	if line.Type != expected {
+		return errors.BadRequest(fmt.Sprintf("Неверный архив: строка %d: ожидается запись %s", lineNumber, expected))
+	}
+	if err := json.Unmarshal(line.Data, dest); err != nil {
+		return errors.BadRequest(fmt.Sprintf("Неверный архив: строка %d: %v", lineNumber, err))
+	}
+	return nil
+}
+
+func decodeArchiveRecord(line archiveInputLine) (interface{}, error) {
+	var record interface{}
+	switch line.Type {
+	case domain.RecordEmployee:
+		record = &domain.ArchivedEmployee{}
+	case domain.RecordProject:
+		record = &domain.ArchivedProject{}
+	case domain.RecordTask:
+		record = &domain.ArchivedTask{}
+	case domain.RecordParticipant:
+		record = &domain.TaskParticipant{}
+	case domain.RecordMessage:
+		record = &domain.TaskMessage{}
+	case domain.RecordTimeEntry:
+		record = &domain.TimeEntry{}
+	}
+	if err := json.Unmarshal(line.Data, record); err != nil {
+		return nil, err
+	}
+	if recordID(record) == uuid.Nil {
+		return nil, fmt.Errorf("запись %s без идентификатора", line.Type)
+	}
+	return record, nil
+}
+
+func archiveTypeIndex(recordType domain.ArchiveRecordType) int {
+	for i, t := range domain.ArchiveRecordOrder {
+		if t == recordType {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkFooter сверяет число записей с завершающей строкой; после неё данных быть не должно
+func (im *workspaceImport) checkFooter(decoder *json.Decoder, data json.RawMessage, lineNumber int) error {
+	var footer domain.ArchiveFooter
+	if err := json.Unmarshal(data, &footer); err != nil {
+		return errors.BadRequest(fmt.Sprintf("Неверный архив: строка %d: %v", lineNumber, err))
+	}
+	for _, recordType := range domain.ArchiveRecordOrder {
+		if footer.Counts[recordType] != im.counts[recordType] {
+			return errors.BadRequest(fmt.Sprintf("Архив повреждён: записей %s %d, в footer указано %d",
+				recordType, im.counts[recordType], footer.Counts[recordType]))
+		}
+	}
+	if decoder.More() {
+		return errors.BadRequest(fmt.Sprintf("Неверный архив: данные после строки footer (строка %d)", lineNumber))
+	}
+	return nil
+}
+
+// importLookups - проверки по базе для одного пакета записей
+type importLookups struct {
+	ownExisting map[uuid.UUID]bool
+	emails      map[string]uuid.UUID
+	keys        map[string]bool
+}
+
+// flush загружает накопленный пакет записей одного типа в отдельной транзакции.
+// При DryRun все пакеты выполняются в общей транзакции, которая затем откатывается.
+func (im *workspaceImport) flush(ctx context.Context) error {
+	if len(im.batch) == 0 {
+		return nil
+	}
+	batch, recordType := im.batch, im.batchType
+	im.batch = nil
+
+	tx, err := im.begin(ctx)
+	if err != nil {
+		return err
+	}
+	if !im.opts.DryRun {
+		defer tx.Rollback()
+	}
+
+	lookups, err := im.lookup(ctx, tx, recordType, batch)
+	if err != nil {
+		return err
+	}
+
+	// Счётчики пакета применяются к отчёту только после фиксации
+	imported := 0
+	for _, record := range batch {
+		ok, err := im.importRecord(ctx, tx, recordType, record, lookups)
+		if err != nil {
+			return err
+		}
+		if ok {
+			imported++
+		}
+	}
+
+	if !im.opts.DryRun {
+		if err := tx.Commit(); err != nil {
+			return errors.Internal(err, "Не удалось зафиксировать транзакцию")
+		}
+	}
+	if imported > 0 {
+		im.report.Imported[recordType] += imported
+	}
+
+	return nil
+}
+
+func (im *workspaceImport) begin(ctx context.Context) (*sql.Tx, error) {
+	if im.dryRunTx != nil {
+		return im.dryRunTx, nil
+	}
+
+	tx, err := im.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.Internal(err, "Не удалось начать транзакцию")
+	}
+	if im.opts.DryRun {
+		im.dryRunTx = tx
+	}
+	return tx, nil
+}
+
+// lookup одним запросом на тип проверяет идентификаторы пакета, ссылки на записи вне архива
+// и уникальные поля
+func (im *workspaceImport) lookup(ctx context.Context, tx *sql.Tx, recordType domain.ArchiveRecordType, batch []interface{}) (*importLookups, error) {
+	lookups := &importLookups{}
+	var err error
+
+	if !im.opts.Remap {
+		ids := make([]uuid.UUID, len(batch))
+		for i, record := range batch {
+			ids[i] = recordID(record)
+		}
+		if lookups.ownExisting, err = im.repo.ExistingIDsWithTx(ctx, tx, recordType, ids); err != nil {
+			return nil, err
+		}
+
+		unknown := map[domain.ArchiveRecordType][]uuid.UUID{}
+		for _, record := range batch {
+			for _, ref := range recordRefs(record) {
+				if _, ok := im.ids[ref.recordType][ref.id]; ok {
+					continue
+				}
+				if _, ok := im.existing[ref.recordType][ref.id]; ok {
+					continue
+				}
+				unknown[ref.recordType] = append(unknown[ref.recordType], ref.id)
+			}
+		}
+		for refType, refIDs := range unknown {
+			found, err := im.repo.ExistingIDsWithTx(ctx, tx, refType, refIDs)
+			if err != nil {
+				return nil, err
+			}
+			if im.existing[refType] == nil {
+				im.existing[refType] = map[uuid.UUID]bool{}
+			}
+			for _, id := range refIDs {
+				im.existing[refType][id] = found[id]
+			}
+		}
+	}
+
+	switch recordType {
+	case domain.RecordEmployee:
+		emails := make([]string, 0, len(batch))
+		for _, record := range batch {
+			emails = append(emails, record.(*domain.ArchivedEmployee).Email)
+		}
+		lookups.emails, err = im.repo.EmployeeIDsByEmailWithTx(ctx, tx, emails)
+	case domain.RecordProject:
+		keys := make([]string, 0, len(batch))
+		for _, record := range batch {
+			keys = append(keys, record.(*domain.ArchivedProject).Key)
+		}
+		lookups.keys, err = im.repo.ExistingProjectKeysWithTx(ctx, tx, keys)
+	case domain.RecordTask:
+		keys := make([]string, 0, len(batch))
+		for _, record := range batch {
+			if key := record.(*domain.ArchivedTask).Key; key != nil {
+				keys = append(keys, *key)
+			}
+		}
+		lookups.keys, err = im.repo.ExistingTaskKeysWithTx(ctx, tx, keys)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return lookups, nil
+}
+
+// resolve возвращает идентификатор в базе для ссылки архива
+func (im *workspaceImport) resolve(recordType domain.ArchiveRecordType, id uuid.UUID) (uuid.UUID, bool) {
+	if mapped, ok := im.ids[recordType][id]; ok {
+		return mapped, true
+	}
+	if !im.opts.Remap && im.existing[recordType][id] {
+		return id, true
+	}
+	return uuid.Nil, false
+}
+
+func (im *workspaceImport) remember(recordType domain.ArchiveRecordType, archiveID, id uuid.UUID) {
+	if im.ids[recordType] == nil {
+		im.ids[recordType] = map[uuid.UUID]uuid.UUID{}
+	}
+	im.ids[recordType][archiveID] = id
+}
+
+func (im *workspaceImport) skip(kind string, recordType domain.ArchiveRecordType, id uuid.UUID, message string) {
+	im.report.Skipped[recordType]++
+	im.report.issue(kind, recordType, id, message)
+}
+
+// importRecord проверяет запись, заменяет ссылки идентификаторами в базе и сохраняет её.
+// Возвращает true, если запись загружена.
+func (im *workspaceImport) importRecord(ctx context.Context, tx *sql.Tx, recordType domain.ArchiveRecordType, record interface{}, lookups *importLookups) (bool, error) {
+	archiveID := recordID(record)
+
+	if lookups.ownExisting[archiveID] {
+		im.remember(recordType, archiveID, archiveID)
+		im.report.Matched[recordType]++
+		im.report.issue(ImportIssueExists, recordType, archiveID, "Запись уже существует и не изменялась")
+		return false, nil
+	}
+
+	for _, ref := range recordRefs(record) {
+		if _, ok := im.resolve(ref.recordType, ref.id); !ok && ref.required {
+			im.skip(ImportIssueMissingReference, recordType, archiveID,
+				fmt.Sprintf("Не найдена запись %s %s (%s)", ref.recordType, ref.id, ref.field))
+			return false, nil
+		}
+	}
+
+	id := archiveID
+	if im.opts.Remap {
+		id = uuid.New()
+	}
+	ref := func(refType domain.ArchiveRecordType, archiveRef uuid.UUID) uuid.UUID {
+		resolved, _ := im.resolve(refType, archiveRef)
+		return resolved
+	}
+	optionalRef := func(refType domain.ArchiveRecordType, archiveRef *uuid.UUID, field string) *uuid.UUID {
+		if archiveRef == nil {
+			return nil
+		}
+		resolved, ok := im.resolve(refType, *archiveRef)
+		if !ok {
+			im.report.issue(ImportIssueDroppedReference, recordType, archiveID,
+				fmt.Sprintf("Не найдена запись %s %s (%s), ссылка не загружена", refType, *archiveRef, field))
+			return nil
+		}
+		return &resolved
+	}
+
+	var err error
+	switch r := record.(type) {
+	case *domain.ArchivedEmployee:
+		if existingID, ok := lookups.emails[r.Email]; ok {
+			im.remember(recordType, archiveID, existingID)
+			im.report.Matched[recordType]++
+			im.report.issue(ImportIssueMatched, recordType, archiveID, "Сотрудник сопоставлен существующему с тем же email")
+			return false, nil
+		}
+		if r.Email == "" || r.Name == "" {
+			im.skip(ImportIssueInvalid, recordType, archiveID, "Не заданы имя или email сотрудника")
+			return false, nil
+		}
+		employee := *r
+		employee.ID = id
+		err = im.repo.InsertEmployeeWithTx(ctx, tx, &employee)
+
+	case *domain.ArchivedProject:
+		if lookups.keys[r.Key] {
+			im.skip(ImportIssueConflict, recordType, archiveID, "Проект с ключом "+r.Key+" уже существует")
+			return false, nil
+		}
+		project := *r
+		project.ID = id
+		project.OwnerID = ref(domain.RecordEmployee, r.OwnerID)
+		project.Members = make([]uuid.UUID, 0, len(r.Members))
+		for _, member := range r.Members {
+			if resolved := optionalRef(domain.RecordEmployee, &member, "members"); resolved != nil {
+				project.Members = append(project.Members, *resolved)
+			}
+		}
+		err = im.repo.InsertProjectWithTx(ctx, tx, &project)
+
+	case *domain.ArchivedTask:
+		if !r.Status.IsValid() {
+			im.skip(ImportIssueInvalid, recordType, archiveID, "Неизвестный статус задачи "+string(r.Status))
+			return false, nil
+		}
+		if r.Key != nil && lookups.keys[*r.Key] {
+			im.skip(ImportIssueConflict, recordType, archiveID, "Задача с ключом "+*r.Key+" уже существует")
+			return false, nil
+		}
+		task := *r
+		task.ID = id
+		task.CreatedBy = ref(domain.RecordEmployee, r.CreatedBy)
+		task.ProjectID = optionalRef(domain.RecordProject, r.ProjectID, "project_id")
+		task.ParentID = optionalRef(domain.RecordTask, r.ParentID, "parent_id")
+		if task.ProjectID == nil {
+			task.Key = nil
+		}
+		if err = im.repo.InsertTaskWithTx(ctx, tx, &task); err != nil {
+			break
+		}
+		// История статусов в архив не входит; без начального перехода задача не попала бы
+		// в аналитику и отчёты спринтов
+		transition := domain.NewStatusTransition(task.ID, nil, task.Status, task.CreatedBy)
+		transition.ChangedAt = task.CreatedAt
+		err = im.transitionRepo.CreateWithTx(ctx, tx, transition)
+
+	case *domain.TaskParticipant:
+		if !r.Role.IsValid() {
+			im.skip(ImportIssueInvalid, recordType, archiveID, "Неизвестная роль участника "+string(r.Role))
+			return false, nil
+		}
+		participant := *r
+		participant.ID = id
+		participant.TaskID = ref(domain.RecordTask, r.TaskID)
+		participant.EmployeeID = ref(domain.RecordEmployee, r.EmployeeID)
+		err = im.repo.InsertParticipantWithTx(ctx, tx, &participant)
+
+	case *domain.TaskMessage:
+		message := *r
+		message.ID = id
+		message.TaskID = ref(domain.RecordTask, r.TaskID)
+		message.AuthorID = optionalRef(domain.RecordEmployee, r.AuthorID, "author_id")
+		err = im.repo.InsertMessageWithTx(ctx, tx, &message)
+
+	case *domain.TimeEntry:
+		if r.Hours <= 0 {
+			im.skip(ImportIssueInvalid, recordType, archiveID, "Число часов должно быть больше нуля")
+			return false, nil
+		}
+		entry := *r
+		entry.ID = id
+		entry.TaskID = ref(domain.RecordTask, r.TaskID)
+		entry.EmployeeID = ref(domain.RecordEmployee, r.EmployeeID)
+		err = im.repo.InsertTimeEntryWithTx(ctx, tx, &entry)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	im.remember(recordType, archiveID, id)
+	return true, nil
+}
+
+// archiveRef - ссылка записи архива на другую запись
+type archiveRef struct {
+	recordType domain.ArchiveRecordType
+	id         uuid.UUID
+	field      string
+	required   bool
+}
+
+// recordRefs перечисляет ссылки записи; необязательные ссылки без значения не включаются
+func recordRefs(record interface{}) []archiveRef {
+	refs := []archiveRef{}
+	optional := func(recordType domain.ArchiveRecordType, id *uuid.UUID, field string) {
+		if id != nil {
+			refs = append(refs, archiveRef{recordType: recordType, id: *id, field: field})
+		}
+	}
+
+	switch r := record.(type) {
+	case *domain.ArchivedProject:
+		refs = append(refs, archiveRef{domain.RecordEmployee, r.OwnerID, "owner_id", true})
+		for i := range r.Members {
+			optional(domain.RecordEmployee, &r.Members[i], "members")
+		}
+	case *domain.ArchivedTask:
+		refs = append(refs, archiveRef{domain.RecordEmployee, r.CreatedBy, "created_by", true})
+		optional(domain.RecordProject, r.ProjectID, "project_id")
+		optional(domain.RecordTask, r.ParentID, "parent_id")
+	case *domain.TaskParticipant:
+		refs = append(refs,
+			archiveRef{domain.RecordTask, r.TaskID, "task_id", true},
+			archiveRef{domain.RecordEmployee, r.EmployeeID, "employee_id", true})
+	case *domain.TaskMessage:
+		refs = append(refs, archiveRef{domain.RecordTask, r.TaskID, "task_id", true})
+		optional(domain.RecordEmployee, r.AuthorID, "author_id")
+	case *domain.TimeEntry:
+		refs = append(refs,
+			archiveRef{domain.RecordTask, r.TaskID, "task_id", true},
+			archiveRef{domain.RecordEmployee, r.EmployeeID, "employee_id", true})
+	}
+	return refs
+}
+
+func recordID(record interface{}) uuid.UUID {
+	switch r := record.(type) {
+	case *domain.ArchivedEmployee:
+		return r.ID
+	case *domain.ArchivedProject:
+		return r.ID
+	case *domain.ArchivedTask:
+		return r.ID
+	case *domain.TaskParticipant:
+		return r.ID
+	case *domain.TaskMessage:
+		return r.ID
+	case *domain.TimeEntry:
+		return r.ID
+	}
+	return uuid.Nil
+}
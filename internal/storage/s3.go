@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/dmitry/taskmanager/internal/config"
+	"github.com/dmitry/taskmanager/pkg/logger"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Storage хранит объекты в S3-совместимом хранилище (AWS S3, MinIO и т.п.)
+type S3Storage struct {
+	client *minio.Client
+	bucket string
+}
+
+func NewS3(cfg *config.Config, log *logger.Logger) (*S3Storage, error) {
+	client, err := minio.New(cfg.S3Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.S3AccessKey, cfg.S3SecretKey, ""),
+		Secure: cfg.S3UseSSL,
+		Region: cfg.S3Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать S3 клиент: %w", err)
+	}
+
+	ctx := context.Background()
+	exists, err := client.BucketExists(ctx, cfg.S3Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось подключиться к S3: %w", err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.S3Bucket, minio.MakeBucketOptions{Region: cfg.S3Region}); err != nil {
+			return nil, fmt.Errorf("не удалось создать бакет %s: %w", cfg.S3Bucket, err)
+		}
+		log.Info("Создан бакет для вложений", "bucket", cfg.S3Bucket)
+	}
+
+	log.Info("Подключение к S3 хранилищу установлено", "endpoint", cfg.S3Endpoint, "bucket", cfg.S3Bucket)
+
+	return &S3Storage{
+		client: client,
+		bucket: cfg.S3Bucket,
+	}, nil
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return fmt.Errorf("не удалось загрузить объект в S3: %w", err)
+	}
+	return nil
+}
+
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	// GetObject ленивый, поэтому сначала проверяем наличие объекта
+	if exists, err := s.Exists(ctx, key); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, ErrNotFound
+	}
+
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить объект из S3: %w", err)
+	}
+	return obj, nil
+}
+
+func (s *S3Storage) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return false, nil
+		}
+		return false, fmt.Errorf("не удалось проверить объект в S3: %w", err)
+	}
+	return true, nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("не удалось удалить объект из S3: %w", err)
+	}
+	return nil
+}
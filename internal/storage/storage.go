@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dmitry/taskmanager/internal/config"
+	"github.com/dmitry/taskmanager/pkg/logger"
+)
+
+// ErrNotFound возвращается, если объект с указанным ключом отсутствует в хранилище
+var ErrNotFound = errors.New("объект не найден в хранилище")
+
+// BlobStorage - хранилище бинарных объектов (вложений) по ключу
+type BlobStorage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New создает хранилище согласно STORAGE_DRIVER
+func New(cfg *config.Config, log *logger.Logger) (BlobStorage, error) {
+	switch cfg.StorageDriver {
+	case "local":
+		return NewLocal(cfg.StorageLocalPath, log)
+	case "s3":
+		return NewS3(cfg, log)
+	default:
+		return nil, fmt.Errorf("неизвестный драйвер хранилища: %s", cfg.StorageDriver)
+	}
+}
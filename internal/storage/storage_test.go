@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dmitry/taskmanager/internal/config"
+	"github.com/dmitry/taskmanager/pkg/logger"
+)
+
+var discardLog = logger.NewWithWriter("error", io.Discard)
+
+// testBlobStorage проверяет контракт BlobStorage, общий для всех драйверов
+func testBlobStorage(t *testing.T, s BlobStorage) {
+	ctx := context.Background()
+	key := "ab/cd/abcdef"
+	content := "содержимое вложения"
+
+	exists, err := s.Exists(ctx, key)
+	if err != nil || exists {
+		t.Fatalf("Exists до загрузки: %v, %v", exists, err)
+	}
+	if _, err := s.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get до загрузки: %v, ожидалось ErrNotFound", err)
+	}
+
+	if err := s.Put(ctx, key, strings.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	exists, err = s.Exists(ctx, key)
+	if err != nil || !exists {
+		t.Fatalf("Exists после загрузки: %v, %v", exists, err)
+	}
+
+	r, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	r.Close()
+	if err != nil || string(got) != content {
+		t.Fatalf("прочитано %q, %v; ожидалось %q", got, err, content)
+	}
+
+	// Повторная загрузка заменяет объект
+	if err := s.Put(ctx, key, strings.NewReader("новое"), int64(len("новое")), "text/plain"); err != nil {
+		t.Fatalf("повторный Put: %v", err)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	exists, err = s.Exists(ctx, key)
+	if err != nil || exists {
+		t.Fatalf("Exists после удаления: %v, %v", exists, err)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Errorf("удаление отсутствующего объекта: %v", err)
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	s, err := NewLocal(t.TempDir(), discardLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBlobStorage(t, s)
+}
+
+func TestLocalStorageKeepsKeysInsideRoot(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "blobs")
+	s, err := NewLocal(root, discardLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err := s.Put(ctx, "../outside", strings.NewReader("x"), 1, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "outside")); err != nil {
+		t.Errorf("объект записан не в корень хранилища: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(root), "outside")); !os.IsNotExist(err) {
+		t.Error("ключ с .. вышел за пределы корня хранилища")
+	}
+
+	if err := s.Put(ctx, "/", strings.NewReader("x"), 1, "text/plain"); err == nil {
+		t.Error("ожидалась ошибка для пустого ключа")
+	}
+}
+
+// TestS3Storage выполняется, только если задан STORAGE_TEST_S3_ENDPOINT, например для MinIO:
+// docker run -p 9000:9000 minio/minio server /data
+// STORAGE_TEST_S3_ENDPOINT=localhost:9000 STORAGE_TEST_S3_ACCESS_KEY=minioadmin STORAGE_TEST_S3_SECRET_KEY=minioadmin
+func TestS3Storage(t *testing.T) {
+	endpoint := os.Getenv("STORAGE_TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("STORAGE_TEST_S3_ENDPOINT не задан")
+	}
+
+	s, err := NewS3(&config.Config{
+		S3Endpoint:  endpoint,
+		S3AccessKey: os.Getenv("STORAGE_TEST_S3_ACCESS_KEY"),
+		S3SecretKey: os.Getenv("STORAGE_TEST_S3_SECRET_KEY"),
+		S3Bucket:    "taskmanager-storage-test",
+		S3Region:    "us-east-1",
+		S3UseSSL:    os.Getenv("STORAGE_TEST_S3_USE_SSL") == "true",
+	}, discardLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBlobStorage(t, s)
+}
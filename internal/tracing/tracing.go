@@ -0,0 +1,83 @@
+// Package tracing настраивает трассировку OpenTelemetry: провайдер спанов, экспортёр
+// и распространение контекста по заголовку W3C traceparent.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/dmitry/taskmanager/internal/config"
+	"github.com/dmitry/taskmanager/pkg/buildinfo"
+	"github.com/dmitry/taskmanager/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName - имя сервиса в спанах
+const ServiceName = "taskmanager"
+
+// ShutdownFunc отправляет накопленные спаны и останавливает экспортёр
+type ShutdownFunc func(ctx context.Context) error
+
+// New настраивает глобальный провайдер трассировки согласно TRACING_EXPORTER.
+// Распространение traceparent включено всегда: даже без экспортёра сервис передаёт
+// контекст трассировки дальше и пишет trace_id входящих запросов в журнал.
+func New(cfg *config.Config, log *logger.Logger) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.TracingExporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr), stdouttrace.WithPrettyPrint())
+	case "otlp":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.TracingEndpoint)}
+		if cfg.TracingInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(context.Background(), opts...)
+	default:
+		return nil, fmt.Errorf("неизвестный экспортёр трассировки: %s", cfg.TracingExporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать экспортёр трассировки: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(ServiceName),
+		semconv.ServiceVersion(buildinfo.Get().Version),
+		semconv.DeploymentEnvironment(cfg.Environment),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось описать ресурс трассировки: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.TracingSampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	log.Info("Трассировка включена", "exporter", cfg.TracingExporter, "sample_ratio", cfg.TracingSampleRatio)
+
+	return provider.Shutdown, nil
+}
+
+// Start открывает дочерний спан для участка кода, который не покрыт автоматической
+// инструментацией HTTP, SQL и Redis
+func Start(ctx context.Context, name string) (context.Context, trace.Span) {
+	return otel.Tracer(ServiceName).Start(ctx, name)
+}
@@ -0,0 +1,48 @@
+// Package buildinfo хранит сведения о сборке. Значения задаются при сборке:
+//
+//	go build -ldflags "-X github.com/dmitry/taskmanager/pkg/buildinfo.Version=v1.2.0 \
+//	    -X github.com/dmitry/taskmanager/pkg/buildinfo.Commit=$(git rev-parse HEAD)"
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info - сведения о сборке для проверок работоспособности
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get возвращает сведения о сборке; коммит и время без ldflags берутся из данных VCS,
+// которые go build встраивает в бинарный файл
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildTime == "":
+				info.BuildTime = setting.Value
+			}
+		}
+	}
+
+	return info
+}
@@ -12,6 +12,7 @@ const (
 	ErrCodeNotFound     ErrorCode = "NOT_FOUND"
 	ErrCodeConflict     ErrorCode = "CONFLICT"
 	ErrCodeUnauthorized ErrorCode = "UNAUTHORIZED"
+	ErrCodeForbidden    ErrorCode = "FORBIDDEN"
 	ErrCodeInternal     ErrorCode = "INTERNAL_ERROR"
 	ErrCodeBadRequest   ErrorCode = "BAD_REQUEST"
 )
@@ -49,6 +50,8 @@ func (e *AppError) HTTPStatusCode() int {
 		return http.StatusConflict
 	case ErrCodeUnauthorized:
 		return http.StatusUnauthorized
+	case ErrCodeForbidden:
+		return http.StatusForbidden
 	default:
 		return http.StatusInternalServerError
 	}
@@ -97,3 +100,10 @@ func Unauthorized(message string) *AppError {
 		Message: message,
 	}
 }
+
+func Forbidden(message string) *AppError {
+	return &AppError{
+		Code:    ErrCodeForbidden,
+		Message: message,
+	}
+}
@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type contextKey struct{}
+
+var defaultLogger atomic.Pointer[Logger]
+
+// SetDefault задаёт логгер, который FromContext возвращает для контекста без логгера
+// (фоновые задачи, утилиты командной строки)
+func SetDefault(l *Logger) {
+	defaultLogger.Store(l)
+}
+
+// Default возвращает логгер по умолчанию; если он не задан - логгер уровня info в stdout
+func Default() *Logger {
+	if l := defaultLogger.Load(); l != nil {
+		return l
+	}
+	l := New("info")
+	defaultLogger.CompareAndSwap(nil, l)
+	return defaultLogger.Load()
+}
+
+// NewContext сохраняет логгер в контексте. Middleware кладут сюда логгер запроса
+// с request_id, маршрутом, сотрудником и идентификаторами трассировки.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext возвращает логгер из контекста или логгер по умолчанию
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return l
+	}
+	return Default()
+}
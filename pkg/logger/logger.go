@@ -1,36 +1,76 @@
 package logger
 
 import (
+	"context"
+	"io"
 	"log/slog"
 	"os"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Logger struct {
 	logger *slog.Logger
+	// level общий для логгера и всех производных от него (With, WithTrace, Sampled)
+	level *slog.LevelVar
+}
+
+// Options - параметры логгера; пустой RedactFields означает DefaultRedactFields
+type Options struct {
+	Level        string
+	Writer       io.Writer
+	RedactFields []string
 }
 
 func New(level string) *Logger {
-	var logLevel slog.Level
+	return NewWithWriter(level, os.Stdout)
+}
+
+// NewWithWriter создаёт логгер, пишущий в w; утилиты командной строки пишут журнал в stderr,
+// чтобы он не смешивался с результатом команды
+func NewWithWriter(level string, w io.Writer) *Logger {
+	return NewWithOptions(Options{Level: level, Writer: w})
+}
+
+// NewWithOptions создаёт логгер с маскированием чувствительных полей (см. DefaultRedactFields)
+func NewWithOptions(opts Options) *Logger {
+	logLevel := &slog.LevelVar{}
+	logLevel.Set(parseLevel(opts.Level))
+
+	redactFields := opts.RedactFields
+	if len(redactFields) == 0 {
+		redactFields = DefaultRedactFields
+	}
+
+	handler := slog.NewJSONHandler(opts.Writer, &slog.HandlerOptions{
+		Level:       logLevel,
+		ReplaceAttr: redactAttr(redactFields),
+	})
+
+	return &Logger{
+		logger: slog.New(handler),
+		level:  logLevel,
+	}
+}
+
+func parseLevel(level string) slog.Level {
 	switch level {
 	case "debug":
-		logLevel = slog.LevelDebug
+		return slog.LevelDebug
 	case "info":
-		logLevel = slog.LevelInfo
+		return slog.LevelInfo
 	case "warn":
-		logLevel = slog.LevelWarn
+		return slog.LevelWarn
 	case "error":
-		logLevel = slog.LevelError
+		return slog.LevelError
 	default:
-		logLevel = slog.LevelInfo
+		return slog.LevelInfo
 	}
+}
 
-	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
-	})
-
-	return &Logger{
-		logger: slog.New(handler),
-	}
+// SetLevel меняет уровень журнала на ходу, в том числе для уже созданных производных логгеров
+func (l *Logger) SetLevel(level string) {
+	l.level.Set(parseLevel(level))
 }
 
 func (l *Logger) Info(msg string, args ...any) {
@@ -57,5 +97,46 @@ func (l *Logger) Fatal(msg string, args ...any) {
 func (l *Logger) With(args ...any) *Logger {
 	return &Logger{
 		logger: l.logger.With(args...),
+		level:  l.level,
+	}
+}
+
+// WithTrace добавляет к записям trace_id и span_id текущего спана из ctx, чтобы запись
+// журнала можно было найти по трассировке; без активного спана логгер возвращается как есть
+func (l *Logger) WithTrace(ctx context.Context) *Logger {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return l
 	}
+	return l.With("trace_id", spanContext.TraceID().String(), "span_id", spanContext.SpanID().String())
+}
+
+// Sampled возвращает логгер для запроса, не попавшего в выборку (keep == false): такой логгер
+// пишет только предупреждения и ошибки. При keep == true логгер возвращается как есть.
+func (l *Logger) Sampled(keep bool) *Logger {
+	if keep {
+		return l
+	}
+	return &Logger{
+		logger: slog.New(&minLevelHandler{Handler: l.logger.Handler(), min: slog.LevelWarn}),
+		level:  l.level,
+	}
+}
+
+// minLevelHandler отбрасывает записи ниже min независимо от уровня исходного обработчика
+type minLevelHandler struct {
+	slog.Handler
+	min slog.Level
+}
+
+func (h *minLevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.min && h.Handler.Enabled(ctx, level)
+}
+
+func (h *minLevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &minLevelHandler{Handler: h.Handler.WithAttrs(attrs), min: h.min}
+}
+
+func (h *minLevelHandler) WithGroup(name string) slog.Handler {
+	return &minLevelHandler{Handler: h.Handler.WithGroup(name), min: h.min}
 }
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"log/slog"
+	"net/url"
+	"strings"
+)
+
+// DefaultRedactFields - поля, значения которых не попадают в журнал. Поле совпадает по имени
+// или суффиксу: "token" скрывает и token, и refresh_token. signature - подпись ссылок
+// на скачивание вложений, по которой файл доступен без авторизации.
+var DefaultRedactFields = []string{"password", "token", "secret", "signature", "authorization", "cookie", "email"}
+
+const redacted = "[скрыто]"
+
+// queryKey - поле со строкой запроса URL; в ней скрываются значения параметров,
+// имена которых совпадают с полями списка
+const queryKey = "query"
+
+// redactAttr скрывает значения чувствительных полей; у адресов почты остаются первая буква
+// и домен, чтобы записи можно было сопоставить
+func redactAttr(fields []string) func(groups []string, a slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == queryKey && a.Value.Kind() == slog.KindString {
+			return slog.String(a.Key, redactQuery(a.Value.String(), fields))
+		}
+
+		field, ok := matchField(a.Key, fields)
+		if !ok || a.Value.Kind() == slog.KindGroup {
+			return a
+		}
+
+		value := a.Value.String()
+		if value == "" {
+			return a
+		}
+		if field == "email" {
+			return slog.String(a.Key, maskEmail(value))
+		}
+		return slog.String(a.Key, redacted)
+	}
+}
+
+func matchField(key string, fields []string) (string, bool) {
+	key = strings.ToLower(key)
+	for _, field := range fields {
+		if key == field || strings.HasSuffix(key, "_"+field) {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+// maskEmail превращает ivan@example.com в i***@example.com
+func maskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 1 {
+		return redacted
+	}
+	return email[:1] + "***" + email[at:]
+}
+
+// redactQuery скрывает значения чувствительных параметров строки запроса, сохраняя
+// порядок и запись остальных параметров
+func redactQuery(query string, fields []string) string {
+	if query == "" {
+		return query
+	}
+
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		name, _, hasValue := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if _, ok := matchField(name, fields); ok && hasValue {
+			params[i] = param[:strings.Index(param, "=")+1] + redacted
+		}
+	}
+	return strings.Join(params, "&")
+}
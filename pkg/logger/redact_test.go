@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", ""},
+		{"page=2&status=new", "page=2&status=new"},
+		{"expires=1700000000&signature=abc123", "expires=1700000000&signature=[скрыто]"},
+		{"token=t1&page=1&refresh_token=t2", "token=[скрыто]&page=1&refresh_token=[скрыто]"},
+		{"Signature=abc", "Signature=[скрыто]"},
+		{"sig%6Eature=abc", "sig%6Eature=[скрыто]"},
+		{"signature", "signature"},
+		{"text=password", "text=password"},
+	}
+
+	for _, tt := range tests {
+		if got := redactQuery(tt.query, DefaultRedactFields); got != tt.want {
+			t.Errorf("redactQuery(%q) = %q, ожидалось %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestLoggerRedactsQueryAndFields(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithOptions(Options{Level: "info", Writer: &buf})
+
+	log.Info("входящий_запрос",
+		"query", "expires=1700000000&signature=abc123",
+		"refresh_token", "secret-value",
+		"email", "ivan@example.com",
+		"path", "/api/v1/attachments/1/download",
+	)
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"query":         "expires=1700000000&signature=[скрыто]",
+		"refresh_token": "[скрыто]",
+		"email":         "i***@example.com",
+		"path":          "/api/v1/attachments/1/download",
+	}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("%s = %v, ожидалось %q", key, record[key], value)
+		}
+	}
+}
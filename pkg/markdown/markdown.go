@@ -0,0 +1,79 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// Renderer преобразует Markdown в HTML и очищает результат по строгому allow-list
+type Renderer struct {
+	md     goldmark.Markdown
+	policy *bluemonday.Policy
+}
+
+func New() *Renderer {
+	md := goldmark.New(
+		goldmark.WithExtensions(
+			extension.Table,
+			extension.Strikethrough,
+			extension.Linkify,
+			extension.TaskList,
+		),
+	)
+
+	return &Renderer{
+		md:     md,
+		policy: newPolicy(),
+	}
+}
+
+// Render возвращает безопасный HTML для исходного Markdown.
+// Сырой HTML в исходнике не передаётся в вывод: goldmark экранирует его,
+// а политика дополнительно удаляет всё, что не входит в allow-list.
+func (r *Renderer) Render(source string) (string, error) {
+	if source == "" {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	if err := r.md.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+
+	return r.policy.Sanitize(buf.String()), nil
+}
+
+// newPolicy описывает допустимые элементы и атрибуты итогового HTML
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+
+	p.AllowElements(
+		"p", "br", "hr", "blockquote", "pre",
+		"h1", "h2", "h3", "h4", "h5", "h6",
+		"strong", "em", "del", "code",
+		"ul", "ol", "li",
+		"table", "thead", "tbody", "tr", "th", "td",
+	)
+
+	// Ссылки только по безопасным схемам, открываются без доступа к opener
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowURLSchemes("http", "https", "mailto")
+	p.RequireParseableURLs(true)
+	p.RequireNoFollowOnLinks(true)
+	p.RequireNoReferrerOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+
+	// Подсветка языка в блоках кода и выравнивание в таблицах
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[a-zA-Z0-9_+-]+$`)).OnElements("code")
+	p.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|right|center)$`)).OnElements("th", "td")
+
+	// Чекбоксы списков задач, только для чтения
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+
+	return p
+}
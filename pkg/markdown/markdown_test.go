@@ -0,0 +1,62 @@
+package markdown
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"пустой текст", "", ""},
+		{"тег script", "<script>alert(1)</script>", "\n"},
+		{"img с onerror", "<img src=x onerror=alert(1)>", "\n"},
+		{"сырой HTML с обработчиком", `<a href="https://example.com" onclick="x()">a</a>`, "<p>a</p>\n"},
+		{"сырой HTML со стилем", `<div style="color:red">текст</div>`, "\n"},
+		{"ссылка javascript:", "[x](javascript:alert(1))", "<p>x</p>\n"},
+		{"ссылка javascript: в другом регистре", "[x](JaVaScRiPt:alert(1))", "<p>x</p>\n"},
+		{"ссылка data:", "[x](data:text/html;base64,PHNjcmlwdD4=)", "<p>x</p>\n"},
+		{
+			"внешняя ссылка получает rel и target",
+			"[сайт](https://example.com)",
+			`<p><a href="https://example.com" rel="nofollow noreferrer noopener" target="_blank">сайт</a></p>` + "\n",
+		},
+		{
+			"ссылка mailto",
+			"[почта](mailto:anna@example.com)",
+			`<p><a href="mailto:anna@example.com" rel="nofollow noreferrer">почта</a></p>` + "\n",
+		},
+		{
+			"класс языка в блоке кода",
+			"```go\nfmt.Println()\n```",
+			`<pre><code class="language-go">fmt.Println()` + "\n</code></pre>\n",
+		},
+		{
+			"класс кода только language-*",
+			"```go\" onmouseover=\"x\n1\n```",
+			"<pre><code>1\n</code></pre>\n",
+		},
+		{
+			"чекбоксы списка задач только для чтения",
+			"- [x] готово\n- [ ] нет",
+			"<ul>\n" +
+				`<li><input checked="" disabled="" type="checkbox"> готово</li>` + "\n" +
+				`<li><input disabled="" type="checkbox"> нет</li>` + "\n" +
+				"</ul>\n",
+		},
+		{"выделение", "**жирный** ~~зачёркнутый~~", "<p><strong>жирный</strong> <del>зачёркнутый</del></p>\n"},
+	}
+
+	r := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Render(tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Render(%q)\nполучено  %q\nожидалось %q", tt.source, got, tt.want)
+			}
+		})
+	}
+}